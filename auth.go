@@ -0,0 +1,167 @@
+package httperror
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BasicAuthValidator checks the credentials of a request using HTTP basic
+// authentication. It returns nil if the credentials are valid, or an error
+// otherwise, usually Unauthorized (or Forbidden if the user is known but not
+// allowed access).
+type BasicAuthValidator = func(r *http.Request, username, password string) error
+
+// BearerTokenValidator checks the bearer token of a request. It returns nil
+// if the token is valid, or an error otherwise, usually Unauthorized (or
+// Forbidden if the token does not grant access).
+type BearerTokenValidator = func(r *http.Request, token string) error
+
+// BasicAuthMiddleware returns an [httperror.Middleware] that requires HTTP
+// basic authentication. Requests without credentials, or whose credentials
+// are rejected by validate with a 401 error, return Unauthorized with a
+// WWW-Authenticate challenge for the realm attached (see
+// [httperror.WithHeader]), instead of writing a response. Other errors
+// returned by validate are returned as they are.
+func BasicAuthMiddleware(realm string, validate BasicAuthValidator) Middleware {
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			username, password, ok := r.BasicAuth()
+			if !ok {
+				return WithHeader(Unauthorized, "WWW-Authenticate", challenge)
+			}
+
+			if err := validate(r, username, password); err != nil {
+				if StatusCode(err) == http.StatusUnauthorized {
+					return WithHeader(err, "WWW-Authenticate", challenge)
+				}
+				return err
+			}
+
+			return h.Serve(w, r)
+		})
+	}
+}
+
+// BearerAuthMiddleware returns an [httperror.Middleware] that requires a
+// bearer token (RFC 6750) in the Authorization header. Requests without a
+// token return Unauthorized with a WWW-Authenticate challenge for the realm
+// attached (see [httperror.WithHeader]), instead of writing a response.
+// Tokens rejected by validate with a 401 error get a challenge with
+// error="invalid_token". Other errors returned by validate are returned as
+// they are.
+func BearerAuthMiddleware(realm string, validate BearerTokenValidator) Middleware {
+	challenge := "Bearer realm=" + strconv.Quote(realm)
+
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			token, ok := bearerToken(r)
+			if !ok {
+				return WithHeader(Unauthorized, "WWW-Authenticate", challenge)
+			}
+
+			if err := validate(r, token); err != nil {
+				if StatusCode(err) == http.StatusUnauthorized {
+					return WithHeader(err, "WWW-Authenticate", challenge+`, error="invalid_token"`)
+				}
+				return err
+			}
+
+			return h.Serve(w, r)
+		})
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>"
+// request header.
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+
+	auth := r.Header.Get("Authorization")
+	if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(auth[len(prefix):]), true
+}
+
+// KeyByQuery returns a [httperror.Keyer] that reads the value of the given
+// URL query parameter, e.g. an API key.
+func KeyByQuery(name string) Keyer {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(name)
+	}
+}
+
+// APIKeyValidator checks the API key of a request. It returns nil if the
+// key is valid, or an error otherwise, usually Unauthorized for unknown
+// keys or Forbidden for keys that do not grant access.
+type APIKeyValidator = func(r *http.Request, key string) error
+
+// APIKeyMiddleware returns an [httperror.Middleware] that requires an API
+// key, read from the request with key (see [httperror.KeyByHeader] and
+// [httperror.KeyByQuery]). Requests without a key return Unauthorized with
+// the code "missing_api_key" (see [httperror.ErrorCode]). 401 and 403
+// errors returned by validate without a code get the codes
+// "invalid_api_key" and "api_key_forbidden". Other errors are returned as
+// they are.
+//
+// To pass the principal identified by the key to an [httperror.XHandler],
+// use [httperror.APIKeyPrincipal] instead.
+func APIKeyMiddleware(key Keyer, validate APIKeyValidator) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			k := key(r)
+			if k == "" {
+				return WithCode(Unauthorized, "missing_api_key")
+			}
+
+			if err := validate(r, k); err != nil {
+				return apiKeyError(err)
+			}
+
+			return h.Serve(w, r)
+		})
+	}
+}
+
+// APIKeyPrincipal returns a function that resolves the principal (such as
+// an account or client) identified by the API key of a request using
+// lookup, for use with [httperror.Bind] or
+// [httperror.XHandlerWithProvider]. Errors are coded as described for
+// [httperror.APIKeyMiddleware].
+//
+//	h := httperror.Bind(accountHandler,
+//		httperror.APIKeyPrincipal(httperror.KeyByHeader("X-API-Key"), lookupAccount))
+func APIKeyPrincipal[P any](key Keyer, lookup func(r *http.Request, key string) (P, error)) func(*http.Request) (P, error) {
+	return func(r *http.Request) (P, error) {
+		k := key(r)
+		if k == "" {
+			var zeroValue P
+			return zeroValue, WithCode(Unauthorized, "missing_api_key")
+		}
+
+		p, err := lookup(r, k)
+		if err != nil {
+			return p, apiKeyError(err)
+		}
+		return p, nil
+	}
+}
+
+// apiKeyError adds a machine-readable code to an error returned for an API
+// key, if it has none.
+func apiKeyError(err error) error {
+	if ErrorCode(err) != "" {
+		return err
+	}
+
+	switch StatusCode(err) {
+	case http.StatusUnauthorized:
+		return WithCode(err, "invalid_api_key")
+	case http.StatusForbidden:
+		return WithCode(err, "api_key_forbidden")
+	}
+	return err
+}
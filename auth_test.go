@@ -0,0 +1,212 @@
+package httperror_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	h := httperror.BasicAuthMiddleware("admin", func(r *http.Request, username, password string) error {
+		if username != "bill" {
+			return httperror.Unauthorized
+		}
+		if password != "secret" {
+			return httperror.Forbidden
+		}
+		return nil
+	})(okHandler)
+
+	serve := func(username, password string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		if username != "" {
+			r.SetBasicAuth(username, password)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		return rr
+	}
+
+	{
+		rr := serve("", "")
+		assert.Equal(t, 401, rr.Code)
+		assert.Equal(t, `Basic realm="admin", charset="UTF-8"`, rr.Header().Get("WWW-Authenticate"))
+	}
+
+	{
+		rr := serve("alice", "secret")
+		assert.Equal(t, 401, rr.Code)
+		assert.Equal(t, `Basic realm="admin", charset="UTF-8"`, rr.Header().Get("WWW-Authenticate"))
+	}
+
+	{
+		rr := serve("bill", "wrong")
+		assert.Equal(t, 403, rr.Code)
+		assert.Equal(t, "", rr.Header().Get("WWW-Authenticate"))
+	}
+
+	assert.Equal(t, 200, serve("bill", "secret").Code)
+}
+
+func TestBearerAuthMiddleware(t *testing.T) {
+	h := httperror.BearerAuthMiddleware("api", func(r *http.Request, token string) error {
+		if token != "s3cr3t" {
+			return httperror.Unauthorized
+		}
+		return nil
+	})(okHandler)
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		return rr
+	}
+
+	{
+		rr := serve("")
+		assert.Equal(t, 401, rr.Code)
+		assert.Equal(t, `Bearer realm="api"`, rr.Header().Get("WWW-Authenticate"))
+	}
+
+	{
+		rr := serve("Bearer wrong")
+		assert.Equal(t, 401, rr.Code)
+		assert.Equal(t, `Bearer realm="api", error="invalid_token"`, rr.Header().Get("WWW-Authenticate"))
+	}
+
+	assert.Equal(t, 200, serve("Bearer s3cr3t").Code)
+}
+
+func TestCSRFMiddleware(t *testing.T) {
+	var token string
+	h := httperror.CSRFMiddleware(httperror.CSRFConfig{})(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		token = httperror.CSRFToken(r)
+		return nil
+	}))
+
+	var cookie *http.Cookie
+	{
+		rr := httptest.NewRecorder()
+		assert.Nil(t, h.Serve(rr, httptest.NewRequest("GET", "/", nil)))
+		cookies := rr.Result().Cookies()
+		assert.Len(t, cookies, 1, "safe request gets a token cookie")
+		cookie = cookies[0]
+		assert.Equal(t, "csrf_token", cookie.Name)
+		assert.Equal(t, cookie.Value, token)
+	}
+
+	post := func(cookie *http.Cookie, header, form string) error {
+		r := httptest.NewRequest("POST", "/", strings.NewReader("csrf_token="+form))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		if header != "" {
+			r.Header.Set("X-CSRF-Token", header)
+		}
+		return h.Serve(httptest.NewRecorder(), r)
+	}
+
+	assert.Nil(t, post(cookie, cookie.Value, ""), "token in header")
+	assert.Nil(t, post(cookie, "", cookie.Value), "token in form")
+
+	{
+		err := post(nil, cookie.Value, "")
+		assert.Equal(t, 403, httperror.StatusCode(err))
+		assert.Equal(t, "missing CSRF cookie", httperror.PublicMessage(err))
+	}
+
+	{
+		err := post(cookie, "", "")
+		assert.Equal(t, 403, httperror.StatusCode(err))
+		assert.Equal(t, "missing CSRF token", httperror.PublicMessage(err))
+	}
+
+	{
+		err := post(cookie, "wrong", "")
+		assert.True(t, errors.Is(err, httperror.Forbidden))
+		assert.Equal(t, "invalid CSRF token", httperror.PublicMessage(err))
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	validate := func(r *http.Request, key string) error {
+		switch key {
+		case "good":
+			return nil
+		case "readonly":
+			return httperror.Forbidden
+		case "broken":
+			return errors.New("database down")
+		}
+		return httperror.Unauthorized
+	}
+
+	h := httperror.APIKeyMiddleware(httperror.KeyByHeader("X-API-Key"), validate)(okHandler)
+
+	serve := func(key string) error {
+		r := httptest.NewRequest("GET", "/", nil)
+		if key != "" {
+			r.Header.Set("X-API-Key", key)
+		}
+		return h.Serve(httptest.NewRecorder(), r)
+	}
+
+	assert.Nil(t, serve("good"))
+
+	for _, tc := range []struct {
+		key    string
+		status int
+		code   string
+	}{
+		{"", 401, "missing_api_key"},
+		{"bad", 401, "invalid_api_key"},
+		{"readonly", 403, "api_key_forbidden"},
+		{"broken", 500, ""},
+	} {
+		err := serve(tc.key)
+		assert.Equal(t, tc.status, httperror.StatusCode(err), tc.key)
+		assert.Equal(t, tc.code, httperror.ErrorCode(err), tc.key)
+	}
+}
+
+func TestAPIKeyPrincipal(t *testing.T) {
+	type account struct{ name string }
+
+	h := httperror.Bind[account](httperror.XHandlerFunc[account](func(w http.ResponseWriter, r *http.Request, a account) error {
+		_, _ = w.Write([]byte(a.name))
+		return nil
+	}), httperror.APIKeyPrincipal(httperror.KeyByQuery("api_key"), func(r *http.Request, key string) (account, error) {
+		if key != "k1" {
+			return account{}, httperror.Unauthorized
+		}
+		return account{"alice"}, nil
+	}))
+
+	{
+		s, m := testRequest(h, "/?api_key=k1")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "alice", m, "principal is passed as the parameter")
+	}
+
+	{
+		err := h.Serve(httptest.NewRecorder(), httptest.NewRequest("GET", "/?api_key=k2", nil))
+		assert.Equal(t, 401, httperror.StatusCode(err))
+		assert.Equal(t, "invalid_api_key", httperror.ErrorCode(err))
+	}
+
+	{
+		err := h.Serve(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, "missing_api_key", httperror.ErrorCode(err))
+	}
+}
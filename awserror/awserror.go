@@ -0,0 +1,101 @@
+/*
+Package awserror converts errors returned by the AWS SDK for Go v2 into
+httperrors, for services that proxy AWS resources. See the documentation
+of the parent package at https://github.com/johnwarden/httperror
+*/
+package awserror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/johnwarden/httperror"
+)
+
+// retryAfter is the delay suggested to clients for throttled requests.
+const retryAfter = time.Second
+
+// codeStatus maps AWS API error codes to status codes.
+var codeStatus = map[string]int{
+	"NoSuchKey":                 http.StatusNotFound,
+	"NoSuchBucket":              http.StatusNotFound,
+	"NoSuchEntity":              http.StatusNotFound,
+	"NotFound":                  http.StatusNotFound,
+	"ResourceNotFoundException": http.StatusNotFound,
+
+	"AccessDenied":          http.StatusForbidden,
+	"AccessDeniedException": http.StatusForbidden,
+	"Forbidden":             http.StatusForbidden,
+	"UnauthorizedOperation": http.StatusForbidden,
+
+	"Throttling":                             http.StatusTooManyRequests,
+	"ThrottlingException":                    http.StatusTooManyRequests,
+	"ThrottledException":                     http.StatusTooManyRequests,
+	"RequestThrottled":                       http.StatusTooManyRequests,
+	"RequestLimitExceeded":                   http.StatusTooManyRequests,
+	"TooManyRequestsException":               http.StatusTooManyRequests,
+	"ProvisionedThroughputExceededException": http.StatusTooManyRequests,
+	"SlowDown":                               http.StatusTooManyRequests,
+
+	"PreconditionFailed": http.StatusPreconditionFailed,
+}
+
+// Map converts an error returned by an AWS SDK v2 client into an
+// httperror, based on the API error code (see smithy.APIError), or else on
+// the status code of the AWS response:
+//
+//   - NoSuchKey, NoSuchBucket, NotFound and similar codes, or a 404
+//     response: 404 Not Found
+//   - AccessDenied and similar codes, or a 403 response: 403 Forbidden
+//   - Throttling, SlowDown and similar codes, or a 429 response: 429 Too
+//     Many Requests, with a Retry-After header
+//   - PreconditionFailed: 412 Precondition Failed
+//   - server faults, or a 5xx response: 502 Bad Gateway
+//
+// The original error remains in the chain, so the AWS error code is also
+// available with [httperror.ErrorCode]. Other errors, and errors that
+// already have a status code, are returned unchanged.
+func Map(err error) error {
+	if err == nil || httperror.StatusCode(err) != http.StatusInternalServerError {
+		return err
+	}
+
+	s := status(err)
+	if s == 0 {
+		return err
+	}
+
+	err = httperror.Wrap(err, s)
+	if s == http.StatusTooManyRequests {
+		err = httperror.WithRetryAfter(err, retryAfter)
+	}
+	return err
+}
+
+// status returns the status code for an AWS error, or 0.
+func status(err error) int {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if s, ok := codeStatus[apiErr.ErrorCode()]; ok {
+			return s
+		}
+		if apiErr.ErrorFault() == smithy.FaultServer {
+			return http.StatusBadGateway
+		}
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch s := respErr.HTTPStatusCode(); {
+		case s == http.StatusNotFound, s == http.StatusForbidden, s == http.StatusTooManyRequests:
+			return s
+		case s >= 500:
+			return http.StatusBadGateway
+		}
+	}
+
+	return 0
+}
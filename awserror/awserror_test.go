@@ -0,0 +1,49 @@
+package awserror_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/awserror"
+	"github.com/stretchr/testify/assert"
+)
+
+func responseError(status int, err error) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+		Err:      err,
+	}
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{&smithy.GenericAPIError{Code: "NoSuchKey"}, http.StatusNotFound},
+		{&smithy.GenericAPIError{Code: "AccessDenied"}, http.StatusForbidden},
+		{&smithy.GenericAPIError{Code: "ThrottlingException"}, http.StatusTooManyRequests},
+		{&smithy.GenericAPIError{Code: "InternalError", Fault: smithy.FaultServer}, http.StatusBadGateway},
+		{responseError(http.StatusNotFound, &smithy.GenericAPIError{Code: "UnknownError"}), http.StatusNotFound},
+		{responseError(http.StatusServiceUnavailable, errors.New("unavailable")), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		err := awserror.Map(fmt.Errorf("get object: %w", tt.err))
+		assert.Equal(t, tt.status, httperror.StatusCode(err), tt.err.Error())
+		assert.True(t, errors.Is(err, tt.err))
+	}
+
+	err := awserror.Map(&smithy.GenericAPIError{Code: "SlowDown"})
+	assert.Equal(t, "1", httperror.Headers(err).Get("Retry-After"))
+	assert.Equal(t, "SlowDown", httperror.ErrorCode(err))
+
+	validationErr := &smithy.GenericAPIError{Code: "ValidationException", Fault: smithy.FaultClient}
+	assert.Equal(t, error(validationErr), awserror.Map(validationErr))
+	assert.Equal(t, httperror.NotFound, awserror.Map(httperror.NotFound))
+	assert.Nil(t, awserror.Map(nil))
+}
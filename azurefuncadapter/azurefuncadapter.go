@@ -0,0 +1,234 @@
+/*
+Package azurefuncadapter runs httperror handlers as Azure Functions custom
+handlers. See the documentation of the parent package at
+https://github.com/johnwarden/httperror
+
+The function host either forwards HTTP requests unchanged (with
+enableForwardingHttpRequest in host.json), in which case the handlers can
+simply be served on [Addr], or it posts an invocation payload describing the
+request, which [Handler] translates:
+
+	http.ListenAndServe(azurefuncadapter.Addr(), azurefuncadapter.Handler(h, azurefuncadapter.Config{}))
+*/
+package azurefuncadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/johnwarden/httperror"
+)
+
+// Addr returns the address on which the function host expects the custom
+// handler to listen, from the FUNCTIONS_CUSTOMHANDLER_PORT environment
+// variable, or ":8080" if it is not set.
+func Addr() string {
+	if port := os.Getenv("FUNCTIONS_CUSTOMHANDLER_PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
+
+// Config configures [Handler]. Zero values use the defaults.
+type Config struct {
+	// ErrorHandler handles the errors returned by the handler. The default
+	// is [httperror.DefaultErrorHandler].
+	ErrorHandler httperror.ErrorHandler
+
+	// RequestBinding is the name of the HTTP trigger binding in
+	// function.json. The default is "req".
+	RequestBinding string
+
+	// ResponseBinding is the name of the HTTP output binding in
+	// function.json. The default is "res".
+	ResponseBinding string
+}
+
+type invocationRequest struct {
+	Data map[string]json.RawMessage
+}
+
+type httpTrigger struct {
+	URL     string              `json:"Url"`
+	Method  string              `json:"Method"`
+	Query   map[string]string   `json:"Query"`
+	Headers map[string][]string `json:"Headers"`
+	Params  map[string]string   `json:"Params"`
+	Body    json.RawMessage     `json:"Body"`
+}
+
+type invocationResponse struct {
+	Outputs     map[string]httpOutput `json:"Outputs"`
+	Logs        []string              `json:"Logs"`
+	ReturnValue interface{}           `json:"ReturnValue"`
+}
+
+type httpOutput struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// Handler returns an [httperror.Handler] that serves the invocation
+// requests of the function host with h: the HTTP request described by the
+// payload is served by h, and the response, or the error returned by h
+// handled with the error handler, is returned in the payload of the output
+// binding, with the right status code. The format of errors is negotiated
+// from the Accept header of the request: JSON (the default), HTML, or plain
+// text. Route parameters are available with [http.Request.PathValue].
+//
+// Malformed invocation payloads return BadRequest.
+func Handler(h httperror.Handler, cfg Config) httperror.Handler {
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = httperror.DefaultErrorHandler
+	}
+	if cfg.RequestBinding == "" {
+		cfg.RequestBinding = "req"
+	}
+	if cfg.ResponseBinding == "" {
+		cfg.ResponseBinding = "res"
+	}
+
+	return httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var inv invocationRequest
+		if err := json.NewDecoder(r.Body).Decode(&inv); err != nil {
+			return httperror.FromJSONError(err)
+		}
+
+		var trigger httpTrigger
+		if err := json.Unmarshal(inv.Data[cfg.RequestBinding], &trigger); err != nil {
+			return httperror.Wrap(err, http.StatusBadRequest)
+		}
+
+		req, err := Request(r.Context(), trigger.URL, trigger.Method, trigger.Query, trigger.Headers, trigger.Params, triggerBody(trigger.Body))
+		if err != nil {
+			return err
+		}
+
+		rw := &responseWriter{header: make(http.Header)}
+		err = h.Serve(rw, req)
+		httperror.ReportError(req.Context(), req, err)
+		if err != nil && !httperror.ResponseWritten(err) {
+			if rw.header.Get("Content-Type") == "" {
+				rw.header.Set("Content-Type", negotiate(req.Header.Get("Accept")))
+			}
+			cfg.ErrorHandler(rw, err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(invocationResponse{
+			Outputs: map[string]httpOutput{cfg.ResponseBinding: rw.output()},
+			Logs:    []string{},
+		})
+	})
+}
+
+// Request constructs the [http.Request] described by the HTTP trigger of
+// an invocation payload.
+func Request(ctx context.Context, rawURL, method string, query map[string]string, header map[string][]string, params map[string]string, body []byte) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, httperror.Wrap(err, http.StatusBadRequest)
+	}
+	if len(query) > 0 && u.RawQuery == "" {
+		values := url.Values{}
+		for k, v := range query {
+			values.Set(k, v)
+		}
+		u.RawQuery = values.Encode()
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, httperror.Wrap(err, http.StatusBadRequest)
+	}
+	r.RequestURI = u.RequestURI()
+	for k, vs := range header {
+		for _, v := range vs {
+			r.Header.Add(k, v)
+		}
+	}
+	for k, v := range params {
+		r.SetPathValue(k, v)
+	}
+	return r, nil
+}
+
+// triggerBody returns the request body from the Body of an HTTP trigger,
+// which is a JSON string, or the parsed JSON body.
+func triggerBody(raw json.RawMessage) []byte {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []byte(s)
+	}
+	if string(raw) == "null" {
+		return nil
+	}
+	return raw
+}
+
+// negotiate returns the first of the supported content types (JSON, HTML,
+// and plain text) accepted by the Accept header, or JSON.
+func negotiate(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			return "application/json"
+		case "text/html", "text/*":
+			return "text/html"
+		case "text/plain":
+			return "text/plain"
+		}
+	}
+	return "application/json"
+}
+
+// responseWriter is an http.ResponseWriter that buffers the response for
+// the output binding.
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(s int) {
+	if w.status == 0 {
+		w.status = s
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) output() httpOutput {
+	out := httpOutput{StatusCode: w.status, Body: w.body.String()}
+	if out.StatusCode == 0 {
+		out.StatusCode = http.StatusOK
+	}
+	if w.body.Len() > 0 && w.header.Get("Content-Type") == "" {
+		w.header.Set("Content-Type", http.DetectContentType(w.body.Bytes()))
+	}
+	if len(w.header) > 0 {
+		out.Headers = make(map[string]string, len(w.header))
+		for k, vs := range w.header {
+			out.Headers[k] = strings.Join(vs, ", ")
+		}
+	}
+	return out
+}
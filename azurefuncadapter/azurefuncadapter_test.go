@@ -0,0 +1,78 @@
+package azurefuncadapter_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/azurefuncadapter"
+	"github.com/stretchr/testify/assert"
+)
+
+type output struct {
+	Outputs map[string]struct {
+		StatusCode int
+		Headers    map[string]string
+		Body       string
+	}
+}
+
+func invoke(t *testing.T, h http.Handler, payload string) (int, output) {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("POST", "/orders", strings.NewReader(payload)))
+
+	var out output
+	if rr.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+	}
+	return rr.Code, out
+}
+
+func TestHandler(t *testing.T) {
+	h := azurefuncadapter.Handler(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.PathValue("id") != "42" {
+			return httperror.NewPublic(http.StatusNotFound, "no such order")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "order %s for %s", r.PathValue("id"), r.URL.Query().Get("customer"))
+		return nil
+	}), azurefuncadapter.Config{})
+
+	s, out := invoke(t, h, `{"Data":{"req":{"Url":"http://localhost:7071/api/orders/42","Method":"GET","Query":{"customer":"alice"},"Headers":{},"Params":{"id":"42"}}},"Metadata":{}}`)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, 200, out.Outputs["res"].StatusCode)
+	assert.Equal(t, "order 42 for alice", out.Outputs["res"].Body)
+	assert.Equal(t, "text/plain", out.Outputs["res"].Headers["Content-Type"])
+
+	s, out = invoke(t, h, `{"Data":{"req":{"Url":"http://localhost:7071/api/orders/7","Method":"GET","Params":{"id":"7"}}}}`)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, 404, out.Outputs["res"].StatusCode)
+	assert.Equal(t, "application/json", out.Outputs["res"].Headers["Content-Type"])
+	assert.Contains(t, out.Outputs["res"].Body, "no such order")
+
+	s, _ = invoke(t, h, `{"Data":`)
+	assert.Equal(t, 400, s)
+}
+
+func TestHandlerBody(t *testing.T) {
+	h := azurefuncadapter.Handler(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var order struct{ Name string }
+		if err := httperror.DecodeJSON(r, &order); err != nil {
+			return err
+		}
+		fmt.Fprint(w, order.Name)
+		return nil
+	}), azurefuncadapter.Config{RequestBinding: "request", ResponseBinding: "$return"})
+
+	_, out := invoke(t, h, `{"Data":{"request":{"Url":"http://localhost/api/orders","Method":"POST","Headers":{"Content-Type":["application/json"]},"Body":"{\"Name\":\"widget\"}"}}}`)
+	assert.Equal(t, 200, out.Outputs["$return"].StatusCode)
+	assert.Equal(t, "widget", out.Outputs["$return"].Body)
+
+	_, out = invoke(t, h, `{"Data":{"request":{"Url":"http://localhost/api/orders","Method":"POST","Headers":{"Content-Type":["application/json"],"Accept":["text/plain"]},"Body":"{"}}}`)
+	assert.Equal(t, 400, out.Outputs["$return"].StatusCode)
+	assert.Equal(t, "400 Bad Request: unexpected end of JSON input\n", out.Outputs["$return"].Body)
+}
@@ -0,0 +1,232 @@
+package httperror
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Query parses the query parameter name of r as a T, which can be a string,
+// bool, integer, float, or time.Duration type, or a type that implements
+// encoding.TextUnmarshaler. If the parameter is missing or can't be
+// parsed, Query returns a BadRequest error with a public message naming
+// the parameter and the expected type:
+//
+//	page, err := httperror.Query[int](r, "page")
+//	if err != nil {
+//		return err
+//	}
+func Query[T any](r *http.Request, name string) (T, error) {
+	var v T
+	values := r.URL.Query()
+	if !values.Has(name) {
+		return v, PublicErrorf(http.StatusBadRequest, "missing query parameter %q", name)
+	}
+	err := parseParam(&v, values.Get(name), "query parameter", name)
+	return v, err
+}
+
+// QueryDefault is like [httperror.Query], but returns def if the parameter
+// is missing or empty.
+func QueryDefault[T any](r *http.Request, name string, def T) (T, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	var v T
+	err := parseParam(&v, s, "query parameter", name)
+	return v, err
+}
+
+// PathValue parses the value of the wildcard name in the routing pattern
+// that matched r (see [http.Request.PathValue]) as a T, which can be any
+// type supported by [httperror.Query], including types such as UUIDs that
+// implement encoding.TextUnmarshaler. If the value is empty (for example
+// for a "{path...}" wildcard matching an empty path), PathValue returns
+// NotFound. If the value can't be parsed, it returns a BadRequest error
+// with a public message naming the parameter and the expected type:
+//
+//	id, err := httperror.PathValue[int64](r, "id")
+//	if err != nil {
+//		return err
+//	}
+func PathValue[T any](r *http.Request, name string) (T, error) {
+	var v T
+	s := r.PathValue(name)
+	if s == "" {
+		return v, NotFound
+	}
+	err := parseParam(&v, s, "path parameter", name)
+	return v, err
+}
+
+// BindForm parses the form of r (see [http.Request.ParseForm]), which
+// includes the query parameters, into the fields of the struct pointed to
+// by dst. Each field is bound to the form field named by its `form` struct
+// tag, or else to the form field with the name of the struct field. Fields
+// with the tag `form:"-"` and unexported fields are skipped, and fields
+// without a value in the form are left unchanged. The supported field
+// types are those supported by [httperror.Query], and slices of them,
+// which receive all values of the form field.
+//
+// If the form can't be parsed or a value can't be parsed, BindForm returns
+// a BadRequest error with a public message naming the form field and the
+// expected type.
+//
+//	var filter struct {
+//		Status []string `form:"status"`
+//		Limit  int      `form:"limit"`
+//	}
+//	if err := httperror.BindForm(r, &filter); err != nil {
+//		return err
+//	}
+func BindForm(r *http.Request, dst interface{}) error {
+	if err := r.ParseForm(); err != nil {
+		return wrapPublic(err, http.StatusBadRequest, "malformed form")
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("httperror: BindForm destination must be a pointer to a struct, not %T", dst)
+	}
+	v = v.Elem()
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("form")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		values, ok := r.Form[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Slice && !isTextUnmarshaler(fv.Type()) {
+			s := reflect.MakeSlice(fv.Type(), len(values), len(values))
+			for j, value := range values {
+				if !parseValue(s.Index(j), value) {
+					return invalidParamError("form field", name, fv.Type().Elem())
+				}
+			}
+			fv.Set(s)
+			continue
+		}
+
+		if !parseValue(fv, values[0]) {
+			return invalidParamError("form field", name, fv.Type())
+		}
+	}
+	return nil
+}
+
+// parseParam parses s into *v, returning a BadRequest error describing the
+// parameter if it can't be parsed.
+func parseParam[T any](v *T, s, kind, name string) error {
+	rv := reflect.ValueOf(v).Elem()
+	if !parseValue(rv, s) {
+		return invalidParamError(kind, name, rv.Type())
+	}
+	return nil
+}
+
+func invalidParamError(kind, name string, t reflect.Type) error {
+	return PublicErrorf(http.StatusBadRequest, "invalid value for %s %q: expected %s", kind, name, typeDescription(t))
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// parseValue parses s into v, which must be settable, and reports whether
+// it succeeded.
+func parseValue(v reflect.Value, s string) bool {
+	t := v.Type()
+	if isTextUnmarshaler(t) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)) == nil
+	}
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return false
+		}
+		v.SetInt(int64(d))
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return false
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return false
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return false
+		}
+		v.SetFloat(f)
+	case reflect.Pointer:
+		p := reflect.New(t.Elem())
+		if !parseValue(p.Elem(), s) {
+			return false
+		}
+		v.Set(p)
+	default:
+		return false
+	}
+	return true
+}
+
+// typeDescription describes the values of t for public error messages.
+func typeDescription(t reflect.Type) string {
+	if isTextUnmarshaler(t) {
+		return t.Name()
+	}
+	if t == durationType {
+		return "duration"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Pointer:
+		return typeDescription(t.Elem())
+	}
+	return t.String()
+}
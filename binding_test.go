@@ -0,0 +1,74 @@
+package httperror_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?page=2&limit=ten&since=1h&at=2024-01-02T03:04:05Z", nil)
+
+	page, err := httperror.Query[int](r, "page")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page)
+
+	since, err := httperror.Query[time.Duration](r, "since")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, since)
+
+	at, err := httperror.Query[time.Time](r, "at")
+	assert.NoError(t, err)
+	assert.Equal(t, 2024, at.Year())
+
+	_, err = httperror.Query[int](r, "limit")
+	assert.Equal(t, 400, httperror.StatusCode(err))
+	assert.Equal(t, `invalid value for query parameter "limit": expected integer`, httperror.PublicMessage(err))
+
+	_, err = httperror.Query[bool](r, "verbose")
+	assert.Equal(t, 400, httperror.StatusCode(err))
+	assert.Equal(t, `missing query parameter "verbose"`, httperror.PublicMessage(err))
+
+	verbose, err := httperror.QueryDefault(r, "verbose", true)
+	assert.NoError(t, err)
+	assert.True(t, verbose)
+}
+
+func TestBindForm(t *testing.T) {
+	type filter struct {
+		Status   []string `form:"status"`
+		Limit    int      `form:"limit"`
+		Archived *bool    `form:"archived"`
+		Sort     string
+		Ignored  string `form:"-"`
+	}
+
+	r := httptest.NewRequest("POST", "/?status=open", strings.NewReader("status=closed&limit=10&archived=true&Sort=name&Ignored=x"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	f := filter{Limit: 25}
+	assert.NoError(t, httperror.BindForm(r, &f))
+	assert.Equal(t, []string{"closed", "open"}, f.Status)
+	assert.Equal(t, 10, f.Limit)
+	assert.True(t, *f.Archived)
+	assert.Equal(t, "name", f.Sort)
+	assert.Equal(t, "", f.Ignored)
+
+	r = httptest.NewRequest("GET", "/?limit=-1", nil)
+	f = filter{}
+	err := httperror.BindForm(r, &f)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, f.Limit)
+
+	r = httptest.NewRequest("GET", "/?archived=maybe", nil)
+	err = httperror.BindForm(r, &f)
+	assert.Equal(t, 400, httperror.StatusCode(err))
+	assert.Equal(t, `invalid value for form field "archived": expected boolean`, httperror.PublicMessage(err))
+
+	assert.Error(t, httperror.BindForm(r, f), "destination must be a pointer")
+}
@@ -0,0 +1,53 @@
+package httperror
+
+import (
+	"net/http"
+)
+
+// CachePolicy returns the Cache-Control header value for an error response
+// with the given status code. An empty string means no header is attached.
+type CachePolicy = func(status int) string
+
+// NoStore is a [httperror.CachePolicy] that forbids caching of all error
+// responses.
+func NoStore(status int) string {
+	return "no-store"
+}
+
+// StatusCachePolicy returns a [httperror.CachePolicy] that looks up the
+// Cache-Control value for the status code in policies, and falls back to
+// "no-store" for other statuses. For example, to let clients and proxies
+// cache 404s for a minute:
+//
+//	httperror.StatusCachePolicy(map[int]string{
+//		http.StatusNotFound: "public, max-age=60",
+//	})
+func StatusCachePolicy(policies map[int]string) CachePolicy {
+	return func(status int) string {
+		if v, ok := policies[status]; ok {
+			return v
+		}
+		return "no-store"
+	}
+}
+
+// CacheControlMiddleware returns an [httperror.Middleware] that attaches a
+// Cache-Control header chosen by policy to errors returned by the wrapped
+// handler (see [httperror.WithHeader]), so that error pages are not cached by
+// browsers or proxies by mistake. Errors that already have a Cache-Control
+// header attached are returned unchanged.
+func CacheControlMiddleware(policy CachePolicy) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := h.Serve(w, r)
+			if err == nil || Headers(err).Get("Cache-Control") != "" {
+				return err
+			}
+
+			if v := policy(StatusCode(err)); v != "" {
+				return WithHeader(err, "Cache-Control", v)
+			}
+			return err
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package httperror_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControlMiddleware(t *testing.T) {
+	policy := httperror.StatusCachePolicy(map[int]string{
+		http.StatusNotFound: "public, max-age=60",
+	})
+
+	serve := func(h httperror.Handler) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		httperror.CacheControlMiddleware(policy)(h).ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+		return rr
+	}
+
+	assert.Equal(t, "public, max-age=60", serve(notFoundHandler).Header().Get("Cache-Control"))
+	assert.Equal(t, "", serve(okHandler).Header().Get("Cache-Control"))
+
+	rr := serve(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.BadGateway
+	}))
+	assert.Equal(t, 502, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+	rr = serve(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.WithHeader(httperror.NotFound, "Cache-Control", "max-age=3600")
+	}))
+	assert.Equal(t, "max-age=3600", rr.Header().Get("Cache-Control"))
+
+	assert.Equal(t, "no-store", httperror.NoStore(404))
+}
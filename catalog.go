@@ -0,0 +1,148 @@
+package httperror
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProblemType documents a kind of error that an API can return. Problem
+// types are identified by a machine-readable error code (see
+// [httperror.WithCode]), which is also the last segment of the problem type
+// URI.
+type ProblemType struct {
+	Code        string
+	Status      int
+	Title       string
+	Description string
+}
+
+// Catalog is a registry of the problem types an API can return. It is used
+// to render problem+json responses (see [httperror.ProblemErrorHandler]),
+// and it implements [httperror.Handler], serving human-readable
+// documentation for each problem type so that problem type URIs dereference
+// to something useful:
+//
+//	http.Handle("/problems/", httperror.DefaultCatalog)
+//
+// The zero value is an empty catalog with no BaseURI.
+type Catalog struct {
+	// BaseURI is the prefix of problem type URIs. The URI of a problem type
+	// is the BaseURI followed by its code, e.g. "/problems/out_of_credit".
+	BaseURI string
+
+	mu    sync.RWMutex
+	types map[string]ProblemType
+}
+
+// DefaultCatalog is the catalog used by [httperror.RegisterProblemType] and
+// [httperror.ProblemErrorHandler].
+var DefaultCatalog = NewCatalog("/problems/")
+
+// NewCatalog returns an empty catalog whose problem type URIs start with the
+// given base URI.
+func NewCatalog(baseURI string) *Catalog {
+	return &Catalog{BaseURI: baseURI, types: make(map[string]ProblemType)}
+}
+
+// RegisterProblemType adds problem types to the [httperror.DefaultCatalog].
+func RegisterProblemType(types ...ProblemType) {
+	DefaultCatalog.Register(types...)
+}
+
+// Register adds problem types to the catalog, replacing any existing problem
+// types with the same code.
+func (c *Catalog) Register(types ...ProblemType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.types == nil {
+		c.types = make(map[string]ProblemType)
+	}
+	for _, t := range types {
+		c.types[t.Code] = t
+	}
+}
+
+// Lookup returns the problem type registered with the given code.
+func (c *Catalog) Lookup(code string) (ProblemType, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, ok := c.types[code]
+	return t, ok
+}
+
+// ProblemTypes returns all registered problem types, sorted by code.
+func (c *Catalog) ProblemTypes() []ProblemType {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	types := make([]ProblemType, 0, len(c.types))
+	for _, t := range c.types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Code < types[j].Code })
+	return types
+}
+
+// TypeURI returns the problem type URI for the given code.
+func (c *Catalog) TypeURI(code string) string {
+	return c.BaseURI + code
+}
+
+// ServeHTTP makes the catalog implement the standard [http.Handler]
+// interface. Any errors will be handled by the default error handler
+// [DefaultErrorHandler].
+func (c *Catalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := c.Serve(w, r)
+	if err != nil {
+		ReportError(r.Context(), r, err)
+		DefaultErrorHandler(w, err)
+	}
+}
+
+// Serve serves an HTML documentation page for the problem type named by the
+// last segment of the request path, or an index of all problem types if the
+// path ends with a slash. Returns NotFound for unknown problem types.
+func (c *Catalog) Serve(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if strings.HasSuffix(r.URL.Path, "/") {
+		return catalogIndexTemplate.Execute(w, c.ProblemTypes())
+	}
+
+	t, ok := c.Lookup(path.Base(r.URL.Path))
+	if !ok {
+		return NotFound
+	}
+
+	return problemTypeTemplate.Execute(w, t)
+}
+
+var catalogIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Problem Types</title></head><body>
+<h1>Problem Types</h1>
+<ul>
+{{- range .}}
+<li><a href="{{.Code}}">{{.Code}}</a>: {{.Title}}</li>
+{{- end}}
+</ul>
+</body></html>
+`))
+
+var problemTypeTemplate = template.Must(template.New("type").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head><body>
+<h1>{{.Title}}</h1>
+<dl>
+<dt>Code</dt><dd>{{.Code}}</dd>
+{{- if .Status}}
+<dt>Status</dt><dd>{{.Status}}</dd>
+{{- end}}
+</dl>
+<p>{{.Description}}</p>
+</body></html>
+`))
@@ -0,0 +1,164 @@
+package httperror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog(t *testing.T) {
+	c := httperror.NewCatalog("https://example.com/problems/")
+	c.Register(httperror.ProblemType{
+		Code:        "out_of_credit",
+		Status:      http.StatusForbidden,
+		Title:       "You do not have enough credit.",
+		Description: "The account balance is too low for this operation.",
+	})
+
+	{
+		h := httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.WithCode(httperror.NewPublic(http.StatusForbidden, "Your current balance is 30, but that costs 50."), "out_of_credit")
+		}, c.ProblemErrorHandler)
+
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 403, s)
+		assert.Equal(t, `{"type":"https://example.com/problems/out_of_credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50."}`+"\n", m)
+	}
+
+	{
+		h := httperror.WrapHandlerFunc(notFoundHandler, c.ProblemErrorHandler)
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, `{"type":"about:blank","title":"Not Found","status":404}`+"\n", m)
+	}
+
+	{
+		s, m := testRequest(c, "/problems/out_of_credit")
+		assert.Equal(t, 200, s)
+		assert.Contains(t, m, "<h1>You do not have enough credit.</h1>")
+		assert.Contains(t, m, "The account balance is too low for this operation.")
+	}
+
+	{
+		s, m := testRequest(c, "/problems/")
+		assert.Equal(t, 200, s)
+		assert.Contains(t, m, `<a href="out_of_credit">out_of_credit</a>`)
+	}
+
+	{
+		s, _ := testRequest(c, "/problems/no_such_problem")
+		assert.Equal(t, 404, s)
+	}
+
+	{
+		var zero httperror.Catalog
+		zero.Register(httperror.ProblemType{Code: "out_of_credit", Status: http.StatusForbidden})
+		_, ok := zero.Lookup("out_of_credit")
+		assert.True(t, ok, "zero value catalog is usable")
+	}
+}
+
+func TestCatalogOpenAPIComponents(t *testing.T) {
+	c := httperror.NewCatalog("/problems/")
+	c.Register(
+		httperror.ProblemType{Code: "out_of_credit", Status: http.StatusForbidden, Title: "You do not have enough credit."},
+		httperror.ProblemType{Code: "account_locked", Status: http.StatusForbidden, Title: "The account is locked.", Description: "Contact support."},
+	)
+
+	b, err := c.OpenAPIComponents()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var components struct {
+		Schemas   map[string]interface{}
+		Responses map[string]struct {
+			Description string
+			Content     map[string]struct {
+				Schema   map[string]string
+				Example  map[string]interface{}
+				Examples map[string]interface{}
+			}
+		}
+	}
+	if !assert.NoError(t, json.Unmarshal(b, &components)) {
+		return
+	}
+
+	assert.Contains(t, components.Schemas, "Problem")
+	assert.Len(t, components.Responses, 3)
+
+	r := components.Responses["account_locked"]
+	assert.Equal(t, "The account is locked. Contact support.", r.Description)
+	assert.Equal(t, "#/components/schemas/Problem", r.Content["application/problem+json"].Schema["$ref"])
+	assert.Equal(t, "/problems/account_locked", r.Content["application/problem+json"].Example["type"])
+
+	r = components.Responses["Problem403"]
+	assert.Equal(t, "Forbidden", r.Description)
+	assert.Len(t, r.Content["application/problem+json"].Examples, 2)
+}
+
+func TestProblem(t *testing.T) {
+	p := httperror.Problem{
+		Type:     "https://example.com/problems/out_of_credit",
+		Title:    "You do not have enough credit.",
+		Status:   http.StatusForbidden,
+		Detail:   "Your current balance is 30, but that costs 50.",
+		Instance: "/account/12345/msgs/abc",
+		Extensions: map[string]interface{}{
+			"balance":  30,
+			"accounts": []string{"/account/12345", "/account/67890"},
+			"status":   "ignored",
+		},
+	}
+
+	b, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"https://example.com/problems/out_of_credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","accounts":["/account/12345","/account/67890"],"balance":30}`, string(b))
+
+	var decoded httperror.Problem
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, p.Instance, decoded.Instance)
+	assert.Equal(t, map[string]interface{}{
+		"balance":  float64(30),
+		"accounts": []interface{}{"/account/12345", "/account/67890"},
+	}, decoded.Extensions)
+
+	err = httperror.FromProblem(decoded)
+	assert.True(t, errors.Is(err, httperror.Forbidden))
+	assert.Equal(t, "out_of_credit", httperror.ErrorCode(err))
+	assert.Equal(t, p.Detail, httperror.PublicMessage(err))
+
+	b, _ = json.Marshal(httperror.Problem{Type: "about:blank", Title: "Not Found", Status: 404})
+	assert.Equal(t, `{"type":"about:blank","title":"Not Found","status":404}`, string(b))
+}
+
+func TestToProblem(t *testing.T) {
+	c := httperror.NewCatalog("/problems/")
+	c.Register(httperror.ProblemType{Code: "out_of_credit", Status: http.StatusForbidden, Title: "You do not have enough credit."})
+
+	p := c.ToProblem(httperror.WithCode(httperror.NewPublic(http.StatusForbidden, "Your current balance is 30."), "out_of_credit"))
+	assert.Equal(t, httperror.Problem{Type: "/problems/out_of_credit", Title: "You do not have enough credit.", Status: 403, Detail: "Your current balance is 30."}, p)
+
+	invalid := httperror.WithCode(httperror.NewValidationError(httperror.FieldError{Field: "title", Code: "missing_field"}), "invalid_issue")
+	p = c.ToProblem(invalid)
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "invalid_issue", p.Extensions["code"])
+
+	b, _ := json.Marshal(p)
+	assert.Equal(t, `{"type":"about:blank","title":"Unprocessable Entity","status":422,"code":"invalid_issue","errors":[{"field":"title","code":"missing_field"}]}`, string(b))
+
+	var decoded httperror.Problem
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	err := httperror.FromProblem(decoded)
+	assert.Equal(t, 422, httperror.StatusCode(err))
+	assert.Equal(t, "invalid_issue", httperror.ErrorCode(err))
+	assert.Equal(t, httperror.FieldErrors(invalid), httperror.FieldErrors(err))
+
+	assert.Equal(t, 500, httperror.StatusCode(httperror.FromProblem(httperror.Problem{})))
+}
@@ -0,0 +1,96 @@
+/*
+Package chiadapter mounts [httperror.Handler]s on a chi router
+(github.com/go-chi/chi/v5). See the documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package chiadapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnwarden/httperror"
+)
+
+type contextKey string
+
+var routerKey interface{} = contextKey("router")
+
+type routerError struct {
+	err error
+}
+
+// Handle registers an [httperror.Handler] with a chi router for the given
+// pattern. When the router is served by [Handler], errors returned by the
+// handler are returned from Handler. Otherwise they are handled by
+// [httperror.DefaultErrorHandler].
+func Handle(r chi.Router, pattern string, h httperror.Handler) {
+	r.Handle(pattern, handler(h))
+}
+
+// Method registers an [httperror.Handler] with a chi router for the given
+// method and pattern. See [Handle].
+func Method(r chi.Router, method, pattern string, h httperror.Handler) {
+	r.Method(method, pattern, handler(h))
+}
+
+func handler(h httperror.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := h.Serve(w, r)
+		if err == nil {
+			return
+		}
+
+		if re, ok := r.Context().Value(routerKey).(*routerError); ok {
+			re.err = err
+			return
+		}
+
+		httperror.ReportError(r.Context(), r, err)
+		httperror.DefaultErrorHandler(w, err)
+	})
+}
+
+// Handler returns an [httperror.HandlerFunc] that dispatches requests to
+// the router, returning the errors returned by handlers registered with
+// [Handle] or [Method]. It sets the router's NotFound and MethodNotAllowed
+// handlers, so that requests that match no route return
+// httperror.NotFound or httperror.MethodNotAllowed. So all errors can be
+// handled by a single, router-wide error handler:
+//
+//	http.ListenAndServe(":8080", httperror.WrapHandlerFunc(chiadapter.Handler(r), errorHandler))
+func Handler(r chi.Router) httperror.HandlerFunc {
+	r.NotFound(handler(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NotFound
+	})).ServeHTTP)
+	r.MethodNotAllowed(handler(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.MethodNotAllowed
+	})).ServeHTTP)
+
+	return func(w http.ResponseWriter, req *http.Request) error {
+		re := &routerError{}
+		r.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), routerKey, re)))
+		return re.err
+	}
+}
+
+// Params returns an [httperror.Handler] that passes the chi URL parameters
+// of the request to h. It is an error to use it for a handler not
+// registered with a chi router.
+//
+//	chiadapter.Handle(r, "/orders/{id}", chiadapter.Params(orderHandler))
+func Params(h httperror.XHandler[chi.RouteParams]) httperror.Handler {
+	return httperror.Bind(h, routeParams)
+}
+
+var errNoRouteContext = httperror.Wrap(errors.New("no chi route context"), http.StatusInternalServerError)
+
+func routeParams(r *http.Request) (chi.RouteParams, error) {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return chi.RouteParams{}, errNoRouteContext
+	}
+	return rctx.URLParams, nil
+}
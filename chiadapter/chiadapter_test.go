@@ -0,0 +1,92 @@
+package chiadapter_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/chiadapter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRequest(h http.Handler, method, path string) (int, string) {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+	body, _ := io.ReadAll(rr.Result().Body)
+	return rr.Code, string(body)
+}
+
+func orderHandler(w http.ResponseWriter, r *http.Request, p chi.RouteParams) error {
+	for i, key := range p.Keys {
+		if key == "id" {
+			if p.Values[i] == "0" {
+				return httperror.NotFound
+			}
+			_, _ = w.Write([]byte("order " + p.Values[i]))
+			return nil
+		}
+	}
+	return httperror.BadRequest
+}
+
+func TestHandler(t *testing.T) {
+	r := chi.NewRouter()
+	chiadapter.Method(r, "GET", "/orders/{id}", chiadapter.Params(httperror.XHandlerFunc[chi.RouteParams](orderHandler)))
+	chiadapter.Handle(r, "/teapot", httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.Teapot
+	}))
+
+	var errs []error
+	h := httperror.WrapHandlerFunc(chiadapter.Handler(r), func(w http.ResponseWriter, err error) {
+		errs = append(errs, err)
+		w.WriteHeader(httperror.StatusCode(err))
+	})
+
+	{
+		s, m := testRequest(h, "GET", "/orders/42")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "order 42", m, "URL parameters are passed to the handler")
+	}
+
+	{
+		s, _ := testRequest(h, "GET", "/orders/0")
+		assert.Equal(t, 404, s)
+	}
+
+	{
+		s, _ := testRequest(h, "GET", "/teapot")
+		assert.Equal(t, 418, s)
+	}
+
+	{
+		s, _ := testRequest(h, "GET", "/missing")
+		assert.Equal(t, 404, s, "unmatched routes return NotFound")
+	}
+
+	{
+		s, _ := testRequest(h, "POST", "/orders/42")
+		assert.Equal(t, 405, s, "unmatched methods return MethodNotAllowed")
+	}
+
+	assert.Equal(t, []error{httperror.NotFound, httperror.Teapot, httperror.NotFound, httperror.MethodNotAllowed}, errs, "all errors reach the router-wide error handler")
+}
+
+func TestHandleWithoutHandler(t *testing.T) {
+	r := chi.NewRouter()
+	chiadapter.Handle(r, "/teapot", httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.Teapot
+	}))
+
+	s, _ := testRequest(r, "GET", "/teapot")
+	assert.Equal(t, 418, s, "errors are handled by the default error handler")
+}
+
+func TestParamsWithoutRouter(t *testing.T) {
+	h := chiadapter.Params(httperror.XHandlerFunc[chi.RouteParams](orderHandler))
+	err := h.Serve(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 500, httperror.StatusCode(err))
+}
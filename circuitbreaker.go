@@ -0,0 +1,193 @@
+package httperror
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a [httperror.CircuitBreaker].
+type CircuitState int
+
+const (
+	// CircuitClosed means requests are passed through to the handler.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means requests are rejected without calling the handler.
+	CircuitOpen
+	// CircuitHalfOpen means a single probe request is passed through to the
+	// handler to test whether it has recovered.
+	CircuitHalfOpen
+)
+
+// String returns the lower-case name of the state, e.g. for use as a
+// metrics label.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitBreaker tracks the errors returned by a handler and trips when too
+// many requests fail, so that a struggling handler (or the backend it
+// depends on) gets time to recover. Use it with
+// [httperror.CircuitBreakerMiddleware].
+type CircuitBreaker struct {
+	// IsFailure decides whether an error returned by the handler counts as a
+	// failure. If nil, server errors (5xx) are failures.
+	IsFailure func(error) bool
+
+	failureRate float64
+	minRequests int
+	window      time.Duration
+	cooldown    time.Duration
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	openedAt    time.Time
+	requests    int
+	failures    int
+	probing     bool
+}
+
+// NewCircuitBreaker returns a closed circuit breaker that trips when at
+// least failureRate (between 0 and 1) of the requests in a window fail,
+// provided there were at least minRequests requests in the window. Once
+// tripped, requests are rejected for the cooldown period, after which a
+// single probe request is let through: if it succeeds the breaker closes,
+// otherwise it stays open for another cooldown period. It panics if
+// failureRate is not in (0, 1], or if minRequests, window, or cooldown is
+// not positive.
+func NewCircuitBreaker(failureRate float64, minRequests int, window, cooldown time.Duration) *CircuitBreaker {
+	switch {
+	case !(failureRate > 0 && failureRate <= 1):
+		panic("httperror: NewCircuitBreaker failureRate must be in (0, 1], got " + strconv.FormatFloat(failureRate, 'g', -1, 64))
+	case minRequests <= 0:
+		panic("httperror: NewCircuitBreaker minRequests must be positive, got " + strconv.Itoa(minRequests))
+	case window <= 0:
+		panic("httperror: NewCircuitBreaker window must be positive, got " + window.String())
+	case cooldown <= 0:
+		panic("httperror: NewCircuitBreaker cooldown must be positive, got " + cooldown.String())
+	}
+
+	return &CircuitBreaker{
+		failureRate: failureRate,
+		minRequests: minRequests,
+		window:      window,
+		cooldown:    cooldown,
+		windowStart: time.Now(),
+	}
+}
+
+// State returns the current state of the circuit breaker.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.cooldown {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+// CircuitBreakerMiddleware returns an [httperror.Middleware] that passes
+// requests through cb. While the breaker is open, requests return
+// ServiceUnavailable with a Retry-After header attached (see
+// [httperror.WithRetryAfter]) without calling the wrapped handler.
+func CircuitBreakerMiddleware(cb *CircuitBreaker) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ok, isProbe, retryAfter := cb.allow()
+			if !ok {
+				return WithRetryAfter(ServiceUnavailable, retryAfter)
+			}
+
+			// A panic counts as a failure.
+			failed := true
+			defer func() { cb.record(failed, isProbe) }()
+
+			err := h.Serve(w, r)
+			failed = cb.isFailure(err)
+
+			return err
+		})
+	}
+}
+
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	if cb.IsFailure != nil {
+		return cb.IsFailure(err)
+	}
+	return StatusCode(err) >= 500
+}
+
+// allow reports whether a request may be passed through, and if so whether
+// it is the probe request of a half-open breaker, or else how long to wait
+// before retrying.
+func (cb *CircuitBreaker) allow() (ok, isProbe bool, retryAfter time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		elapsed := time.Since(cb.openedAt)
+		if elapsed < cb.cooldown {
+			return false, false, cb.cooldown - elapsed
+		}
+		cb.state = CircuitHalfOpen
+		cb.probing = true
+		return true, true, 0
+	case CircuitHalfOpen:
+		if cb.probing {
+			return false, false, cb.cooldown
+		}
+		cb.probing = true
+		return true, true, 0
+	}
+	return true, false, 0
+}
+
+// record records the outcome of a request. Only the probe request can
+// close or reopen a half-open breaker.
+func (cb *CircuitBreaker) record(failed, isProbe bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+
+	if isProbe {
+		cb.probing = false
+		if failed {
+			cb.state = CircuitOpen
+			cb.openedAt = now
+		} else {
+			cb.state = CircuitClosed
+			cb.windowStart, cb.requests, cb.failures = now, 0, 0
+		}
+		return
+	}
+
+	if cb.state != CircuitClosed {
+		// A request that started before the breaker tripped.
+		return
+	}
+
+	if now.Sub(cb.windowStart) >= cb.window {
+		cb.windowStart, cb.requests, cb.failures = now, 0, 0
+	}
+
+	cb.requests++
+	if failed {
+		cb.failures++
+	}
+
+	if cb.requests >= cb.minRequests && float64(cb.failures) >= cb.failureRate*float64(cb.requests) {
+		cb.state = CircuitOpen
+		cb.openedAt = now
+	}
+}
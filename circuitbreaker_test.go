@@ -0,0 +1,116 @@
+package httperror_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	cb := httperror.NewCircuitBreaker(0.5, 2, time.Minute, 50*time.Millisecond)
+
+	failing := true
+	calls := 0
+	h := httperror.CircuitBreakerMiddleware(cb)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		calls++
+		if failing {
+			return httperror.BadGateway
+		}
+		return nil
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+		return rr
+	}
+
+	assert.Equal(t, 502, serve().Code)
+	assert.Equal(t, httperror.CircuitClosed, cb.State())
+	assert.Equal(t, 502, serve().Code)
+	assert.Equal(t, httperror.CircuitOpen, cb.State())
+
+	rr := serve()
+	assert.Equal(t, 503, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	assert.Equal(t, 2, calls, "handler is not called while the breaker is open")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, httperror.CircuitHalfOpen, cb.State())
+
+	failing = false
+	assert.Equal(t, 200, serve().Code)
+	assert.Equal(t, httperror.CircuitClosed, cb.State())
+	assert.Equal(t, "closed", cb.State().String())
+}
+
+func TestCircuitBreakerStaleRequest(t *testing.T) {
+	cb := httperror.NewCircuitBreaker(0.5, 2, time.Minute, 20*time.Millisecond)
+
+	started := make(chan struct{}, 2)
+	release := map[string]chan struct{}{"/stale": make(chan struct{}), "/probe": make(chan struct{})}
+	h := httperror.CircuitBreakerMiddleware(cb)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if c, ok := release[r.URL.Path]; ok {
+			started <- struct{}{}
+			<-c
+			return nil
+		}
+		return httperror.BadGateway
+	}))
+
+	serve := func(path string) int {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr.Code
+	}
+	serveAsync := func(path string) chan int {
+		c := make(chan int, 1)
+		go func() { c <- serve(path) }()
+		return c
+	}
+
+	// A request that starts while the breaker is closed, and finishes while
+	// it is half-open.
+	stale := serveAsync("/stale")
+	<-started
+
+	assert.Equal(t, 502, serve("/fail"))
+	assert.Equal(t, 502, serve("/fail"))
+	assert.Equal(t, httperror.CircuitOpen, cb.State())
+
+	time.Sleep(30 * time.Millisecond)
+	probe := serveAsync("/probe")
+	<-started
+	assert.Equal(t, 503, serve("/fail"), "probe in flight")
+
+	close(release["/stale"])
+	assert.Equal(t, 200, <-stale)
+	assert.Equal(t, httperror.CircuitHalfOpen, cb.State(), "stale request doesn't close the breaker")
+	assert.Equal(t, 503, serve("/fail"), "no second probe")
+
+	close(release["/probe"])
+	assert.Equal(t, 200, <-probe)
+	assert.Equal(t, httperror.CircuitClosed, cb.State())
+}
+
+func TestNewCircuitBreakerInvalid(t *testing.T) {
+	assert.PanicsWithValue(t, "httperror: NewCircuitBreaker failureRate must be in (0, 1], got 0", func() {
+		httperror.NewCircuitBreaker(0, 2, time.Minute, time.Second)
+	})
+	assert.Panics(t, func() { httperror.NewCircuitBreaker(1.5, 2, time.Minute, time.Second) })
+	assert.PanicsWithValue(t, "httperror: NewCircuitBreaker minRequests must be positive, got 0", func() {
+		httperror.NewCircuitBreaker(0.5, 0, time.Minute, time.Second)
+	})
+	assert.PanicsWithValue(t, "httperror: NewCircuitBreaker window must be positive, got 0s", func() {
+		httperror.NewCircuitBreaker(0.5, 2, 0, time.Second)
+	})
+	assert.PanicsWithValue(t, "httperror: NewCircuitBreaker cooldown must be positive, got -1s", func() {
+		httperror.NewCircuitBreaker(0.5, 2, time.Minute, -time.Second)
+	})
+	assert.NotPanics(t, func() { httperror.NewCircuitBreaker(1, 1, time.Minute, time.Second) })
+}
@@ -0,0 +1,63 @@
+package httperror
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Get sends a GET request for url with client (or [http.DefaultClient] if
+// client is nil) and decodes the JSON response body into a T (see
+// [httperror.Do]).
+//
+//	order, err := httperror.Get[Order](ctx, client, "https://api.example.com/orders/42")
+//	if errors.Is(err, httperror.NotFound) {
+//		...
+//	}
+func Get[T any](ctx context.Context, client *http.Client, url string) (T, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return Do[T](client, req)
+}
+
+// Do sends req with client (or [http.DefaultClient] if client is nil) and
+// decodes the JSON response body into a T, so that client code returns
+// errors in the same way as handlers. It requests JSON with an Accept
+// header, unless req already has one. It returns:
+//
+//   - the error returned by the client, converted with
+//     [httperror.FromTransportError]
+//   - for non-2xx responses, the error returned by [httperror.FromResponse]
+//   - BadGateway if the response body can't be decoded
+//
+// The zero T is returned for responses without a body, such as 204 No
+// Content. The response body is always closed.
+func Do[T any](client *http.Client, req *http.Request) (T, error) {
+	var v T
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", contentTypeJSON)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return v, FromTransportError(err)
+	}
+	defer resp.Body.Close()
+
+	if err := FromResponse(resp); err != nil {
+		return v, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil && err != io.EOF {
+		return v, Wrap(err, http.StatusBadGateway)
+	}
+	return v, nil
+}
@@ -0,0 +1,329 @@
+package httperror_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	ID   string `json:"id"`
+	Item string `json:"item"`
+}
+
+func TestClient(t *testing.T) {
+	errOutOfStock := httperror.WithCode(httperror.Conflict, "out_of_stock")
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /orders/{id}", httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", r.Header.Get("Accept"))
+		switch r.PathValue("id") {
+		case "42":
+			return json.NewEncoder(w).Encode(order{ID: "42", Item: "widget"})
+		case "43":
+			return httperror.WithCode(httperror.NewPublic(http.StatusConflict, "widgets are out of stock"), "out_of_stock")
+		case "44":
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		case "45":
+			_, err := w.Write([]byte("{"))
+			return err
+		}
+		return httperror.NotFound
+	}))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+
+	o, err := httperror.Get[order](ctx, nil, ts.URL+"/orders/42")
+	assert.NoError(t, err)
+	assert.Equal(t, order{ID: "42", Item: "widget"}, o)
+
+	_, err = httperror.Get[order](ctx, ts.Client(), ts.URL+"/orders/43")
+	assert.True(t, errors.Is(err, errOutOfStock))
+	assert.Equal(t, "widgets are out of stock", httperror.PublicMessage(err))
+
+	o, err = httperror.Get[order](ctx, nil, ts.URL+"/orders/44")
+	assert.NoError(t, err)
+	assert.Equal(t, order{}, o)
+
+	_, err = httperror.Get[order](ctx, nil, ts.URL+"/orders/45")
+	assert.True(t, errors.Is(err, httperror.BadGateway))
+
+	_, err = httperror.Get[order](ctx, nil, ts.URL+"/orders/46")
+	assert.True(t, errors.Is(err, httperror.NotFound))
+
+	req, _ := http.NewRequest("DELETE", ts.URL+"/orders/42", nil)
+	_, err = httperror.Do[order](nil, req)
+	assert.True(t, errors.Is(err, httperror.MethodNotAllowed))
+
+	addr := ts.Listener.Addr().String()
+	ts.Close()
+	_, err = httperror.Get[order](ctx, nil, "http://"+addr+"/orders/42")
+	assert.True(t, httperror.IsRetryable(err))
+}
+
+func TestFromJSONError(t *testing.T) {
+	decode := func(body string) error {
+		var v struct {
+			Name     string `json:"name"`
+			Quantity int    `json:"quantity"`
+		}
+		d := json.NewDecoder(strings.NewReader(body))
+		d.DisallowUnknownFields()
+		return httperror.FromJSONError(d.Decode(&v))
+	}
+
+	assert.Nil(t, decode(`{"name":"widget"}`))
+
+	tests := []struct {
+		body   string
+		status int
+		public string
+	}{
+		{`{"name":}`, 400, "malformed JSON at offset 9: invalid character '}' looking for beginning of value"},
+		{`{"quantity":"two"}`, 400, `invalid value for field "quantity": expected number, got string`},
+		{`[1]`, 400, "invalid JSON value: expected object, got array"},
+		{`{"color":"red"}`, 400, `unknown field "color"`},
+		{`{"name":"wid`, 400, "unexpected end of JSON input"},
+		{``, 400, "empty request body"},
+	}
+	for _, tt := range tests {
+		err := decode(tt.body)
+		assert.Equal(t, tt.status, httperror.StatusCode(err), tt.body)
+		assert.Equal(t, tt.public, httperror.PublicMessage(err), tt.body)
+	}
+
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(decode(`{`+"x"), &syntaxErr))
+
+	err := httperror.FromJSONError(&http.MaxBytesError{Limit: 10})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, httperror.StatusCode(err))
+	assert.Equal(t, "request body larger than 10 bytes", httperror.PublicMessage(err))
+
+	assert.Equal(t, httperror.NotFound, httperror.FromJSONError(httperror.NotFound))
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type order struct {
+		Name     string `json:"name"`
+		Quantity int    `json:"quantity"`
+	}
+
+	decode := func(contentType, body string, opts ...httperror.DecodeOption) (order, error) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		var o order
+		err := httperror.DecodeJSON(r, &o, opts...)
+		return o, err
+	}
+
+	o, err := decode("application/json; charset=utf-8", `{"name":"widget","quantity":2}`)
+	assert.NoError(t, err)
+	assert.Equal(t, order{"widget", 2}, o)
+
+	_, err = decode("application/merge-patch+json", `{"name":"widget"}`)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		contentType string
+		body        string
+		opts        []httperror.DecodeOption
+		status      int
+		public      string
+	}{
+		{"", `{}`, nil, 415, "Content-Type must be application/json"},
+		{"text/plain", `{}`, nil, 415, "Content-Type must be application/json"},
+		{"application/json", `{"name":"` + strings.Repeat("x", 20) + `"}`, []httperror.DecodeOption{httperror.MaxBodyBytes(16)}, 413, "request body larger than 16 bytes"},
+		{"application/json", `{"color":"red"}`, nil, 400, `unknown field "color"`},
+		{"application/json", `{"name":"widget"} {}`, nil, 400, "request body must contain a single JSON value"},
+		{"application/json", `{"name":"widget"} }`, nil, 400, "malformed JSON at offset 19: invalid character '}' looking for beginning of value"},
+	}
+	for _, tt := range tests {
+		_, err := decode(tt.contentType, tt.body, tt.opts...)
+		assert.Equal(t, tt.status, httperror.StatusCode(err), tt.body)
+		assert.Equal(t, tt.public, httperror.PublicMessage(err), tt.body)
+	}
+
+	_, err = decode("application/json", `{"color":"red"}`, httperror.AllowUnknownFields())
+	assert.NoError(t, err)
+}
+
+func TestFromResponse(t *testing.T) {
+	respond := func(eh httperror.ErrorHandler, contentType string, err error) *http.Response {
+		rr := httptest.NewRecorder()
+		if contentType != "" {
+			rr.Header().Set("Content-Type", contentType)
+		}
+		eh(rr, err)
+		return rr.Result()
+	}
+
+	err := httperror.NewPublic(http.StatusNotFound, "no such order")
+	for _, resp := range []*http.Response{
+		respond(httperror.DefaultErrorHandler, "application/json", err),
+		respond(httperror.DefaultErrorHandler, "text/plain", err),
+		respond(httperror.DefaultErrorHandler, "", err),
+		respond(httperror.ProblemErrorHandler, "", err),
+	} {
+		e := httperror.FromResponse(resp)
+		assert.True(t, errors.Is(e, httperror.NotFound))
+		assert.Equal(t, 404, httperror.StatusCode(e))
+		assert.Equal(t, "no such order", httperror.PublicMessage(e))
+		assert.Equal(t, "404 Not Found: no such order", e.Error())
+	}
+
+	resp := respond(httperror.DefaultErrorHandler, "", httperror.WithRetryAfter(httperror.ServiceUnavailable, 2*time.Second))
+	e := httperror.FromResponse(resp)
+	assert.True(t, errors.Is(e, httperror.ServiceUnavailable))
+	assert.Equal(t, "", httperror.PublicMessage(e))
+	assert.Equal(t, "2", httperror.Headers(e).Get("Retry-After"))
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "Service Unavailable")
+
+	resp = respond(httperror.DefaultErrorHandler, "application/json", httperror.BadRequest)
+	assert.Equal(t, "", httperror.PublicMessage(httperror.FromResponse(resp)))
+
+	assert.Nil(t, httperror.FromResponse(&http.Response{StatusCode: 204, Body: http.NoBody}))
+}
+
+func TestFromResponseRoundTrip(t *testing.T) {
+	respond := func(eh httperror.ErrorHandler, contentType string, err error) *http.Response {
+		rr := httptest.NewRecorder()
+		if contentType != "" {
+			rr.Header().Set("Content-Type", contentType)
+		}
+		eh(rr, err)
+		return rr.Result()
+	}
+
+	catalog := httperror.NewCatalog("https://example.com/problems/")
+	catalog.Register(httperror.ProblemType{Code: "out_of_credit", Status: http.StatusForbidden, Title: "You do not have enough credit."})
+
+	coded := httperror.WithCode(httperror.NewPublic(http.StatusForbidden, "Your current balance is 30."), "out_of_credit")
+	invalid := httperror.WithCode(httperror.NewValidationError(
+		httperror.FieldError{Field: "title", Code: "missing_field"},
+		httperror.FieldError{Field: "body", Message: "is too long"},
+	), "invalid_issue")
+
+	for _, r := range []struct {
+		eh          httperror.ErrorHandler
+		contentType string
+	}{
+		{httperror.DefaultErrorHandler, "application/json"},
+		{httperror.ProblemErrorHandler, ""},
+		{catalog.ProblemErrorHandler, ""},
+	} {
+		e := httperror.FromResponse(respond(r.eh, r.contentType, coded))
+		assert.True(t, errors.Is(e, httperror.Forbidden))
+		assert.Equal(t, "out_of_credit", httperror.ErrorCode(e))
+		assert.Equal(t, "Your current balance is 30.", httperror.PublicMessage(e))
+
+		e = httperror.FromResponse(respond(r.eh, r.contentType, invalid))
+		assert.Equal(t, 422, httperror.StatusCode(e))
+		assert.Equal(t, "invalid_issue", httperror.ErrorCode(e))
+		assert.Equal(t, httperror.FieldErrors(invalid), httperror.FieldErrors(e))
+		assert.Equal(t, "422 Unprocessable Entity: title: missing_field, body: is too long", e.Error())
+	}
+
+	{
+		resp := &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Header:     http.Header{"Content-Type": {"application/json"}, "X-Request-Id": {"abc123"}},
+			Body:       io.NopCloser(strings.NewReader(`{"status":"fail","data":{"title":"A title is required","body":"Too long"}}`)),
+		}
+		e := httperror.FromResponse(resp)
+		assert.Equal(t, []httperror.FieldError{{Field: "body", Message: "Too long"}, {Field: "title", Message: "A title is required"}}, httperror.FieldErrors(e))
+		assert.Equal(t, "abc123", httperror.RequestID(e))
+	}
+
+	{
+		resp := &http.Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{"Content-Type": {"application/problem+json"}, "X-Request-Id": {"abc123"}},
+			Body:       io.NopCloser(strings.NewReader(`{"type":"about:blank","title":"Not Found","status":404,"request_id":"def456"}`)),
+		}
+		e := httperror.FromResponse(resp)
+		assert.Equal(t, "def456", httperror.RequestID(e))
+		assert.Equal(t, "", httperror.ErrorCode(e))
+	}
+
+	assert.Equal(t, "", httperror.RequestID(httperror.NotFound))
+}
+
+func TestFromResponseCaptureBytes(t *testing.T) {
+	body := `{"status":"error","message":"Bad Gateway: upstream failed","code":502}` + strings.Repeat(" ", 1000)
+	resp := &http.Response{
+		StatusCode: http.StatusBadGateway,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+
+	e := httperror.FromResponse(resp, httperror.CaptureBytes(100))
+	assert.Equal(t, body[:100], string(httperror.ResponseBody(e)))
+	assert.Equal(t, "upstream failed", httperror.PublicMessage(e))
+
+	rest, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, body, string(rest), "the body is preserved")
+
+	resp.Body = io.NopCloser(strings.NewReader(body))
+	e = httperror.FromResponse(resp, httperror.CaptureBytes(10))
+	assert.Equal(t, `{"status":`, string(httperror.ResponseBody(e)))
+	assert.Equal(t, "", httperror.PublicMessage(e), "truncated bodies can't be parsed")
+
+	assert.Nil(t, httperror.ResponseBody(httperror.BadGateway))
+}
+
+func TestFromTransportError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = http.Get("http://" + addr)
+	e := httperror.FromTransportError(err)
+	assert.Equal(t, 503, httperror.StatusCode(e), "connection refused")
+	assert.True(t, httperror.IsRetryable(e))
+	assert.ErrorIs(t, e, syscall.ECONNREFUSED)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	_, err = client.Get(ts.URL)
+	assert.Equal(t, 504, httperror.StatusCode(httperror.FromTransportError(err)), "timeout")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL, nil)
+	_, err = http.DefaultClient.Do(req)
+	e = httperror.FromTransportError(err)
+	assert.Equal(t, 499, httperror.StatusCode(e), "canceled")
+	assert.False(t, httperror.IsRetryable(e))
+
+	dnsErr := &url.Error{Op: "Get", URL: "http://nosuchhost.invalid", Err: &net.DNSError{Err: "no such host", Name: "nosuchhost.invalid", IsNotFound: true}}
+	assert.Equal(t, 502, httperror.StatusCode(httperror.FromTransportError(dnsErr)))
+	assert.Equal(t, 503, httperror.StatusCode(httperror.FromTransportError(&net.DNSError{Err: "server misbehaving", IsTemporary: true})))
+
+	assert.Equal(t, io.EOF, httperror.FromTransportError(io.EOF))
+	assert.Equal(t, httperror.NotFound, httperror.FromTransportError(httperror.NotFound))
+	assert.Nil(t, httperror.FromTransportError(nil))
+}
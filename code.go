@@ -0,0 +1,72 @@
+package httperror
+
+import (
+	"errors"
+)
+
+// Coded is an interface that requires an ErrorCode() string method.
+// [httperror.ErrorCode] will extract the machine-readable error code from
+// errors that implement this interface.
+type Coded = interface {
+	ErrorCode() string
+}
+
+// ErrorCode extracts the machine-readable error code (e.g. "card_declined")
+// from errors that have an `ErrorCode() string` method. Returns the empty
+// string if the error has no code.
+func ErrorCode(err error) string {
+	var codedError Coded
+
+	if err == nil {
+		return ""
+	}
+
+	if errors.As(err, &codedError) {
+		return codedError.ErrorCode()
+	}
+
+	return ""
+}
+
+// WithCode wraps an error and embeds a machine-readable error code that can
+// be extracted using [httperror.ErrorCode]. The status code and public
+// message of the wrapped error are preserved.
+//
+// Errors with a code compare equal with errors.Is to any other error with
+// the same code, so that error codes can be used as sentinel values, on the
+// server as well as on the client (see [httperror.FromResponse]):
+//
+//	var ErrOutOfCredit = httperror.WithCode(httperror.Forbidden, "out_of_credit")
+//
+//	if errors.Is(err, ErrOutOfCredit) {
+//		...
+//	}
+func WithCode(err error, code string) error {
+	return codedError{err, code}
+}
+
+type codedError struct {
+	inner error
+	code  string
+}
+
+// Error returns the error string of the wrapped error.
+func (e codedError) Error() string {
+	return e.inner.Error()
+}
+
+// Unwrap returns the inner error of a codedError
+func (e codedError) Unwrap() error {
+	return e.inner
+}
+
+func (e codedError) ErrorCode() string {
+	return e.code
+}
+
+// Is returns true if the target error is an error created by
+// [httperror.WithCode] with the same error code.
+func (e codedError) Is(target error) bool {
+	t, ok := target.(codedError)
+	return ok && e.code != "" && e.code == t.code
+}
@@ -0,0 +1,129 @@
+package httperror
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var collectorKey interface{} = contextKey("collector")
+
+// collector is the per-request error sink installed by
+// [httperror.CollectMiddleware]. While the handler runs, errors are
+// collected, and logged when it returns. Errors reported after that (for
+// example by goroutines started with [httperror.Go]) are logged right away.
+type collector struct {
+	logger Logger
+	r      *http.Request
+	start  time.Time
+
+	mu       sync.Mutex
+	errs     []error
+	finished bool
+	status   int
+}
+
+// Collect records a non-fatal error for the request with context ctx, such
+// as a cache miss treated as a soft failure or a partial upstream failure,
+// to be logged by [httperror.CollectMiddleware] when the request finishes
+// (or right away, if it has already finished). Unlike a returned error, it
+// doesn't change the response. Collect is safe for concurrent use. Nil
+// errors are ignored, as are errors collected for requests not handled by
+// CollectMiddleware.
+func Collect(ctx context.Context, err error) {
+	c, ok := ctx.Value(collectorKey).(*collector)
+	if !ok || err == nil {
+		return
+	}
+
+	c.mu.Lock()
+	if !c.finished {
+		c.errs = append(c.errs, err)
+		c.mu.Unlock()
+		return
+	}
+	status := c.status
+	c.mu.Unlock()
+
+	c.log(status, err)
+}
+
+// Collected returns the non-fatal errors collected so far for the request
+// with context ctx (see [httperror.Collect]).
+func Collected(ctx context.Context) []error {
+	c, ok := ctx.Value(collectorKey).(*collector)
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]error(nil), c.errs...)
+}
+
+// Go runs f in a new goroutine, reporting any error it returns, or any
+// panic, to the request with context ctx as a non-fatal error (see
+// [httperror.Collect]), instead of losing it or crashing the process. Panics
+// are converted to errors that can be identified using errors.Is(err,
+// httperror.Panic).
+func Go(ctx context.Context, f func() error) {
+	go func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(r)
+			}
+			Collect(ctx, err)
+		}()
+
+		err = f()
+	}()
+}
+
+// CollectMiddleware returns an [httperror.Middleware] that lets the wrapped
+// handler record non-fatal errors with [httperror.Collect] and
+// [httperror.Go]. When the handler returns, each collected error is logged
+// as a separate entry with NonFatal set, the status of the response, and a
+// severity of at most SeverityWarning. Errors collected after that are
+// logged right away. The error returned by the handler is returned
+// unchanged, to be logged by [httperror.LoggingMiddleware] as usual.
+func CollectMiddleware(logger Logger) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			c := &collector{logger: logger, r: r, start: time.Now()}
+			err := h.Serve(w, r.WithContext(context.WithValue(r.Context(), collectorKey, c)))
+
+			c.mu.Lock()
+			c.finished = true
+			c.status = StatusCode(err)
+			errs := c.errs
+			c.mu.Unlock()
+
+			for _, e := range errs {
+				c.log(c.status, e)
+			}
+
+			return err
+		})
+	}
+}
+
+func (c *collector) log(status int, err error) {
+	severity := ErrorSeverity(err)
+	if severity > SeverityWarning {
+		severity = SeverityWarning
+	}
+
+	c.logger.Log(LogEntry{
+		Method:    c.r.Method,
+		Path:      c.r.URL.Path,
+		Status:    status,
+		Duration:  time.Since(c.start),
+		RequestID: c.r.Header.Get(requestIDHeader),
+		Severity:  severity,
+		Err:       err,
+		NonFatal:  true,
+	})
+}
@@ -0,0 +1,63 @@
+package httperror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ConcurrencyLimitMiddleware returns an [httperror.Middleware] that allows
+// at most limit requests to be handled at the same time. Further requests
+// wait in a queue for up to queueTimeout. If no slot frees up in time, they
+// return ServiceUnavailable with a Retry-After header of one second attached
+// (see [httperror.WithRetryAfter]), instead of piling up. A queueTimeout of
+// 0 rejects requests immediately when the limit is reached. Requests whose
+// context is done while waiting return 499 Client Closed Request or 504
+// Gateway Timeout (see [httperror.ContextErrorMiddleware]). It panics if
+// limit is not positive.
+func ConcurrencyLimitMiddleware(limit int, queueTimeout time.Duration) Middleware {
+	if limit <= 0 {
+		panic("httperror: ConcurrencyLimitMiddleware limit must be positive, got " + strconv.Itoa(limit))
+	}
+	sem := make(chan struct{}, limit)
+
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if err := acquire(r.Context(), sem, queueTimeout); err != nil {
+				return err
+			}
+			defer func() { <-sem }()
+
+			return h.Serve(w, r)
+		})
+	}
+}
+
+func acquire(ctx context.Context, sem chan struct{}, timeout time.Duration) error {
+	select {
+	case sem <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if timeout <= 0 {
+		return WithRetryAfter(ServiceUnavailable, time.Second)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return WithRetryAfter(ServiceUnavailable, time.Second)
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return Wrap(ctx.Err(), http.StatusGatewayTimeout)
+		}
+		return Wrap(ctx.Err(), statusClientClosedRequest)
+	}
+}
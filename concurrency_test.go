@@ -0,0 +1,50 @@
+package httperror_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	h := httperror.ConcurrencyLimitMiddleware(1, 10*time.Millisecond)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		return nil
+	}))
+
+	done := make(chan int)
+	go func() {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", "/slow", nil))
+		done <- rr.Code
+	}()
+	<-started
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 503, rr.Code, "rejected when saturated")
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, 200, <-done)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 200, rr.Code, "slot is released")
+
+	assert.PanicsWithValue(t, "httperror: ConcurrencyLimitMiddleware limit must be positive, got 0", func() {
+		httperror.ConcurrencyLimitMiddleware(0, time.Second)
+	})
+	assert.Panics(t, func() { httperror.ConcurrencyLimitMiddleware(-1, time.Second) })
+}
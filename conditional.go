@@ -0,0 +1,138 @@
+package httperror
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModified represents the StatusNotModified HTTP response. It is not an
+// error, but returning it (see [httperror.CheckPreconditions]) lets
+// handlers end a request early through the error path.
+var NotModified = httpError{http.StatusNotModified}
+
+// CheckPreconditions evaluates the conditional request headers of r
+// (If-Match, If-Unmodified-Since, If-None-Match and If-Modified-Since, in
+// the order of RFC 9110 section 13.2.2) against the current entity tag and
+// modification time of the resource, so that handlers implement caching
+// and optimistic concurrency by returning the result:
+//
+//	if err := httperror.CheckPreconditions(r, order.ETag(), order.UpdatedAt); err != nil {
+//		return err
+//	}
+//
+// It returns NotModified for GET and HEAD requests whose cached
+// representation is current, PreconditionFailed if a precondition doesn't
+// hold, and nil if the request should be processed. NotModified errors
+// carry the ETag and Last-Modified headers (see [httperror.WithHeader]),
+// which a 304 response must include.
+//
+// etag is the quoted entity tag, such as `"v2"` or `W/"v2"`, or "" if the
+// resource has none or doesn't exist. lastModified is ignored if it is the
+// zero time.
+func CheckPreconditions(r *http.Request, etag string, lastModified time.Time) error {
+	isGetOrHead := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !etagListMatches(im, etag, true) {
+			return PreconditionFailed
+		}
+	} else if ius, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(ius) {
+			return PreconditionFailed
+		}
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagListMatches(inm, etag, false) {
+			if isGetOrHead {
+				return notModified(etag, lastModified)
+			}
+			return PreconditionFailed
+		}
+	} else if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && isGetOrHead && !lastModified.IsZero() {
+		if !lastModified.Truncate(time.Second).After(ims) {
+			return notModified(etag, lastModified)
+		}
+	}
+
+	return nil
+}
+
+// RequireIfMatch returns PreconditionRequired, with a public message, if r
+// is a request with an unsafe method (anything but GET, HEAD, OPTIONS and
+// TRACE) without an If-Match header, so that clients must use optimistic
+// concurrency (see [httperror.CheckPreconditions]) to modify a resource.
+func RequireIfMatch(r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return nil
+	}
+	if r.Header.Get("If-Match") == "" {
+		return NewPublic(http.StatusPreconditionRequired, "this request requires an If-Match header")
+	}
+	return nil
+}
+
+func notModified(etag string, lastModified time.Time) error {
+	var err error = NotModified
+	if etag != "" {
+		err = WithHeader(err, "ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		err = WithHeader(err, "Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	return err
+}
+
+// etagListMatches reports whether a list of entity tags from an If-Match
+// or If-None-Match header matches etag, using strong or weak comparison.
+// "*" matches any current entity tag.
+func etagListMatches(list, etag string, strong bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+
+	for list != "" {
+		list = strings.TrimLeft(list, " \t,")
+		tag, rest, ok := scanETag(list)
+		if !ok {
+			return false
+		}
+		if etagsMatch(tag, etag, strong) {
+			return true
+		}
+		list = rest
+	}
+	return false
+}
+
+// scanETag scans the entity tag at the start of s, returning it and the
+// remainder of s.
+func scanETag(s string) (etag, rest string, ok bool) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+	if len(s) < start+2 || s[start] != '"' {
+		return "", "", false
+	}
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", "", false
+	}
+	end += start + 2
+	return s[:end], s[end:], true
+}
+
+// etagsMatch compares two entity tags. Strong comparison requires both to
+// be strong.
+func etagsMatch(a, b string, strong bool) bool {
+	if strong {
+		return a == b && !strings.HasPrefix(a, "W/")
+	}
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
@@ -0,0 +1,112 @@
+package httperror_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPreconditions(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	check := func(method string, headers map[string]string) error {
+		r := httptest.NewRequest(method, "/", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return httperror.CheckPreconditions(r, `"v2"`, lastModified)
+	}
+
+	assert.Nil(t, check("GET", nil))
+
+	err := check("GET", map[string]string{"If-None-Match": `"v1", W/"v2"`})
+	assert.Equal(t, 304, httperror.StatusCode(err))
+	assert.Equal(t, `"v2"`, httperror.Headers(err).Get("ETag"))
+	assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", httperror.Headers(err).Get("Last-Modified"))
+
+	assert.Nil(t, check("GET", map[string]string{"If-None-Match": `"v1"`}))
+	assert.True(t, errors.Is(check("PUT", map[string]string{"If-None-Match": "*"}), httperror.PreconditionFailed))
+
+	assert.True(t, errors.Is(check("GET", map[string]string{"If-Modified-Since": "Tue, 02 Jan 2024 03:04:05 GMT"}), httperror.NotModified))
+	assert.Nil(t, check("GET", map[string]string{"If-Modified-Since": "Tue, 02 Jan 2024 03:04:04 GMT"}))
+	assert.Nil(t, check("GET", map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": "Tue, 02 Jan 2024 03:04:05 GMT"}), "If-None-Match takes precedence")
+
+	assert.Nil(t, check("PUT", map[string]string{"If-Match": `"v1", "v2"`}))
+	assert.True(t, errors.Is(check("PUT", map[string]string{"If-Match": `"v1"`}), httperror.PreconditionFailed))
+	assert.True(t, errors.Is(check("PUT", map[string]string{"If-Match": `W/"v2"`}), httperror.PreconditionFailed), "If-Match uses strong comparison")
+	assert.True(t, errors.Is(check("PUT", map[string]string{"If-Unmodified-Since": "Tue, 02 Jan 2024 00:00:00 GMT"}), httperror.PreconditionFailed))
+
+	r := httptest.NewRequest("PUT", "/", nil)
+	err = httperror.RequireIfMatch(r)
+	assert.Equal(t, 428, httperror.StatusCode(err))
+	r.Header.Set("If-Match", `"v2"`)
+	assert.Nil(t, httperror.RequireIfMatch(r))
+
+	{
+		h := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if err := httperror.CheckPreconditions(r, `"v2"`, time.Time{}); err != nil {
+				return err
+			}
+			w.Header().Set("ETag", `"v2"`)
+			_, err := io.WriteString(w, "hello")
+			return err
+		})
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("If-None-Match", `"v2"`)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		assert.Equal(t, 304, rr.Code)
+		assert.Equal(t, `"v2"`, rr.Header().Get("ETag"))
+	}
+}
+
+func TestNotModifiedResponse(t *testing.T) {
+	var reported int
+	httperror.RegisterErrorReporter(httperror.ErrorReporterFunc(func(ctx context.Context, r *http.Request, err error) {
+		reported++
+	}), func(err error) bool {
+		return errors.Is(err, httperror.NotModified)
+	})
+
+	h := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if err := httperror.CheckPreconditions(r, `"v2"`, time.Time{}); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "hello")
+		return err
+	})
+
+	for _, contentType := range []string{"", "text/plain", "application/json"} {
+		wrapped := httperror.WrapHandler(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			return h(w, r)
+		}), nil)
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("If-None-Match", `"v2"`)
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, r)
+
+		assert.Equal(t, 304, rr.Code)
+		assert.Equal(t, `"v2"`, rr.Header().Get("ETag"))
+		assert.Equal(t, 0, rr.Body.Len(), "%q: %s", contentType, rr.Body.String())
+		assert.Equal(t, contentType, rr.Header().Get("Content-Type"))
+	}
+
+	rr := httptest.NewRecorder()
+	httperror.WriteResponse(rr, http.StatusNoContent, []byte("No Content"))
+	assert.Equal(t, 0, rr.Body.Len())
+
+	assert.Equal(t, 0, reported, "NotModified is not reported")
+}
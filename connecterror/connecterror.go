@@ -0,0 +1,199 @@
+/*
+Package connecterror converts between httperrors and Connect errors
+(connectrpc.com/connect), so that business logic shared by HTTP and Connect
+endpoints can return one error type. See the documentation of the parent
+package at https://github.com/johnwarden/httperror
+*/
+package connecterror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/johnwarden/httperror"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+// Code returns the Connect code corresponding to the HTTP status code of
+// the error (see [httperror.GRPCCode]). Connect codes are the same as gRPC
+// codes. A nil error returns 0, which is not a valid Connect code.
+func Code(err error) connect.Code {
+	return connect.Code(httperror.GRPCCode(err))
+}
+
+// ToConnect converts an error into a *connect.Error. The code is given by
+// [Code], and the message is the public message of the error (see
+// [httperror.PublicMessage]), or the HTTP status text if there is none, so
+// that internal details are not sent to clients. Headers attached to the
+// error (see [httperror.WithHeader]), such as Retry-After, become the error
+// metadata. The error code (see [httperror.ErrorCode]) becomes an
+// ErrorInfo detail, and field errors (see [httperror.FieldErrors]) become a
+// BadRequest detail.
+//
+// Errors that are already Connect errors are returned as they are, and
+// context errors without a status code get the codes Canceled and
+// DeadlineExceeded. A nil error returns nil.
+func ToConnect(err error) *connect.Error {
+	if err == nil {
+		return nil
+	}
+
+	var ce *connect.Error
+	if errors.As(err, &ce) {
+		return ce
+	}
+
+	s := httperror.StatusCode(err)
+	if s == http.StatusInternalServerError {
+		switch {
+		case errors.Is(err, context.Canceled):
+			return connect.NewError(connect.CodeCanceled, err)
+		case errors.Is(err, context.DeadlineExceeded):
+			return connect.NewError(connect.CodeDeadlineExceeded, err)
+		}
+	}
+
+	m := httperror.PublicMessage(err)
+	if m == "" {
+		m = http.StatusText(s)
+	}
+	ce = connect.NewError(Code(err), errors.New(m))
+
+	for k, vs := range httperror.Headers(err) {
+		for _, v := range vs {
+			ce.Meta().Add(k, v)
+		}
+	}
+
+	if code := httperror.ErrorCode(err); code != "" {
+		addDetail(ce, &errdetails.ErrorInfo{Reason: code})
+	}
+
+	if fieldErrors := httperror.FieldErrors(err); len(fieldErrors) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range fieldErrors {
+			description := f.Message
+			if description == "" {
+				description = f.Code
+			}
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: description,
+			})
+		}
+		addDetail(ce, br)
+	}
+
+	return ce
+}
+
+// addDetail adds a detail to a Connect error, unless it can't be marshaled.
+func addDetail(ce *connect.Error, msg proto.Message) {
+	if d, err := connect.NewErrorDetail(msg); err == nil {
+		ce.AddDetail(d)
+	}
+}
+
+// FromConnect converts a Connect error, such as one returned by a Connect
+// client, into an httperror, reversing [ToConnect]: the HTTP status code
+// corresponds to the Connect code (see [httperror.StatusFromGRPCCode]), the
+// error message is the public message, the Retry-After metadata is
+// attached as a header, and ErrorInfo and BadRequest details become the
+// error code and field errors of the error. Other errors are returned
+// unchanged.
+func FromConnect(err error) error {
+	var ce *connect.Error
+	if !errors.As(err, &ce) {
+		return err
+	}
+
+	err = httperror.Wrap(connectError{ce}, httperror.StatusFromGRPCCode(int(ce.Code())))
+	if seconds, convErr := strconv.Atoi(ce.Meta().Get("Retry-After")); convErr == nil {
+		err = httperror.WithRetryAfter(err, time.Duration(seconds)*time.Second)
+	}
+	return err
+}
+
+// Interceptor returns a connect.Interceptor that converts errors returned
+// by handlers with [ToConnect], and errors returned to clients with
+// [FromConnect].
+func Interceptor() connect.Interceptor {
+	return interceptor{}
+}
+
+type interceptor struct{}
+
+func (interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		resp, err := next(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if req.Spec().IsClient {
+			return resp, FromConnect(err)
+		}
+		return resp, ToConnect(err)
+	}
+}
+
+func (interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := next(ctx, conn); err != nil {
+			return ToConnect(err)
+		}
+		return nil
+	}
+}
+
+// connectError is an error carrying a Connect error, with its message as
+// the public message.
+type connectError struct {
+	ce *connect.Error
+}
+
+func (e connectError) Error() string {
+	return e.ce.Message()
+}
+
+func (e connectError) Unwrap() error {
+	return e.ce
+}
+
+func (e connectError) PublicMessage() string {
+	return e.ce.Message()
+}
+
+func (e connectError) ErrorCode() string {
+	for _, d := range e.ce.Details() {
+		if v, err := d.Value(); err == nil {
+			if ei, ok := v.(*errdetails.ErrorInfo); ok {
+				return ei.Reason
+			}
+		}
+	}
+	return ""
+}
+
+func (e connectError) FieldErrors() []httperror.FieldError {
+	var fieldErrors []httperror.FieldError
+	for _, d := range e.ce.Details() {
+		if v, err := d.Value(); err == nil {
+			if br, ok := v.(*errdetails.BadRequest); ok {
+				for _, f := range br.FieldViolations {
+					fieldErrors = append(fieldErrors, httperror.FieldError{Field: f.Field, Message: f.Description})
+				}
+			}
+		}
+	}
+	return fieldErrors
+}
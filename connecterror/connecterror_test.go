@@ -0,0 +1,76 @@
+package connecterror_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/connecterror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToConnect(t *testing.T) {
+	assert.Nil(t, connecterror.ToConnect(nil))
+
+	{
+		ce := connecterror.ToConnect(httperror.NewPublic(404, "no such order"))
+		assert.Equal(t, connect.CodeNotFound, ce.Code())
+		assert.Equal(t, "no such order", ce.Message())
+	}
+
+	{
+		ce := connecterror.ToConnect(errors.New("secret database details"))
+		assert.Equal(t, connect.CodeInternal, ce.Code())
+		assert.Equal(t, "Internal Server Error", ce.Message(), "private messages are not exposed")
+	}
+
+	{
+		ce := connecterror.ToConnect(httperror.WithRetryAfter(httperror.TooManyRequests, 10*time.Second))
+		assert.Equal(t, connect.CodeResourceExhausted, ce.Code())
+		assert.Equal(t, "10", ce.Meta().Get("Retry-After"), "headers become metadata")
+	}
+
+	assert.Equal(t, connect.CodeCanceled, connecterror.ToConnect(context.Canceled).Code())
+
+	original := connect.NewError(connect.CodeAborted, errors.New("conflict"))
+	assert.Equal(t, original, connecterror.ToConnect(original))
+}
+
+func TestFromConnect(t *testing.T) {
+	original := httperror.WithRetryAfter(httperror.WithCode(httperror.NewValidationError(
+		httperror.FieldError{Field: "email", Message: "is required"},
+	), "invalid_signup"), 5*time.Second)
+
+	err := connecterror.FromConnect(connecterror.ToConnect(original))
+	assert.Equal(t, 400, httperror.StatusCode(err))
+	assert.Equal(t, "Unprocessable Entity", httperror.PublicMessage(err))
+	assert.Equal(t, "invalid_signup", httperror.ErrorCode(err))
+	assert.Equal(t, []httperror.FieldError{{Field: "email", Message: "is required"}}, httperror.FieldErrors(err))
+	assert.Equal(t, "5", httperror.Headers(err).Get("Retry-After"))
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), "original error is preserved")
+
+	other := errors.New("other")
+	assert.Equal(t, other, connecterror.FromConnect(other))
+}
+
+func TestInterceptor(t *testing.T) {
+	i := connecterror.Interceptor()
+
+	unary := i.WrapUnary(func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		return nil, httperror.Forbidden
+	})
+	_, err := unary(context.Background(), connect.NewRequest(&struct{}{}))
+
+	var ce *connect.Error
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, connect.CodePermissionDenied, ce.Code())
+
+	streaming := i.WrapStreamingHandler(func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return httperror.Unauthorized
+	})
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(streaming(context.Background(), nil)))
+}
@@ -0,0 +1,17 @@
+module github.com/johnwarden/httperror/connecterror
+
+go 1.22
+
+require (
+	connectrpc.com/connect v1.16.2
+	github.com/johnwarden/httperror v0.0.0-20261016035624-08a2347946c7
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237
+	google.golang.org/protobuf v1.33.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,57 @@
+package httperror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ContextErrorMiddleware wraps a [httperror.Handler], returning a new
+// [httperror.HandlerFunc] that inspects the request context after the
+// handler returns. If the context is done and the handler returned nil or
+// an error without a status code, the error is converted to 499 Client
+// Closed Request if the client went away (context.Canceled), or 504 Gateway
+// Timeout if the deadline was exceeded. This way aborted requests are not
+// logged as successes or as generic server errors. Errors that already have
+// a status code are returned unchanged.
+//
+// If the handler had already started writing the response, the returned
+// error is marked as already written (see [httperror.ResponseWritten]), so
+// no error response is written.
+func ContextErrorMiddleware(h Handler) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		rw := NewResponseWriter(w)
+		err := h.Serve(rw, r)
+		return contextError(r.Context(), rw, err)
+	}
+}
+
+// XContextErrorMiddleware is a generic version of
+// [httperror.ContextErrorMiddleware].
+func XContextErrorMiddleware[P any](h XHandler[P]) XHandlerFunc[P] {
+	return func(w http.ResponseWriter, r *http.Request, p P) error {
+		rw := NewResponseWriter(w)
+		err := h.Serve(rw, r, p)
+		return contextError(r.Context(), rw, err)
+	}
+}
+
+func contextError(ctx context.Context, rw *ResponseWriter, err error) error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil || HasStatusCode(err) {
+		return err
+	}
+
+	s := statusClientClosedRequest
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		s = http.StatusGatewayTimeout
+	}
+
+	if err == nil {
+		err = ctxErr
+	}
+	if rw.Written() {
+		return writtenError{Wrap(err, s)}
+	}
+	return Wrap(err, s)
+}
@@ -0,0 +1,113 @@
+package httperror
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+)
+
+var csrfKey interface{} = contextKey("csrf")
+
+// CSRFConfig configures [httperror.CSRFMiddleware]. Zero values use the
+// defaults.
+type CSRFConfig struct {
+	// CookieName is the name of the cookie holding the token. The default
+	// is "csrf_token".
+	CookieName string
+
+	// HeaderName is the request header in which clients submit the token.
+	// The default is "X-CSRF-Token".
+	HeaderName string
+
+	// FormField is the form field in which clients submit the token, if the
+	// header is absent. The default is "csrf_token".
+	FormField string
+
+	// Secure sets the Secure attribute of the cookie.
+	Secure bool
+}
+
+// CSRFMiddleware returns an [httperror.Middleware] that protects against
+// cross-site request forgery using the double-submit cookie pattern. It
+// sets a random token in a cookie if the request doesn't have one, and
+// requires requests with unsafe methods (anything but GET, HEAD, OPTIONS
+// and TRACE) to submit the same token in a header or form field. Rejected
+// requests return Forbidden with a public message, instead of writing a
+// response, so that the application's error pages and logging apply.
+//
+// Handlers can get the token with [httperror.CSRFToken], to embed it in
+// forms.
+func CSRFMiddleware(cfg CSRFConfig) Middleware {
+	if cfg.CookieName == "" {
+		cfg.CookieName = "csrf_token"
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+	if cfg.FormField == "" {
+		cfg.FormField = "csrf_token"
+	}
+
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			var token string
+			if c, err := r.Cookie(cfg.CookieName); err == nil && c.Value != "" {
+				token = c.Value
+			}
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			default:
+				if token == "" {
+					return NewPublic(http.StatusForbidden, "missing CSRF cookie")
+				}
+
+				submitted := r.Header.Get(cfg.HeaderName)
+				if submitted == "" {
+					submitted = r.PostFormValue(cfg.FormField)
+				}
+				if submitted == "" {
+					return NewPublic(http.StatusForbidden, "missing CSRF token")
+				}
+				if subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+					return NewPublic(http.StatusForbidden, "invalid CSRF token")
+				}
+			}
+
+			if token == "" {
+				var err error
+				token, err = newCSRFToken()
+				if err != nil {
+					return err
+				}
+				http.SetCookie(w, &http.Cookie{
+					Name:     cfg.CookieName,
+					Value:    token,
+					Path:     "/",
+					Secure:   cfg.Secure,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+
+			return h.Serve(w, r.WithContext(context.WithValue(r.Context(), csrfKey, token)))
+		})
+	}
+}
+
+// CSRFToken returns the CSRF token for a request handled by
+// [httperror.CSRFMiddleware], or "" if there is none.
+func CSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfKey).(string)
+	return token
+}
+
+// newCSRFToken returns a random, URL-safe token.
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,109 @@
+package httperror
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DrainController tracks in-flight requests and, once draining, rejects new
+// ones, enabling clean rollouts without a reverse proxy. Use it with
+// [httperror.DrainMiddleware].
+type DrainController struct {
+	retryAfter time.Duration
+
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	idle     chan struct{}
+}
+
+// NewDrainController returns a DrainController. Requests rejected while
+// draining carry a Retry-After header of retryAfter.
+func NewDrainController(retryAfter time.Duration) *DrainController {
+	return &DrainController{
+		retryAfter: retryAfter,
+		idle:       make(chan struct{}),
+	}
+}
+
+// Drain starts draining: new requests are rejected, while in-flight
+// requests finish. Calling Drain more than once has no further effect.
+func (dc *DrainController) Drain() {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if dc.draining {
+		return
+	}
+	dc.draining = true
+	if dc.inFlight == 0 {
+		close(dc.idle)
+	}
+}
+
+// Draining reports whether Drain has been called.
+func (dc *DrainController) Draining() bool {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	return dc.draining
+}
+
+// InFlight returns the number of requests currently being handled.
+func (dc *DrainController) InFlight() int {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	return dc.inFlight
+}
+
+// Wait blocks until Drain has been called and all in-flight requests have
+// finished, or until ctx is done, in which case it returns ctx.Err().
+func (dc *DrainController) Wait(ctx context.Context) error {
+	select {
+	case <-dc.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DrainMiddleware returns an [httperror.Middleware] that tracks requests
+// with dc. Once dc is draining, requests return ServiceUnavailable with a
+// Retry-After header and a Connection: close header attached (see
+// [httperror.WithHeader]), so that clients retry on another instance.
+func DrainMiddleware(dc *DrainController) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if !dc.begin() {
+				return WithHeader(WithRetryAfter(ServiceUnavailable, dc.retryAfter), "Connection", "close")
+			}
+			defer dc.end()
+
+			return h.Serve(w, r)
+		})
+	}
+}
+
+func (dc *DrainController) begin() bool {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if dc.draining {
+		return false
+	}
+	dc.inFlight++
+	return true
+}
+
+func (dc *DrainController) end() {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	dc.inFlight--
+	if dc.draining && dc.inFlight == 0 {
+		close(dc.idle)
+	}
+}
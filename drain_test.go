@@ -0,0 +1,54 @@
+package httperror_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainMiddleware(t *testing.T) {
+	dc := httperror.NewDrainController(5 * time.Second)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	h := httperror.DrainMiddleware(dc)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		return nil
+	}))
+
+	done := make(chan int)
+	go func() {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", "/slow", nil))
+		done <- rr.Code
+	}()
+	<-started
+	assert.Equal(t, 1, dc.InFlight())
+
+	dc.Drain()
+	assert.True(t, dc.Draining())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 503, rr.Code, "new requests are rejected while draining")
+	assert.Equal(t, "5", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "close", rr.Header().Get("Connection"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, dc.Wait(ctx), "in-flight request hasn't finished")
+
+	close(release)
+	assert.Equal(t, 200, <-done, "in-flight request finishes")
+	assert.Nil(t, dc.Wait(context.Background()))
+}
@@ -1,7 +1,12 @@
 package httperror_test
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -84,3 +89,165 @@ func TestErrors(t *testing.T) {
 		assert.True(t, errors.Is(e, httperror.BadRequest))
 	}
 }
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestFromSQLError(t *testing.T) {
+	assert.Nil(t, httperror.FromSQLError(nil))
+
+	err := httperror.FromSQLError(fmt.Errorf("get order: %w", sql.ErrNoRows))
+	assert.Equal(t, http.StatusNotFound, httperror.StatusCode(err))
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+
+	assert.Equal(t, http.StatusConflict, httperror.StatusCode(httperror.FromSQLError(sqlStateError("23505"))))
+	assert.Equal(t, http.StatusServiceUnavailable, httperror.StatusCode(httperror.FromSQLError(sqlStateError("40001"))))
+	assert.Equal(t, http.StatusServiceUnavailable, httperror.StatusCode(httperror.FromSQLError(driver.ErrBadConn)))
+
+	other := sqlStateError("42601")
+	assert.Equal(t, error(other), httperror.FromSQLError(other))
+
+	errLocked := errors.New("database is locked")
+	httperror.RegisterSQLErrorClassifier(func(err error) int {
+		if errors.Is(err, errLocked) {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	})
+	assert.Equal(t, http.StatusServiceUnavailable, httperror.StatusCode(httperror.FromSQLError(errLocked)))
+}
+
+func TestErrorCodeIs(t *testing.T) {
+	errOutOfCredit := httperror.WithCode(httperror.Forbidden, "out_of_credit")
+	errCardDeclined := httperror.WithCode(httperror.PaymentRequired, "card_declined")
+
+	classify := func(err error) string {
+		switch {
+		case errors.Is(err, errOutOfCredit):
+			return "out of credit"
+		case errors.Is(err, errCardDeclined):
+			return "card declined"
+		case errors.Is(err, httperror.Forbidden):
+			return "forbidden"
+		case errors.Is(err, httperror.NotFound):
+			return "not found"
+		}
+		return "other"
+	}
+
+	serverErrs := []error{
+		httperror.WithCode(httperror.NewPublic(http.StatusForbidden, "Your current balance is 30."), "out_of_credit"),
+		fmt.Errorf("charging: %w", errCardDeclined),
+		httperror.WithCode(httperror.Forbidden, "account_locked"),
+		httperror.NotFound,
+		httperror.WithCode(httperror.InternalServerError, ""),
+	}
+	expected := []string{"out of credit", "card declined", "forbidden", "not found", "other"}
+
+	catalog := httperror.NewCatalog("/problems/")
+	catalog.Register(httperror.ProblemType{Code: "out_of_credit", Status: http.StatusForbidden, Title: "You do not have enough credit."})
+
+	for i, err := range serverErrs {
+		assert.Equal(t, expected[i], classify(err), "server: %v", err)
+
+		for _, eh := range []httperror.ErrorHandler{httperror.DefaultErrorHandler, catalog.ProblemErrorHandler} {
+			rr := httptest.NewRecorder()
+			rr.Header().Set("Content-Type", "application/json")
+			eh(rr, err)
+			assert.Equal(t, expected[i], classify(httperror.FromResponse(rr.Result())), "client: %v", err)
+		}
+	}
+}
+
+func TestNewAllocs(t *testing.T) {
+	assert.Equal(t, "400 Bad Request: 100% invalid", httperror.New(http.StatusBadRequest, "100% invalid").Error())
+
+	inner := io.ErrUnexpectedEOF
+	e := httperror.Errorf(http.StatusBadRequest, "reading body: %w", inner)
+	assert.Equal(t, "400 Bad Request: reading body: unexpected EOF", e.Error())
+	assert.True(t, errors.Is(e, inner))
+
+	var sink error
+	assert.LessOrEqual(t, testing.AllocsPerRun(100, func() {
+		sink = httperror.New(http.StatusNotFound, "no such user")
+	}), 1.0, "New")
+	assert.LessOrEqual(t, testing.AllocsPerRun(100, func() {
+		sink = httperror.Wrap(inner, http.StatusNotFound)
+	}), 1.0, "Wrap")
+	_ = sink
+}
+
+var benchmarkErr error
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkErr = httperror.New(http.StatusNotFound, "no such user")
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkErr = httperror.Wrap(io.ErrUnexpectedEOF, http.StatusNotFound)
+	}
+}
+
+func BenchmarkErrorf(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkErr = httperror.Errorf(http.StatusNotFound, "no such user %d", i)
+	}
+}
+
+func BenchmarkStatusCode(b *testing.B) {
+	foreign := error(httperror.NotFound)
+	for i := 0; i < 10; i++ {
+		foreign = fmt.Errorf("layer %d: %w", i, foreign)
+	}
+
+	for _, bm := range []struct {
+		name string
+		err  error
+	}{
+		{"sentinel", httperror.NotFound},
+		{"wrapped", httperror.Wrap(io.ErrUnexpectedEOF, http.StatusNotFound)},
+		{"decorated", httperror.WithCode(httperror.WithHeader(httperror.Wrap(io.ErrUnexpectedEOF, http.StatusTooManyRequests), "Retry-After", "1"), "rate_limited")},
+		{"foreign", foreign},
+		{"none", io.ErrUnexpectedEOF},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = httperror.StatusCode(bm.err)
+			}
+		})
+	}
+}
+
+func TestErrorMemoized(t *testing.T) {
+	for _, e := range []error{
+		httperror.New(http.StatusNotFound, "no such user"),
+		httperror.Wrap(io.ErrUnexpectedEOF, http.StatusBadRequest),
+		httperror.NewPublic(http.StatusForbidden, "not allowed"),
+		httperror.PublicErrorf(http.StatusConflict, "version %d", 3),
+	} {
+		s := e.Error()
+		same := true
+		assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+			same = same && e.Error() == s
+		}), s)
+		assert.True(t, same, s)
+	}
+}
+
+func BenchmarkError(b *testing.B) {
+	err := httperror.Wrap(fmt.Errorf("user %d: %w", 42, io.ErrUnexpectedEOF), http.StatusNotFound)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = err.Error()
+	}
+}
@@ -2,14 +2,15 @@ package httperror
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"mime"
 	"net/http"
 	"strconv"
+	"sync"
 )
 
-// const contentTypeHTML = "text/html"
 const (
+	contentTypeHTML      = "text/html; charset=utf-8"
 	contentTypeTextPlain = "text/plain"
 	contentTypeText      = "text"
 	contentTypeJSON      = "application/json"
@@ -21,70 +22,153 @@ type ErrorHandler = func(http.ResponseWriter, error)
 // DefaultErrorHandler writes a reasonable default error response, using the status
 // code from the error if it can be extracted (see [StatusCode]), or 500 by
 // default, using the content type from from w.Header(), or text/html by
-// default, and using any public message (see [PublicErrorf] and [Public].)
+// default (in which case the Content-Type header is set), and using any
+// public message (see [PublicErrorf] and [Public].) Any headers attached to
+// the error (see [WithHeader]) are set on the response. JSON responses also
+// include the error code (see [ErrorCode]) and field errors (see
+// [FieldErrors]), if any, in a data member. Responses with a status that
+// doesn't allow a body, such as [NotModified], have only headers.
+//
+// If the response for the error has already been written (see
+// [ResponseWritten]), DefaultErrorHandler does nothing.
 func DefaultErrorHandler(w http.ResponseWriter, e error) {
+	if ResponseWritten(e) {
+		return
+	}
+
 	s := StatusCode(e)
+	setErrorHeaders(w, e)
+	if !bodyAllowed(s) {
+		w.WriteHeader(s)
+		return
+	}
+	if _, ok := w.Header()["Content-Type"]; !ok {
+		w.Header().Set("Content-Type", contentTypeHTML)
+	}
 	w.WriteHeader(s)
 
-	var b bytes.Buffer
-	b.WriteString(http.StatusText(s))
+	b := getBuffer()
+	defer putBuffer(b)
+
+	b.WriteString(statusText(s))
 	if s := PublicMessage(e); s != "" {
 		b.WriteString(": ")
 		b.WriteString(s)
 	}
 
-	WriteResponse(w, s, b.Bytes())
+	writeResponse(w, s, b.Bytes(), errorData(e))
+}
+
+// ResponseWritten reports whether the response for an error has already
+// been written, for example by standard middleware that denied a request
+// (see [ApplyStandardMiddleware]). Such errors are returned so that logging
+// and other error middleware see them, but the handler wrappers in this
+// package don't pass them to error handlers, and [DefaultErrorHandler]
+// ignores them. Custom error handlers that may be called with such errors
+// should check this first.
+func ResponseWritten(err error) bool {
+	var we interface{ ResponseWritten() bool }
+	return errors.As(err, &we) && we.ResponseWritten()
+}
+
+// writtenError wraps an error whose response has already been written.
+type writtenError struct {
+	inner error
+}
+
+// Error returns the error string of the wrapped error.
+func (e writtenError) Error() string {
+	return e.inner.Error()
+}
+
+// Unwrap returns the inner error of a writtenError
+func (e writtenError) Unwrap() error {
+	return e.inner
+}
+
+func (e writtenError) ResponseWritten() bool {
+	return true
 }
 
 // WriteResponse writes a reasonable default error response given the status
-// code and optional error message. The default error handler
+// code and optional error message, or nothing for statuses that don't allow
+// a body (1xx, 204 No Content and 304 Not Modified). The default error handler
 // [DefaultErrorHandler] calls this method after extracting the status code and any
 // public error message.
 func WriteResponse(w http.ResponseWriter, s int, m []byte) {
-	contentType := responseContentType(w)
+	writeResponse(w, s, m, nil)
+}
 
-	switch contentType {
+// writeResponse is like WriteResponse, but also writes the error code and
+// field errors in data, if any, to JSON responses. The body is assembled in
+// a pooled buffer and written at once.
+func writeResponse(w http.ResponseWriter, s int, m []byte, data *jsonErrorData) {
+	if !bodyAllowed(s) {
+		return
+	}
+
+	b := getBuffer()
+	defer putBuffer(b)
+
+	switch responseContentType(w) {
 	case contentTypeJSON:
-		writeJsonErrorBody(w, s, m)
+		writeJsonErrorBody(b, s, m, data)
 	case contentTypeTextPlain:
-		writePlainTextErrorBody(w, s, m)
+		writePlainTextErrorBody(b, s, m)
 	case contentTypeText:
-		writePlainTextErrorBody(w, s, m)
+		writePlainTextErrorBody(b, s, m)
 	default:
-		writeHtmlErrorBody(w, s, m)
+		writeHtmlErrorBody(b, s, m)
 	}
+
+	_, _ = w.Write(b.Bytes())
 }
 
-func writeHtmlErrorBody(w http.ResponseWriter, s int, m []byte) {
-	_, _ = w.Write([]byte(`<html><head><meta http-equiv="Content-Type" content="text/html; charset=UTF-8"><title>`))
-	_, _ = w.Write([]byte(`Error `))
-	_, _ = w.Write([]byte(strconv.Itoa(s)))
-	_, _ = w.Write([]byte(`</title></head><body>`))
-	_, _ = w.Write([]byte(m))
-	_, _ = w.Write([]byte("</body></html>\n"))
+func writeHtmlErrorBody(b *bytes.Buffer, s int, m []byte) {
+	b.WriteString(`<html><head><meta http-equiv="Content-Type" content="text/html; charset=UTF-8"><title>`)
+	b.WriteString(`Error `)
+	b.WriteString(strconv.Itoa(s))
+	b.WriteString(`</title></head><body>`)
+	b.Write(m)
+	b.WriteString("</body></html>\n")
 }
 
-func writePlainTextErrorBody(w http.ResponseWriter, s int, m []byte) {
-	_, _ = w.Write([]byte(strconv.Itoa(s)))
-	_, _ = w.Write([]byte(` `))
-	_, _ = w.Write([]byte(m))
-	_, _ = w.Write([]byte("\n"))
+func writePlainTextErrorBody(b *bytes.Buffer, s int, m []byte) {
+	b.WriteString(strconv.Itoa(s))
+	b.WriteString(` `)
+	b.Write(m)
+	b.WriteString("\n")
 }
 
 // jsonError prints an error using general guidelines from
 // https://github.com/omniti-labs/jsend
-func writeJsonErrorBody(w http.ResponseWriter, s int, m []byte) {
-	response := jsonhttperror{Status: "error", Message: string(m), Code: s}
-	json, _ := json.Marshal(response) // No error handling for error handling
+func writeJsonErrorBody(b *bytes.Buffer, s int, m []byte, data *jsonErrorData) {
+	b.Write(appendJsonErrorBody(b.AvailableBuffer(), s, m, data))
+}
+
+// jsonErrorData is the data member of JSON error responses, holding the
+// error code and field errors, if any, so that clients can reconstruct the
+// error (see [httperror.FromResponse]).
+type jsonErrorData struct {
+	Code        string       `json:"code,omitempty"`
+	Errors      []FieldError `json:"errors,omitempty"`
+	Fingerprint string       `json:"fingerprint,omitempty"`
+}
 
-	_, _ = w.Write(json)
-	_, _ = w.Write([]byte("\n"))
+// errorData returns the data member of the JSON error response for e, or
+// nil if e has no error code, field errors, or idempotency fingerprint (see
+// [httperror.IdempotencyFingerprint]).
+func errorData(e error) *jsonErrorData {
+	data := jsonErrorData{Code: ErrorCode(e), Errors: FieldErrors(e), Fingerprint: IdempotencyFingerprint(e)}
+	if data.Code == "" && data.Errors == nil && data.Fingerprint == "" {
+		return nil
+	}
+	return &data
 }
 
-type jsonhttperror struct {
-	Status  string `json:"status"`
-	Message string `json:"message,omitempty"`
-	Code    int    `json:"code,omitempty"`
+// bodyAllowed reports whether a response with status s may have a body.
+func bodyAllowed(s int) bool {
+	return s >= 200 && s != http.StatusNoContent && s != http.StatusNotModified
 }
 
 // responseContentType extracts the content type from the response writer, if
@@ -98,3 +182,25 @@ func responseContentType(w http.ResponseWriter) string {
 	}
 	return contentType
 }
+
+// maxPooledBufferSize is the capacity above which buffers are not returned
+// to the pool, so that a single huge error message doesn't stay in memory.
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool holds the buffers in which error bodies are assembled, to
+// avoid allocating them for every error.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBufferSize {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
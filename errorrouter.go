@@ -0,0 +1,98 @@
+package httperror
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrorHandlerRouter selects an error handler based on the request host or
+// path prefix, so that for example errors under /api/ are rendered as JSON
+// problem details and everything else as HTML pages:
+//
+//	router := httperror.NewErrorHandlerRouter(htmlErrorHandler).
+//		PathPrefix("/api/", httperror.ProblemErrorHandler).
+//		Host("api.example.com", httperror.ProblemErrorHandler)
+//
+//	http.ListenAndServe(":8080", router.Wrap(httperror.MuxHandler(mux)))
+//
+// Since an [httperror.ErrorHandler] doesn't receive the request, the router
+// is used through its Wrap method, or as a request-aware error handler
+// through its HandleError method (see [httperror.WithRequestErrorHandler]).
+type ErrorHandlerRouter struct {
+	fallback ErrorHandler
+	routes   []errorRoute
+}
+
+type errorRoute struct {
+	host   string
+	prefix string
+	eh     ErrorHandler
+}
+
+// NewErrorHandlerRouter returns an [httperror.ErrorHandlerRouter] that uses
+// fallback for requests that match no route. If fallback is nil,
+// [httperror.DefaultErrorHandler] is used.
+func NewErrorHandlerRouter(fallback ErrorHandler) *ErrorHandlerRouter {
+	if fallback == nil {
+		fallback = DefaultErrorHandler
+	}
+	return &ErrorHandlerRouter{fallback: fallback}
+}
+
+// Host routes errors for requests to host (compared case-insensitively,
+// ignoring any port) to eh, and returns the router.
+func (rt *ErrorHandlerRouter) Host(host string, eh ErrorHandler) *ErrorHandlerRouter {
+	rt.routes = append(rt.routes, errorRoute{host: strings.ToLower(host), eh: eh})
+	return rt
+}
+
+// PathPrefix routes errors for requests whose URL path starts with prefix
+// to eh, and returns the router.
+func (rt *ErrorHandlerRouter) PathPrefix(prefix string, eh ErrorHandler) *ErrorHandlerRouter {
+	rt.routes = append(rt.routes, errorRoute{prefix: prefix, eh: eh})
+	return rt
+}
+
+// ErrorHandler returns the error handler for r: the handler of the first
+// route, in the order they were added, that matches r, or the fallback.
+func (rt *ErrorHandlerRouter) ErrorHandler(r *http.Request) ErrorHandler {
+	host := requestHost(r)
+	for _, route := range rt.routes {
+		if route.host != "" && route.host == host {
+			return route.eh
+		}
+		if route.host == "" && strings.HasPrefix(r.URL.Path, route.prefix) {
+			return route.eh
+		}
+	}
+	return rt.fallback
+}
+
+// HandleError handles err with the error handler for r. It is an
+// [httperror.RequestErrorHandler].
+func (rt *ErrorHandlerRouter) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	rt.ErrorHandler(r)(w, err)
+}
+
+// Wrap wraps h, handling the errors it returns with the error handler for
+// the request (see [httperror.WithErrorHandler]).
+func (rt *ErrorHandlerRouter) Wrap(h Handler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err := h.Serve(w, r)
+		ReportError(r.Context(), r, err)
+		if err != nil && !ResponseWritten(err) {
+			rt.HandleError(w, r, err)
+		}
+		return nil
+	})
+}
+
+// requestHost returns the lower-cased request host, without the port.
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
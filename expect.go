@@ -0,0 +1,79 @@
+package httperror
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ExpectContinueConfig configures [httperror.ExpectContinueMiddleware].
+// Zero values disable the corresponding checks.
+type ExpectContinueConfig struct {
+	// MaxBytes is the maximum Content-Length of the request.
+	MaxBytes int64
+
+	// ContentTypes are the accepted media types of the request body, such
+	// as "application/json".
+	ContentTypes []string
+
+	// Check is called with the request before its body is read, for
+	// example to check authentication. An error is returned as is.
+	Check func(r *http.Request) error
+}
+
+// ExpectContinueMiddleware returns an [httperror.Middleware] that evaluates
+// the preconditions of requests with an "Expect: 100-continue" header
+// before their body is read. Go's HTTP server sends the 100 Continue
+// response when the handler first reads the body, so returning an error
+// here rejects an upload before the client sends it:
+//
+//   - RequestEntityTooLarge, with a public message, if the Content-Length
+//     exceeds MaxBytes
+//   - UnsupportedMediaType, with a public message, if the Content-Type is
+//     not one of ContentTypes
+//   - the error returned by Check
+//
+// Requests with any other expectation return ExpectationFailed. Requests
+// without an Expect header are passed through unchanged.
+func ExpectContinueMiddleware(cfg ExpectContinueConfig) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			expect := r.Header.Get("Expect")
+			if expect == "" {
+				return h.Serve(w, r)
+			}
+			if !strings.EqualFold(expect, "100-continue") {
+				return ExpectationFailed
+			}
+
+			if cfg.MaxBytes > 0 && r.ContentLength > cfg.MaxBytes {
+				return PublicErrorf(http.StatusRequestEntityTooLarge, "request body larger than %d bytes", cfg.MaxBytes)
+			}
+			if len(cfg.ContentTypes) > 0 && !hasMediaType(r.Header.Get("Content-Type"), cfg.ContentTypes) {
+				return PublicErrorf(http.StatusUnsupportedMediaType, "Content-Type must be one of %s", strings.Join(cfg.ContentTypes, ", "))
+			}
+			if cfg.Check != nil {
+				if err := cfg.Check(r); err != nil {
+					return err
+				}
+			}
+
+			return h.Serve(w, r)
+		})
+	}
+}
+
+// hasMediaType reports whether the media type of a Content-Type header
+// value is one of mediaTypes.
+func hasMediaType(contentType string, mediaTypes []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range mediaTypes {
+		if strings.EqualFold(mediaType, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package httperror_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectContinueMiddleware(t *testing.T) {
+	var bodyRead bool
+	h := httperror.ExpectContinueMiddleware(httperror.ExpectContinueConfig{
+		MaxBytes:     10,
+		ContentTypes: []string{"application/json"},
+		Check: func(r *http.Request) error {
+			if r.Header.Get("Authorization") == "" {
+				return httperror.Unauthorized
+			}
+			return nil
+		},
+	})(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		_, err := io.ReadAll(r.Body)
+		bodyRead = true
+		return err
+	}))
+
+	serve := func(expect, contentType, authorization, body string) error {
+		bodyRead = false
+		r := httptest.NewRequest("PUT", "/upload", strings.NewReader(body))
+		r.Header.Set("Expect", expect)
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set("Authorization", authorization)
+		return h.Serve(httptest.NewRecorder(), r)
+	}
+
+	assert.NoError(t, serve("100-continue", "application/json", "Bearer x", "{}"))
+	assert.True(t, bodyRead)
+
+	err := serve("100-continue", "application/json", "Bearer x", `{"name":"widget"}`)
+	assert.Equal(t, 413, httperror.StatusCode(err))
+	assert.False(t, bodyRead)
+
+	err = serve("100-continue", "text/plain", "Bearer x", "{}")
+	assert.Equal(t, 415, httperror.StatusCode(err))
+	assert.Equal(t, "Content-Type must be one of application/json", httperror.PublicMessage(err))
+
+	assert.Equal(t, httperror.Unauthorized, serve("100-Continue", "application/json", "", "{}"))
+	assert.Equal(t, httperror.ExpectationFailed, serve("something-else", "application/json", "Bearer x", "{}"))
+
+	assert.NoError(t, serve("", "text/plain", "", "unchecked body"))
+	assert.True(t, bodyRead)
+}
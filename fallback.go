@@ -0,0 +1,52 @@
+package httperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Fallback returns a [httperror.Handler] that tries the handlers in order,
+// moving on to the next handler only when the previous one returns NotFound.
+// This is useful for layouts such as "API route, else static file, else SPA
+// index". See [httperror.FallbackIf] for details.
+func Fallback(hs ...Handler) Handler {
+	return FallbackIf(func(err error) bool {
+		return errors.Is(err, NotFound)
+	}, hs...)
+}
+
+// FallbackIf is like [httperror.Fallback], but moves on to the next handler
+// whenever next returns true for the error returned by the previous one.
+//
+// A handler that has already written to the response can't be fallen back
+// from, so its error is returned. Response headers set by a handler that is
+// fallen back from are discarded. The error returned by the last handler is
+// returned as is. With no handlers, NotFound is returned.
+func FallbackIf(next func(error) bool, hs ...Handler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if len(hs) == 0 {
+			return NotFound
+		}
+
+		header := w.Header()
+		saved := header.Clone()
+
+		var err error
+		for i, h := range hs {
+			rw := NewResponseWriter(w)
+
+			err = h.Serve(rw, r)
+			if err == nil || i == len(hs)-1 || rw.Written() || !next(err) {
+				return err
+			}
+
+			for k := range header {
+				delete(header, k)
+			}
+			for k, vs := range saved {
+				header[k] = vs
+			}
+		}
+		return err
+	})
+}
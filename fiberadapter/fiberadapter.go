@@ -0,0 +1,133 @@
+/*
+Package fiberadapter translates between httperror values and the error
+handling of Fiber (github.com/gofiber/fiber/v2), so that error responses
+are consistent across stacks that mix net/http and Fiber handlers. See the
+documentation of the parent package at https://github.com/johnwarden/httperror
+*/
+package fiberadapter
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/johnwarden/httperror"
+)
+
+// ToFiber converts an error into a *fiber.Error with the status code of the
+// error (see [httperror.StatusCode]) and its public message (see
+// [httperror.PublicMessage]), or the status text if it has none, so that
+// Fiber's default error handler doesn't expose private error messages.
+func ToFiber(err error) *fiber.Error {
+	if err == nil {
+		return nil
+	}
+
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fe
+	}
+
+	s := httperror.StatusCode(err)
+	m := httperror.PublicMessage(err)
+	if m == "" {
+		m = http.StatusText(s)
+	}
+	return fiber.NewError(s, m)
+}
+
+// FromFiber converts an error returned by a Fiber handler into an
+// httperror: *fiber.Error values (such as fiber.ErrNotFound) are wrapped
+// with their status code, and other errors are returned unchanged.
+func FromFiber(err error) error {
+	var fe *fiber.Error
+	if errors.As(err, &fe) && httperror.StatusCode(err) == http.StatusInternalServerError {
+		return httperror.Wrap(err, fe.Code)
+	}
+	return err
+}
+
+// Handler converts an [httperror.Handler] into a fiber.Handler. Errors
+// returned by h are returned to Fiber, to be handled by the app's error
+// handler (see [ErrorHandler]).
+func Handler(h httperror.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var err error
+		herr := adaptor.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err = h.Serve(w, r)
+		})(c)
+		if herr != nil {
+			return herr
+		}
+		return err
+	}
+}
+
+// ErrorHandler returns a fiber.ErrorHandler that renders errors returned
+// by Fiber handlers, including Fiber's own errors (see [FromFiber]), with
+// eh, so that error bodies are the same as those of net/http handlers.
+// Errors are passed to the registered reporters first (see
+// [httperror.ReportError]). If
+// eh is nil, [httperror.DefaultErrorHandler] is used. The format is
+// negotiated from the Accept header of the request: JSON, HTML, or plain
+// text.
+//
+//	app := fiber.New(fiber.Config{ErrorHandler: fiberadapter.ErrorHandler(nil)})
+func ErrorHandler(eh httperror.ErrorHandler) fiber.ErrorHandler {
+	if eh == nil {
+		eh = httperror.DefaultErrorHandler
+	}
+
+	return func(c *fiber.Ctx, err error) error {
+		err = FromFiber(err)
+		if r, cerr := adaptor.ConvertRequest(c, true); cerr == nil {
+			httperror.ReportError(c.UserContext(), r, err)
+		}
+		if httperror.ResponseWritten(err) {
+			return nil
+		}
+
+		w := &responseWriter{c: c, header: make(http.Header)}
+		if contentType := c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML, fiber.MIMETextPlain); contentType != "" {
+			w.header.Set("Content-Type", contentType)
+		}
+
+		eh(w, err)
+		return nil
+	}
+}
+
+// responseWriter is an http.ResponseWriter that writes to a Fiber
+// response.
+type responseWriter struct {
+	c           *fiber.Ctx
+	header      http.Header
+	wroteHeader bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(s int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	for k, vs := range w.header {
+		w.c.Response().Header.Del(k)
+		for _, v := range vs {
+			w.c.Response().Header.Add(k, v)
+		}
+	}
+	w.c.Status(s)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.c.Write(b)
+}
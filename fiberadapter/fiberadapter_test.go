@@ -0,0 +1,99 @@
+package fiberadapter_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/fiberadapter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRequest(t *testing.T, app *fiber.App, path, accept string) (int, string) {
+	r := httptest.NewRequest("GET", path, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	res, err := app.Test(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: fiberadapter.ErrorHandler(nil)})
+	app.Get("/hello", fiberadapter.Handler(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			return httperror.NewPublic(http.StatusBadRequest, "missing 'name' parameter")
+		}
+		_, _ = w.Write([]byte("hello " + name))
+		return nil
+	})))
+	app.Get("/fiber", func(c *fiber.Ctx) error {
+		return fiber.ErrTeapot
+	})
+
+	{
+		s, m := testRequest(t, app, "/hello?name=bill", "")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "hello bill", m)
+	}
+
+	{
+		s, m := testRequest(t, app, "/hello", "application/json")
+		assert.Equal(t, 400, s)
+		assert.Equal(t, `{"status":"error","message":"Bad Request: missing 'name' parameter","code":400}`+"\n", m, "errors are rendered by the httperror error handler")
+	}
+
+	{
+		s, m := testRequest(t, app, "/hello", "text/plain")
+		assert.Equal(t, 400, s)
+		assert.Equal(t, "400 Bad Request: missing 'name' parameter\n", m, "format is negotiated from the Accept header")
+	}
+
+	{
+		s, m := testRequest(t, app, "/fiber", "text/plain")
+		assert.Equal(t, 418, s)
+		assert.Equal(t, "418 I'm a teapot\n", m, "fiber errors are rendered too")
+	}
+
+	{
+		s, _ := testRequest(t, app, "/missing", "text/plain")
+		assert.Equal(t, 404, s)
+	}
+}
+
+func TestToFiber(t *testing.T) {
+	assert.Nil(t, fiberadapter.ToFiber(nil))
+
+	{
+		fe := fiberadapter.ToFiber(httperror.NewPublic(http.StatusConflict, "already exists"))
+		assert.Equal(t, 409, fe.Code)
+		assert.Equal(t, "already exists", fe.Message)
+	}
+
+	{
+		fe := fiberadapter.ToFiber(errors.New("secret database details"))
+		assert.Equal(t, 500, fe.Code)
+		assert.Equal(t, "Internal Server Error", fe.Message, "private messages are not exposed")
+	}
+
+	assert.Equal(t, fiber.ErrNotFound, fiberadapter.ToFiber(fiber.ErrNotFound))
+}
+
+func TestFromFiber(t *testing.T) {
+	err := fiberadapter.FromFiber(fiber.ErrNotFound)
+	assert.True(t, errors.Is(err, httperror.NotFound))
+	assert.True(t, errors.Is(err, fiber.ErrNotFound))
+
+	other := errors.New("other")
+	assert.Equal(t, other, fiberadapter.FromFiber(other))
+}
@@ -0,0 +1,234 @@
+package httperror
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileServer returns a [httperror.Handler] that serves files from fsys,
+// like [http.FileServer], but returns errors instead of writing the
+// standard library's error pages, so that static assets get the same error
+// responses and logging as other routes. Missing files return NotFound,
+// files that can't be read due to permissions return Forbidden, and other
+// failures are wrapped as InternalServerError. Conditional and range
+// requests return NotModified, PreconditionFailed or
+// RequestedRangeNotSatisfiable (see [httperror.CheckPreconditions] and
+// [httperror.RangeNotSatisfiable]). Directories are served using
+// their index.html file. Directory listings are not supported, so
+// directories without an index.html return NotFound.
+//
+// Use [http.StripPrefix] with [httperror.ApplyStandardMiddleware] to serve
+// a subtree:
+//
+//	h := httperror.ApplyStandardMiddleware(httperror.FileServer(os.DirFS("static")), func(h http.Handler) http.Handler {
+//		return http.StripPrefix("/static/", h)
+//	})
+func FileServer(fsys fs.FS) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		p := r.URL.Path
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+
+		name := strings.TrimPrefix(path.Clean(p), "/")
+		if name == "" {
+			name = "."
+		}
+
+		return serveFile(w, r, fsys, name, strings.HasSuffix(p, "/"))
+	})
+}
+
+// SPAHandler returns a [httperror.Handler] for a single-page application:
+// it serves files from fsys like [httperror.FileServer], but GET and HEAD
+// requests for missing paths without a file extension (client-side routes
+// such as /orders/42) are served the index file instead, e.g. "index.html".
+// Missing paths with an extension (assets such as /app.js) still return
+// NotFound, so that broken asset links are not masked by the index page.
+func SPAHandler(fsys fs.FS, index string) Handler {
+	files := FileServer(fsys)
+
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err := files.Serve(w, r)
+		if !errors.Is(err, NotFound) || path.Ext(r.URL.Path) != "" {
+			return err
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			return err
+		}
+		return ServeFile(w, r, fsys, index)
+	})
+}
+
+// ServeFile serves the named file from fsys, like [http.ServeFile], but
+// returns errors instead of writing the standard library's error pages. See
+// [httperror.FileServer].
+func ServeFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) error {
+	return serveFile(w, r, fsys, name, true)
+}
+
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, trailingSlash bool) error {
+	if !fs.ValidPath(name) {
+		return NotFound
+	}
+
+	f, info, err := openFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if info.IsDir() {
+		if !trailingSlash {
+			return Redirect(w, r, path.Base(r.URL.Path)+"/", http.StatusMovedPermanently)
+		}
+
+		index, indexInfo, err := openFile(fsys, path.Join(name, "index.html"))
+		if err != nil {
+			return err
+		}
+		defer index.Close()
+
+		if indexInfo.IsDir() {
+			return NotFound
+		}
+		f, info = index, indexInfo
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return fileError(err)
+		}
+		content = bytes.NewReader(b)
+	}
+
+	// Evaluate preconditions and ranges first, so that ServeContent doesn't
+	// write its own 412 and 416 pages.
+	etag := w.Header().Get("Etag")
+	if err := CheckPreconditions(r, etag, info.ModTime()); err != nil {
+		return err
+	}
+	if !rangeSatisfiable(r, etag, info.ModTime(), info.Size()) {
+		return RangeNotSatisfiable(info.Size())
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+	return nil
+}
+
+// rangeSatisfiable reports whether http.ServeContent would serve the Range
+// header of r, if any, for content of the given size, rather than respond
+// with RequestedRangeNotSatisfiable. A Range header is ignored if the
+// If-Range precondition doesn't hold.
+func rangeSatisfiable(r *http.Request, etag string, modtime time.Time, size int64) bool {
+	spec := r.Header.Get("Range")
+	if spec == "" || !ifRangeMatches(r, etag, modtime) {
+		return true
+	}
+
+	spec, ok := strings.CutPrefix(spec, "bytes=")
+	if !ok {
+		return false
+	}
+
+	// Mirror the parsing of http.ServeContent, which only responds with
+	// RequestedRangeNotSatisfiable if the header is invalid or none of the
+	// ranges overlap the content.
+	ranges, noOverlap := 0, false
+	for _, ra := range strings.Split(spec, ",") {
+		ra = textproto.TrimString(ra)
+		if ra == "" {
+			continue
+		}
+		start, end, ok := strings.Cut(ra, "-")
+		if !ok {
+			return false
+		}
+		start, end = textproto.TrimString(start), textproto.TrimString(end)
+
+		if start == "" {
+			// A suffix range, e.g. "-500".
+			if end == "" || end[0] == '-' {
+				return false
+			}
+			if n, err := strconv.ParseInt(end, 10, 64); err != nil || n < 0 {
+				return false
+			}
+			ranges++
+			continue
+		}
+
+		i, err := strconv.ParseInt(start, 10, 64)
+		if err != nil || i < 0 {
+			return false
+		}
+		if i >= size {
+			noOverlap = true
+			continue
+		}
+		if end != "" {
+			if j, err := strconv.ParseInt(end, 10, 64); err != nil || i > j {
+				return false
+			}
+		}
+		ranges++
+	}
+
+	return ranges > 0 || !noOverlap
+}
+
+// ifRangeMatches reports whether the If-Range precondition of r, if any,
+// holds, in which case the Range header applies.
+func ifRangeMatches(r *http.Request, etag string, modtime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return true
+	}
+
+	ir := r.Header.Get("If-Range")
+	if ir == "" {
+		return true
+	}
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, `W/"`) {
+		return etag != "" && etagsMatch(ir, etag, true)
+	}
+
+	t, err := http.ParseTime(ir)
+	return err == nil && !modtime.IsZero() && t.Unix() == modtime.Unix()
+}
+
+func openFile(fsys fs.FS, name string) (fs.File, fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, fileError(err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fileError(err)
+	}
+
+	return f, info, nil
+}
+
+// fileError converts an error from opening or reading a file into an error
+// with an appropriate status code.
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Wrap(err, http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		return Wrap(err, http.StatusForbidden)
+	}
+	return Wrap(err, http.StatusInternalServerError)
+}
@@ -0,0 +1,172 @@
+package httperror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripeErrorHandler(t *testing.T) {
+	{
+		h := httperror.WrapHandlerFunc(notFoundHandler, httperror.StripeErrorHandler)
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, `{"error":{"type":"invalid_request_error","message":"Not Found"}}`+"\n", m)
+	}
+
+	{
+		h := httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.WithCode(httperror.NewPublic(http.StatusPaymentRequired, "Your card was declined."), "card_declined")
+		}, httperror.StripeErrorHandler)
+
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 402, s)
+		assert.Equal(t, `{"error":{"type":"card_error","code":"card_declined","message":"Your card was declined."}}`+"\n", m)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	e := httperror.WithCode(httperror.NotFound, "resource_missing")
+	assert.Equal(t, "resource_missing", httperror.ErrorCode(e))
+	assert.Equal(t, http.StatusNotFound, httperror.StatusCode(e))
+	assert.Equal(t, "404 Not Found", e.Error())
+	assert.Equal(t, "", httperror.ErrorCode(httperror.NotFound))
+	assert.Equal(t, "", httperror.ErrorCode(nil))
+}
+
+func TestGitHubErrorHandler(t *testing.T) {
+	h := httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NewValidationError(httperror.FieldError{Resource: "Issue", Field: "title", Code: "missing_field"})
+	}, httperror.GitHubErrorHandler)
+
+	s, m := testRequest(h, "/")
+	assert.Equal(t, 422, s)
+	assert.Equal(t, `{"message":"Unprocessable Entity","errors":[{"resource":"Issue","field":"title","code":"missing_field"}]}`+"\n", m)
+}
+
+func TestValidationError(t *testing.T) {
+	e := httperror.NewValidationError(
+		httperror.FieldError{Field: "title", Code: "missing_field"},
+		httperror.FieldError{Field: "body", Message: "is too long"},
+	)
+
+	assert.Equal(t, "422 Unprocessable Entity: title: missing_field, body: is too long", e.Error())
+	assert.True(t, errors.Is(e, httperror.UnprocessableEntity))
+	assert.Len(t, httperror.FieldErrors(e), 2)
+	assert.Nil(t, httperror.FieldErrors(httperror.BadRequest))
+}
+
+func TestGoogleErrorHandler(t *testing.T) {
+	{
+		h := httperror.WrapHandlerFunc(notFoundHandler, httperror.GoogleErrorHandler)
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, `{"error":{"code":404,"message":"Not Found","status":"NOT_FOUND"}}`+"\n", m)
+	}
+
+	{
+		h := httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.NewValidationError(httperror.FieldError{Field: "part", Code: "required", Message: "Required parameter: part"})
+		}, httperror.GoogleErrorHandler)
+
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 422, s)
+		assert.Equal(t, `{"error":{"code":422,"message":"Unprocessable Entity","status":"INVALID_ARGUMENT","errors":[{"domain":"global","reason":"required","message":"Required parameter: part","location":"part","locationType":"parameter"}]}}`+"\n", m)
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	assert.Equal(t, 0, httperror.GRPCCode(nil))
+	assert.Equal(t, "NOT_FOUND", httperror.GRPCCodeName(httperror.GRPCCode(httperror.NotFound)))
+	assert.Equal(t, "RESOURCE_EXHAUSTED", httperror.GRPCCodeName(httperror.GRPCCode(httperror.TooManyRequests)))
+	assert.Equal(t, "INTERNAL", httperror.GRPCCodeName(httperror.GRPCCode(errors.New("oops"))))
+	assert.Equal(t, "UNKNOWN", httperror.GRPCCodeName(99))
+
+	assert.Equal(t, http.StatusNotFound, httperror.StatusFromGRPCCode(5))
+	assert.Equal(t, http.StatusServiceUnavailable, httperror.StatusFromGRPCCode(14))
+	assert.Equal(t, http.StatusInternalServerError, httperror.StatusFromGRPCCode(99))
+}
+
+func TestS3ErrorHandler(t *testing.T) {
+	{
+		h := httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("x-amz-request-id", "4442587FB7D0A2F9")
+			return httperror.NotFound
+		}, httperror.S3ErrorHandler)
+
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<Error><Code>NoSuchKey</Code><Message>Not Found</Message><RequestId>4442587FB7D0A2F9</RequestId></Error>`+"\n", m)
+	}
+
+	{
+		h := httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.WithCode(httperror.NewPublic(http.StatusNotFound, "The specified bucket does not exist"), "NoSuchBucket")
+		}, httperror.S3ErrorHandler)
+
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 404, s)
+		assert.Contains(t, m, `<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`)
+	}
+}
+
+func TestKubernetesErrorHandler(t *testing.T) {
+	{
+		h := httperror.WrapHandlerFunc(notFoundHandler, httperror.KubernetesErrorHandler)
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"Not Found","reason":"NotFound","code":404}`+"\n", m)
+	}
+
+	{
+		h := httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.NewValidationError(httperror.FieldError{Field: "spec.replicas", Code: "FieldValueInvalid", Message: "must be greater than or equal to 0"})
+		}, httperror.KubernetesErrorHandler)
+
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 422, s)
+		assert.Equal(t, `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"Unprocessable Entity","reason":"Invalid","details":{"causes":[{"reason":"FieldValueInvalid","message":"must be greater than or equal to 0","field":"spec.replicas"}]},"code":422}`+"\n", m)
+	}
+}
+
+func TestJSONRPCError(t *testing.T) {
+	{
+		e := httperror.ToJSONRPCError(httperror.WithCode(httperror.NewPublic(http.StatusBadRequest, "missing 'name' parameter"), "missing_param"))
+		assert.Equal(t, httperror.JSONRPCInvalidParams, e.Code)
+		assert.Equal(t, "missing 'name' parameter", e.Message)
+
+		b, _ := json.Marshal(e)
+		var decoded httperror.JSONRPCError
+		_ = json.Unmarshal(b, &decoded)
+
+		err := httperror.FromJSONRPCError(decoded)
+		assert.True(t, errors.Is(err, httperror.BadRequest))
+		assert.Equal(t, "missing 'name' parameter", httperror.PublicMessage(err))
+		assert.Equal(t, "missing_param", httperror.ErrorCode(err))
+	}
+
+	{
+		err := httperror.FromJSONRPCError(httperror.JSONRPCError{Code: httperror.JSONRPCMethodNotFound, Message: "Method not found"})
+		assert.True(t, errors.Is(err, httperror.NotImplemented))
+	}
+
+	assert.Equal(t, httperror.JSONRPCMethodNotFound, httperror.ToJSONRPCError(httperror.NotImplemented).Code)
+	assert.Equal(t, httperror.JSONRPCServerError, httperror.ToJSONRPCError(httperror.NotFound).Code, "missing resource is not a missing method")
+	assert.Equal(t, httperror.JSONRPCServerError, httperror.ToJSONRPCError(httperror.MethodNotAllowed).Code)
+
+	{
+		h := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			httperror.WriteJSONRPCError(w, json.RawMessage(`1`), httperror.InternalServerError)
+			return nil
+		})
+
+		s, m := testRequest(h, "/")
+		assert.Equal(t, 500, s)
+		assert.Equal(t, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal Server Error","data":{"status":500}},"id":1}`+"\n", m)
+	}
+}
@@ -0,0 +1,88 @@
+package httperror
+
+import (
+	"io"
+	"net/http"
+)
+
+// FromStandard adapts a standard [http.Handler] into an [httperror.Handler],
+// so that error middleware, logging, and error handlers apply to legacy
+// handlers. If h writes an error status (400 or above) without writing a
+// body, the status is not written to the response. Instead, Serve returns
+// an error with that status code, and any headers h set (for example Allow
+// or WWW-Authenticate) are left in place for the error handler. If h writes
+// a body after the error status, h has rendered its own error response, so
+// it is passed through and Serve returns nil.
+func FromStandard(h http.Handler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		sw := &sniffingWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+
+		if sw.status != 0 && !sw.written {
+			return httpError{sw.status}
+		}
+		return nil
+	})
+}
+
+// sniffingWriter is an http.ResponseWriter that holds back error statuses
+// until the body is written, so that [httperror.FromStandard] can turn
+// bodiless error responses into errors.
+type sniffingWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (sw *sniffingWriter) WriteHeader(s int) {
+	if sw.written || sw.status != 0 {
+		return
+	}
+	if s >= 400 {
+		sw.status = s
+		return
+	}
+	if s >= 100 && s < 200 {
+		// Informational responses, such as 103 Early Hints, precede the
+		// final status.
+		sw.ResponseWriter.WriteHeader(s)
+		return
+	}
+	sw.written = true
+	sw.ResponseWriter.WriteHeader(s)
+}
+
+func (sw *sniffingWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 && sw.status != 0 && !sw.written {
+		return 0, nil
+	}
+	sw.commit()
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *sniffingWriter) Flush() {
+	sw.commit()
+	flush(sw.ResponseWriter)
+}
+
+func (sw *sniffingWriter) ReadFrom(src io.Reader) (int64, error) {
+	sw.commit()
+	return readFrom(sw.ResponseWriter, src)
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// [http.ResponseController].
+func (sw *sniffingWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// commit writes the held-back status, if any.
+func (sw *sniffingWriter) commit() {
+	if sw.written {
+		return
+	}
+	sw.written = true
+	if sw.status != 0 {
+		sw.ResponseWriter.WriteHeader(sw.status)
+	}
+}
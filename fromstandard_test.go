@@ -0,0 +1,66 @@
+package httperror_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromStandard(t *testing.T) {
+	serve := func(h http.Handler) (*httptest.ResponseRecorder, error) {
+		rr := httptest.NewRecorder()
+		err := httperror.FromStandard(h).Serve(rr, httptest.NewRequest("GET", "/", nil))
+		return rr, err
+	}
+
+	methodNotAllowed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", "POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	{
+		rr, err := serve(methodNotAllowed)
+		assert.Equal(t, httperror.MethodNotAllowed, err, "bodiless error status is returned as an error")
+		assert.Equal(t, 0, rr.Body.Len())
+		assert.Equal(t, "POST", rr.Header().Get("Allow"))
+	}
+
+	{
+		var e error
+		h := httperror.WrapHandlerFunc(httperror.FromStandard(methodNotAllowed).Serve, func(w http.ResponseWriter, err error) {
+			e = err
+			httperror.DefaultErrorHandler(w, err)
+		})
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 405, s)
+		assert.Equal(t, httperror.MethodNotAllowed, e, "error handler sees the error")
+	}
+
+	{
+		rr, err := serve(http.NotFoundHandler())
+		assert.Nil(t, err, "handler rendered its own error response")
+		assert.Equal(t, 404, rr.Code)
+		assert.Equal(t, "404 page not found\n", rr.Body.String())
+	}
+
+	{
+		rr, err := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("hello"))
+		}))
+		assert.Nil(t, err)
+		assert.Equal(t, 200, rr.Code)
+		assert.Equal(t, "hello", rr.Body.String())
+	}
+	{
+		_, err := serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Link", "</style.css>; rel=preload")
+			w.WriteHeader(http.StatusEarlyHints)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		assert.Equal(t, httperror.NotFound, err, "the final status follows 103 Early Hints")
+	}
+}
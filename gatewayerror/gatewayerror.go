@@ -0,0 +1,45 @@
+/*
+Package gatewayerror renders errors from grpc-gateway
+(github.com/grpc-ecosystem/grpc-gateway/v2) with the error handlers of this
+package, so that gateway and native HTTP endpoints return identical error
+bodies. See the documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package gatewayerror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/grpcerror"
+)
+
+// ErrorHandler returns a runtime.ErrorHandlerFunc that converts gRPC errors
+// returned by upstream services into httperrors (see
+// [grpcerror.FromError]), and renders them with eh. If eh is nil,
+// [httperror.ProblemErrorHandler] is used. To render errors registered in
+// a catalog, pass the catalog's error handler:
+//
+//	mux := runtime.NewServeMux(runtime.WithErrorHandler(gatewayerror.ErrorHandler(catalog.ProblemErrorHandler)))
+//
+// The status code of a runtime.HTTPStatusError, used by the gateway for
+// routing errors such as unknown paths, takes precedence over the gRPC
+// code.
+func ErrorHandler(eh httperror.ErrorHandler) runtime.ErrorHandlerFunc {
+	if eh == nil {
+		eh = httperror.ProblemErrorHandler
+	}
+
+	return func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		var statusErr *runtime.HTTPStatusError
+		if errors.As(err, &statusErr) {
+			eh(w, httperror.Wrap(grpcerror.FromError(statusErr.Err), statusErr.HTTPStatus))
+			return
+		}
+
+		eh(w, grpcerror.FromError(err))
+	}
+}
@@ -0,0 +1,57 @@
+package gatewayerror_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/gatewayerror"
+	"github.com/johnwarden/httperror/grpcerror"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorHandler(t *testing.T) {
+	catalog := httperror.NewCatalog("https://example.com/problems/")
+	catalog.Register(httperror.ProblemType{Code: "out_of_credit", Status: 403, Title: "You do not have enough credit."})
+
+	eh := gatewayerror.ErrorHandler(catalog.ProblemErrorHandler)
+
+	serve := func(err error) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		eh(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, rr, httptest.NewRequest("GET", "/", nil), err)
+		return rr
+	}
+
+	{
+		rr := serve(grpcerror.Error(httperror.WithCode(httperror.NewPublic(403, "Your balance is 30."), "out_of_credit")))
+		assert.Equal(t, 403, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `{"type":"https://example.com/problems/out_of_credit","title":"You do not have enough credit.","status":403,"detail":"Your balance is 30."}`+"\n", rr.Body.String(), "registered problem types are used")
+	}
+
+	{
+		rr := serve(status.Error(codes.Unavailable, "upstream down"))
+		assert.Equal(t, 503, rr.Code)
+		assert.Equal(t, `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"upstream down"}`+"\n", rr.Body.String())
+	}
+
+	{
+		rr := serve(&runtime.HTTPStatusError{HTTPStatus: http.StatusMethodNotAllowed, Err: status.Error(codes.Unimplemented, "Method Not Allowed")})
+		assert.Equal(t, 405, rr.Code, "HTTP status of routing errors takes precedence")
+	}
+}
+
+func TestErrorHandlerDefault(t *testing.T) {
+	mux := runtime.NewServeMux(runtime.WithErrorHandler(gatewayerror.ErrorHandler(nil)))
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/missing", nil))
+	assert.Equal(t, 404, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+}
@@ -0,0 +1,50 @@
+/*
+Package ginadapter runs [httperror.Handler]s as gin handlers
+(github.com/gin-gonic/gin), so that handlers can be migrated to or from gin
+without rewriting their error handling. See the documentation of the parent
+package at https://github.com/johnwarden/httperror
+*/
+package ginadapter
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/johnwarden/httperror"
+)
+
+// Wrap converts an [httperror.Handler] into a gin.HandlerFunc. Errors
+// returned by h are added to the context's error list (c.Errors), as public
+// errors if they have a public message (see [httperror.PublicMessage]) or
+// private errors otherwise, so gin middleware such as loggers see them. The
+// error is then rendered with eh, and the handler chain is aborted. If eh
+// is nil, [httperror.DefaultErrorHandler] is used, which picks the format
+// from the Content-Type set by the handler.
+func Wrap(h httperror.Handler, eh httperror.ErrorHandler) gin.HandlerFunc {
+	if eh == nil {
+		eh = httperror.DefaultErrorHandler
+	}
+
+	return func(c *gin.Context) {
+		err := h.Serve(c.Writer, c.Request)
+		if err == nil {
+			return
+		}
+
+		t := gin.ErrorTypePrivate
+		if httperror.PublicMessage(err) != "" {
+			t = gin.ErrorTypePublic
+		}
+		_ = c.Error(err).SetType(t)
+		httperror.ReportError(c.Request.Context(), c.Request, err)
+
+		if !httperror.ResponseWritten(err) && !c.Writer.Written() {
+			eh(c.Writer, err)
+		}
+		c.Abort()
+	}
+}
+
+// WrapFunc converts an error-returning handler function into a
+// gin.HandlerFunc. See [Wrap].
+func WrapFunc(h httperror.HandlerFunc, eh httperror.ErrorHandler) gin.HandlerFunc {
+	return Wrap(h, eh)
+}
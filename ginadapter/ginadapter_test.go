@@ -0,0 +1,78 @@
+package ginadapter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/ginadapter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestWrap(t *testing.T) {
+	var errs []*gin.Error
+	after := false
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		errs = append(errs, c.Errors...)
+	})
+	r.GET("/hello", ginadapter.WrapFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			return httperror.NewPublic(http.StatusBadRequest, "missing 'name' parameter")
+		}
+		_, _ = w.Write([]byte(`"hello ` + name + `"`))
+		return nil
+	}, nil), func(c *gin.Context) {
+		after = true
+	})
+	r.GET("/teapot", ginadapter.WrapFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.Teapot
+	}, func(w http.ResponseWriter, err error) {
+		w.WriteHeader(httperror.StatusCode(err))
+		_, _ = w.Write([]byte("custom"))
+	}))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	{
+		rr := serve("/hello?name=bill")
+		assert.Equal(t, 200, rr.Code)
+		assert.Equal(t, `"hello bill"`, rr.Body.String())
+		assert.True(t, after, "chain continues after success")
+		assert.Empty(t, errs)
+	}
+
+	after = false
+
+	{
+		rr := serve("/hello")
+		assert.Equal(t, 400, rr.Code)
+		assert.Equal(t, `{"status":"error","message":"Bad Request: missing 'name' parameter","code":400}`+"\n", rr.Body.String(), "rendered in the handler's format")
+		assert.False(t, after, "chain is aborted")
+		assert.Len(t, errs, 1, "error is added to the gin context")
+		assert.True(t, errs[0].IsType(gin.ErrorTypePublic))
+		assert.Equal(t, 400, httperror.StatusCode(errs[0].Err))
+	}
+
+	{
+		rr := serve("/teapot")
+		assert.Equal(t, 418, rr.Code)
+		assert.Equal(t, "custom", rr.Body.String())
+		assert.True(t, errs[1].IsType(gin.ErrorTypePrivate))
+	}
+}
@@ -0,0 +1,57 @@
+package httperror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GitHubErrorHandler is an [httperror.ErrorHandler] that writes a JSON error
+// response in the shape used by the GitHub REST API:
+//
+//	{"message":"Validation Failed","errors":[{"resource":"Issue","field":"title","code":"missing_field"}],"documentation_url":"..."}
+//
+// The message is the public message (see [httperror.PublicMessage]) or the
+// status text if there is none. Field errors (see
+// [httperror.NewValidationError]) are listed in the errors array. If the
+// error has a `DocumentationURL() string` method, its value is used for the
+// documentation_url member.
+func GitHubErrorHandler(w http.ResponseWriter, e error) {
+	s := StatusCode(e)
+
+	body := gitHubError{Message: PublicMessage(e)}
+	if body.Message == "" {
+		body.Message = statusText(s)
+	}
+
+	for _, f := range FieldErrors(e) {
+		body.Errors = append(body.Errors, gitHubFieldError(f))
+	}
+
+	var documentedError interface{ DocumentationURL() string }
+	if errors.As(e, &documentedError) {
+		body.DocumentationURL = documentedError.DocumentationURL()
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	setErrorHeaders(w, e)
+	w.WriteHeader(s)
+
+	json, _ := json.Marshal(body) // No error handling for error handling
+
+	_, _ = w.Write(json)
+	_, _ = w.Write([]byte("\n"))
+}
+
+type gitHubError struct {
+	Message          string             `json:"message"`
+	Errors           []gitHubFieldError `json:"errors,omitempty"`
+	DocumentationURL string             `json:"documentation_url,omitempty"`
+}
+
+type gitHubFieldError struct {
+	Resource string `json:"resource,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
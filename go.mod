@@ -1,6 +1,6 @@
 module github.com/johnwarden/httperror
 
-go 1.19
+go 1.22
 
 require (
 	github.com/pkg/errors v0.9.1
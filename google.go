@@ -0,0 +1,70 @@
+package httperror
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GoogleErrorHandler is an [httperror.ErrorHandler] that writes a JSON error
+// response in the shape used by Google APIs:
+//
+//	{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND","errors":[...]}}
+//
+// The status member is the canonical name of the gRPC status code
+// corresponding to the HTTP status code (see [httperror.GRPCCode]). The
+// message is the public message (see [httperror.PublicMessage]) or the
+// status text if there is none. Field errors (see
+// [httperror.NewValidationError]) are listed in the errors array.
+func GoogleErrorHandler(w http.ResponseWriter, e error) {
+	s := StatusCode(e)
+
+	body := googleErrorBody{
+		Code:    s,
+		Message: PublicMessage(e),
+		Status:  GRPCCodeName(GRPCCode(e)),
+	}
+	if body.Message == "" {
+		body.Message = statusText(s)
+	}
+
+	for _, f := range FieldErrors(e) {
+		item := googleErrorItem{
+			Domain:  "global",
+			Reason:  f.Code,
+			Message: f.Message,
+		}
+		if f.Field != "" {
+			item.Location = f.Field
+			item.LocationType = "parameter"
+		}
+		body.Errors = append(body.Errors, item)
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	setErrorHeaders(w, e)
+	w.WriteHeader(s)
+
+	json, _ := json.Marshal(googleError{body}) // No error handling for error handling
+
+	_, _ = w.Write(json)
+	_, _ = w.Write([]byte("\n"))
+}
+
+type googleError struct {
+	Error googleErrorBody `json:"error"`
+}
+
+type googleErrorBody struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Status  string            `json:"status"`
+	Errors  []googleErrorItem `json:"errors,omitempty"`
+}
+
+type googleErrorItem struct {
+	Domain       string `json:"domain,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Location     string `json:"location,omitempty"`
+	LocationType string `json:"locationType,omitempty"`
+}
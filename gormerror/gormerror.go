@@ -0,0 +1,81 @@
+/*
+Package gormerror converts errors returned by GORM (gorm.io/gorm) into
+httperrors. See the documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package gormerror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"gorm.io/gorm"
+)
+
+// statusClientClosedRequest is the non-standard status used by nginx when
+// the client closes the connection before the response is sent.
+const statusClientClosedRequest = 499
+
+// retryAfter is the delay suggested to clients for retryable errors.
+const retryAfter = time.Second
+
+// mapping describes the httperror for a GORM error.
+type mapping struct {
+	target error
+	status int
+	code   string
+}
+
+var mappings = []mapping{
+	{gorm.ErrRecordNotFound, http.StatusNotFound, "record_not_found"},
+	{gorm.ErrDuplicatedKey, http.StatusConflict, "duplicated_key"},
+	{gorm.ErrForeignKeyViolated, http.StatusConflict, "foreign_key_violation"},
+	{gorm.ErrCheckConstraintViolated, http.StatusUnprocessableEntity, "check_violation"},
+	{context.Canceled, statusClientClosedRequest, ""},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, ""},
+}
+
+// Map converts an error returned by GORM into an httperror:
+//
+//   - gorm.ErrRecordNotFound: 404 Not Found, with the code
+//     "record_not_found"
+//   - gorm.ErrDuplicatedKey: 409 Conflict, with the code "duplicated_key"
+//   - gorm.ErrForeignKeyViolated: 409 Conflict, with the code
+//     "foreign_key_violation"
+//   - gorm.ErrCheckConstraintViolated: 422 Unprocessable Entity, with the
+//     code "check_violation"
+//   - context.Canceled: 499 Client Closed Request
+//   - context.DeadlineExceeded: 504 Gateway Timeout
+//
+// GORM only returns the constraint errors if the TranslateError option is
+// set. Other errors are converted with [httperror.FromSQLError], so that
+// untranslated driver errors are classified by their SQLSTATE code. Errors
+// that are retryable (503 Service Unavailable, such as serialization
+// failures) get a Retry-After header (see [httperror.WithRetryAfter]).
+//
+// The original error remains in the chain. Other errors, and errors that
+// already have a status code, are returned unchanged.
+func Map(err error) error {
+	if err == nil || httperror.StatusCode(err) != http.StatusInternalServerError {
+		return err
+	}
+
+	for _, m := range mappings {
+		if errors.Is(err, m.target) {
+			err = httperror.Wrap(err, m.status)
+			if m.code != "" {
+				err = httperror.WithCode(err, m.code)
+			}
+			return err
+		}
+	}
+
+	err = httperror.FromSQLError(err)
+	if httperror.StatusCode(err) == http.StatusServiceUnavailable {
+		err = httperror.WithRetryAfter(err, retryAfter)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package gormerror_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/gormerror"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{gorm.ErrRecordNotFound, http.StatusNotFound, "record_not_found"},
+		{gorm.ErrDuplicatedKey, http.StatusConflict, "duplicated_key"},
+		{gorm.ErrCheckConstraintViolated, http.StatusUnprocessableEntity, "check_violation"},
+		{context.Canceled, 499, ""},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, ""},
+		{sqlStateError("23505"), http.StatusConflict, ""},
+		{sqlStateError("40001"), http.StatusServiceUnavailable, ""},
+		{driver.ErrBadConn, http.StatusServiceUnavailable, ""},
+	}
+	for _, tt := range tests {
+		err := gormerror.Map(fmt.Errorf("find user: %w", tt.err))
+		assert.Equal(t, tt.status, httperror.StatusCode(err), tt.err.Error())
+		assert.Equal(t, tt.code, httperror.ErrorCode(err), tt.err.Error())
+		assert.True(t, errors.Is(err, tt.err))
+	}
+
+	assert.Equal(t, "1", httperror.Headers(gormerror.Map(sqlStateError("40P01"))).Get("Retry-After"))
+	assert.Empty(t, httperror.Headers(gormerror.Map(gorm.ErrRecordNotFound)))
+
+	other := errors.New("something else")
+	assert.Equal(t, other, gormerror.Map(other))
+	assert.Equal(t, httperror.Forbidden, gormerror.Map(httperror.Forbidden))
+	assert.Nil(t, gormerror.Map(nil))
+}
@@ -0,0 +1,18 @@
+module github.com/johnwarden/httperror/gqlgenerror
+
+go 1.22
+
+require (
+	github.com/99designs/gqlgen v0.17.49
+	github.com/johnwarden/httperror v0.0.0-20261016035624-08a2347946c7
+	github.com/stretchr/testify v1.9.0
+	github.com/vektah/gqlparser/v2 v2.5.16
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sosodev/duration v1.3.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,76 @@
+/*
+Package gqlgenerror presents httperrors returned by gqlgen resolvers
+(github.com/99designs/gqlgen) as GraphQL errors. See the documentation of
+the parent package at https://github.com/johnwarden/httperror
+*/
+package gqlgenerror
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/johnwarden/httperror"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrorPresenter is a gqlgen graphql.ErrorPresenterFunc that presents
+// errors returned by resolvers using their status codes and public
+// messages:
+//
+//   - the GraphQL message is the public message of the error (see
+//     [httperror.PublicMessage]), or the HTTP status text if there is none,
+//     so that internal details are redacted
+//   - extensions.code is the error code (see [httperror.ErrorCode]), or the
+//     name of the gRPC code corresponding to the status code (see
+//     [httperror.GRPCCodeName]), e.g. "NOT_FOUND"
+//   - extensions.http_status is the status code (see
+//     [httperror.StatusCode])
+//
+// GraphQL errors that don't wrap an error, such as query validation
+// errors, are presented as they are.
+//
+//	srv := handler.NewDefaultServer(schema)
+//	srv.SetErrorPresenter(gqlgenerror.ErrorPresenter)
+//	srv.SetRecoverFunc(gqlgenerror.Recover)
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Err == nil {
+		return gqlErr
+	}
+	cause := gqlErr.Err
+
+	s := httperror.StatusCode(cause)
+	m := httperror.PublicMessage(cause)
+	if m == "" {
+		m = http.StatusText(s)
+	}
+	code := httperror.ErrorCode(cause)
+	if code == "" {
+		code = httperror.GRPCCodeName(httperror.GRPCCode(cause))
+	}
+
+	extensions := make(map[string]interface{}, len(gqlErr.Extensions)+2)
+	for k, v := range gqlErr.Extensions {
+		extensions[k] = v
+	}
+	extensions["code"] = code
+	extensions["http_status"] = s
+
+	return &gqlerror.Error{
+		Err:        cause,
+		Message:    m,
+		Path:       gqlErr.Path,
+		Locations:  gqlErr.Locations,
+		Extensions: extensions,
+		Rule:       gqlErr.Rule,
+	}
+}
+
+// Recover is a gqlgen graphql.RecoverFunc that converts a value recovered
+// from a panic in a resolver into an error (see [httperror.PanicError]),
+// which [ErrorPresenter] presents as an internal error without exposing
+// the panic value.
+func Recover(ctx context.Context, recovered interface{}) error {
+	return httperror.PanicError(recovered)
+}
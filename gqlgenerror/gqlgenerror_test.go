@@ -0,0 +1,52 @@
+package gqlgenerror_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/gqlgenerror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestErrorPresenter(t *testing.T) {
+	ctx := context.Background()
+
+	{
+		err := gqlgenerror.ErrorPresenter(ctx, httperror.NewPublic(404, "no such order"))
+		assert.Equal(t, "no such order", err.Message)
+		assert.Equal(t, "NOT_FOUND", err.Extensions["code"])
+		assert.Equal(t, 404, err.Extensions["http_status"])
+	}
+
+	{
+		err := gqlgenerror.ErrorPresenter(ctx, httperror.WithCode(httperror.Forbidden, "account_suspended"))
+		assert.Equal(t, "Forbidden", err.Message)
+		assert.Equal(t, "account_suspended", err.Extensions["code"], "error codes are used")
+	}
+
+	{
+		secret := errors.New("secret database details")
+		err := gqlgenerror.ErrorPresenter(ctx, secret)
+		assert.Equal(t, "Internal Server Error", err.Message, "internal details are redacted")
+		assert.Equal(t, "INTERNAL", err.Extensions["code"])
+		assert.Equal(t, 500, err.Extensions["http_status"])
+		assert.Equal(t, secret, err.Unwrap(), "original error is kept for logging")
+	}
+
+	{
+		validation := gqlerror.Errorf("Cannot query field \"foo\"")
+		assert.Equal(t, validation, gqlgenerror.ErrorPresenter(ctx, validation), "GraphQL errors are unchanged")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	err := gqlgenerror.Recover(context.Background(), "oops")
+	assert.True(t, errors.Is(err, httperror.Panic))
+
+	presented := gqlgenerror.ErrorPresenter(context.Background(), err)
+	assert.Equal(t, "Internal Server Error", presented.Message, "panic values are not exposed")
+}
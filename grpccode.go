@@ -0,0 +1,150 @@
+package httperror
+
+import (
+	"net/http"
+)
+
+// Canonical gRPC status codes, as defined in
+// https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto.
+const (
+	grpcOK                 = 0
+	grpcCanceled           = 1
+	grpcUnknown            = 2
+	grpcInvalidArgument    = 3
+	grpcDeadlineExceeded   = 4
+	grpcNotFound           = 5
+	grpcAlreadyExists      = 6
+	grpcPermissionDenied   = 7
+	grpcResourceExhausted  = 8
+	grpcFailedPrecondition = 9
+	grpcAborted            = 10
+	grpcOutOfRange         = 11
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+	grpcDataLoss           = 15
+	grpcUnauthenticated    = 16
+)
+
+// statusClientClosedRequest is the non-standard 499 status used by nginx
+// and Google APIs for requests canceled by the client.
+const statusClientClosedRequest = 499
+
+// statusText returns the text for an HTTP status code like http.StatusText,
+// including the non-standard statuses used by this package.
+func statusText(s int) string {
+	if s == statusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(s)
+}
+
+var grpcCodeNames = [...]string{
+	grpcOK:                 "OK",
+	grpcCanceled:           "CANCELLED",
+	grpcUnknown:            "UNKNOWN",
+	grpcInvalidArgument:    "INVALID_ARGUMENT",
+	grpcDeadlineExceeded:   "DEADLINE_EXCEEDED",
+	grpcNotFound:           "NOT_FOUND",
+	grpcAlreadyExists:      "ALREADY_EXISTS",
+	grpcPermissionDenied:   "PERMISSION_DENIED",
+	grpcResourceExhausted:  "RESOURCE_EXHAUSTED",
+	grpcFailedPrecondition: "FAILED_PRECONDITION",
+	grpcAborted:            "ABORTED",
+	grpcOutOfRange:         "OUT_OF_RANGE",
+	grpcUnimplemented:      "UNIMPLEMENTED",
+	grpcInternal:           "INTERNAL",
+	grpcUnavailable:        "UNAVAILABLE",
+	grpcDataLoss:           "DATA_LOSS",
+	grpcUnauthenticated:    "UNAUTHENTICATED",
+}
+
+var grpcCodeStatuses = [...]int{
+	grpcOK:                 http.StatusOK,
+	grpcCanceled:           statusClientClosedRequest,
+	grpcUnknown:            http.StatusInternalServerError,
+	grpcInvalidArgument:    http.StatusBadRequest,
+	grpcDeadlineExceeded:   http.StatusGatewayTimeout,
+	grpcNotFound:           http.StatusNotFound,
+	grpcAlreadyExists:      http.StatusConflict,
+	grpcPermissionDenied:   http.StatusForbidden,
+	grpcResourceExhausted:  http.StatusTooManyRequests,
+	grpcFailedPrecondition: http.StatusBadRequest,
+	grpcAborted:            http.StatusConflict,
+	grpcOutOfRange:         http.StatusBadRequest,
+	grpcUnimplemented:      http.StatusNotImplemented,
+	grpcInternal:           http.StatusInternalServerError,
+	grpcUnavailable:        http.StatusServiceUnavailable,
+	grpcDataLoss:           http.StatusInternalServerError,
+	grpcUnauthenticated:    http.StatusUnauthorized,
+}
+
+// GRPCCode returns the canonical gRPC status code corresponding to the HTTP
+// status code of the error (see [httperror.StatusCode]). For example,
+// NotFound maps to 5 (NOT_FOUND) and TooManyRequests to 8
+// (RESOURCE_EXHAUSTED). A nil error maps to 0 (OK). The code is a plain
+// int so that this package does not depend on gRPC; convert it with
+// codes.Code(c) when using google.golang.org/grpc.
+func GRPCCode(err error) int {
+	return grpcCodeFromStatus(StatusCode(err))
+}
+
+func grpcCodeFromStatus(s int) int {
+	switch s {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return grpcInvalidArgument
+	case http.StatusUnauthorized:
+		return grpcUnauthenticated
+	case http.StatusForbidden:
+		return grpcPermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return grpcNotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return grpcUnimplemented
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return grpcDeadlineExceeded
+	case http.StatusConflict:
+		return grpcAborted
+	case http.StatusPreconditionFailed:
+		return grpcFailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return grpcOutOfRange
+	case http.StatusTooManyRequests:
+		return grpcResourceExhausted
+	case statusClientClosedRequest:
+		return grpcCanceled
+	case http.StatusServiceUnavailable:
+		return grpcUnavailable
+	}
+
+	switch {
+	case s < 400:
+		return grpcOK
+	case s < 500:
+		return grpcFailedPrecondition
+	case s < 600:
+		return grpcInternal
+	}
+	return grpcUnknown
+}
+
+// GRPCCodeName returns the canonical name of a gRPC status code, e.g.
+// "NOT_FOUND", as used in the status member of Google API error responses.
+// Returns "UNKNOWN" for codes outside the canonical range.
+func GRPCCodeName(code int) string {
+	if code < 0 || code >= len(grpcCodeNames) {
+		return grpcCodeNames[grpcUnknown]
+	}
+	return grpcCodeNames[code]
+}
+
+// StatusFromGRPCCode returns the HTTP status code corresponding to a
+// canonical gRPC status code, following the mapping documented in
+// google/rpc/code.proto. Returns 500 for codes outside the canonical
+// range.
+func StatusFromGRPCCode(code int) int {
+	if code < 0 || code >= len(grpcCodeStatuses) {
+		return http.StatusInternalServerError
+	}
+	return grpcCodeStatuses[code]
+}
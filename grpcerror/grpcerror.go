@@ -0,0 +1,192 @@
+/*
+Package grpcerror converts httperrors returned by gRPC service methods into
+gRPC status errors (google.golang.org/grpc), so that business logic shared
+by HTTP and gRPC endpoints can return one error type. See the documentation
+of the parent package at https://github.com/johnwarden/httperror
+*/
+package grpcerror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Status converts an error into a gRPC status. The code is the gRPC code
+// corresponding to the HTTP status code of the error (see
+// [httperror.GRPCCode]), and the message is the public message of the
+// error (see [httperror.PublicMessage]), or the HTTP status text if there
+// is none, so that internal details are not sent to clients. The details
+// of the status include:
+//
+//   - an ErrorInfo with the error code as the reason, if the error has one
+//     (see [httperror.ErrorCode])
+//   - a BadRequest with a field violation for each field error (see
+//     [httperror.FieldErrors])
+//   - a RetryInfo, if the error has a Retry-After header in seconds (see
+//     [httperror.WithRetryAfter])
+//
+// Errors that are already gRPC status errors, and context errors without a
+// status code, are converted as by status.FromContextError. A nil error
+// returns nil.
+func Status(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	s := httperror.StatusCode(err)
+	if s == http.StatusInternalServerError && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return status.FromContextError(err)
+	}
+
+	m := httperror.PublicMessage(err)
+	if m == "" {
+		m = http.StatusText(s)
+	}
+	st := status.New(codes.Code(httperror.GRPCCode(err)), m)
+
+	if code := httperror.ErrorCode(err); code != "" {
+		st = withDetail(st, &errdetails.ErrorInfo{Reason: code})
+	}
+
+	if fieldErrors := httperror.FieldErrors(err); len(fieldErrors) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range fieldErrors {
+			description := f.Message
+			if description == "" {
+				description = f.Code
+			}
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: description,
+			})
+		}
+		st = withDetail(st, br)
+	}
+
+	if seconds, convErr := strconv.Atoi(httperror.Headers(err).Get("Retry-After")); convErr == nil {
+		st = withDetail(st, &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(seconds) * time.Second)})
+	}
+
+	return st
+}
+
+// Error converts an error into a gRPC status error. See [Status].
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	return Status(err).Err()
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// converts errors returned by unary service methods with [Error].
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, Error(err)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// converts errors returned by streaming service methods with [Error].
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return Error(handler(srv, ss))
+	}
+}
+
+// withDetail adds a detail to a status, or returns the status unchanged if
+// the detail can't be marshaled.
+func withDetail(st *status.Status, detail protoadapt.MessageV1) *status.Status {
+	if withDetail, err := st.WithDetails(detail); err == nil {
+		return withDetail
+	}
+	return st
+}
+
+// FromError converts a gRPC status error, such as one returned by a gRPC
+// client, into an httperror. Other errors are returned unchanged. See
+// [FromStatus].
+func FromError(err error) error {
+	if st, ok := status.FromError(err); ok && err != nil {
+		return FromStatus(st)
+	}
+	return err
+}
+
+// FromStatus converts a gRPC status into an httperror, reversing [Status]:
+// the HTTP status code corresponds to the gRPC code (see
+// [httperror.StatusFromGRPCCode]), the status message is the public
+// message, and ErrorInfo, BadRequest, and RetryInfo details become the
+// error code, field errors, and Retry-After header of the error. An OK
+// status returns nil.
+func FromStatus(st *status.Status) error {
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	var err error = httperror.Wrap(statusError{st}, httperror.StatusFromGRPCCode(int(st.Code())))
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok && ri.RetryDelay != nil {
+			err = httperror.WithRetryAfter(err, ri.RetryDelay.AsDuration())
+		}
+	}
+	return err
+}
+
+// statusError is an error carrying a gRPC status, with the status message
+// as the public message.
+type statusError struct {
+	st *status.Status
+}
+
+func (e statusError) Error() string {
+	return e.st.Message()
+}
+
+// GRPCStatus returns the status, so that status.FromError returns the
+// original status.
+func (e statusError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+func (e statusError) PublicMessage() string {
+	return e.st.Message()
+}
+
+func (e statusError) ErrorCode() string {
+	for _, d := range e.st.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			return ei.Reason
+		}
+	}
+	return ""
+}
+
+func (e statusError) FieldErrors() []httperror.FieldError {
+	var fieldErrors []httperror.FieldError
+	for _, d := range e.st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				fieldErrors = append(fieldErrors, httperror.FieldError{Field: v.Field, Message: v.Description})
+			}
+		}
+	}
+	return fieldErrors
+}
@@ -0,0 +1,110 @@
+package grpcerror_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/grpcerror"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	assert.Nil(t, grpcerror.Status(nil))
+	assert.Nil(t, grpcerror.Error(nil))
+
+	{
+		st := grpcerror.Status(httperror.NewPublic(404, "no such order"))
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "no such order", st.Message(), "public message is the status message")
+	}
+
+	{
+		st := grpcerror.Status(errors.New("secret database details"))
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Equal(t, "Internal Server Error", st.Message(), "private messages are not exposed")
+	}
+
+	{
+		st := grpcerror.Status(httperror.WithCode(httperror.Forbidden, "account_suspended"))
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+		assert.Len(t, st.Details(), 1)
+		assert.Equal(t, "account_suspended", st.Details()[0].(*errdetails.ErrorInfo).Reason)
+	}
+
+	{
+		st := grpcerror.Status(httperror.NewValidationError(httperror.FieldError{Field: "email", Code: "invalid", Message: "is not an email address"}))
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		br := st.Details()[0].(*errdetails.BadRequest)
+		assert.Equal(t, "email", br.FieldViolations[0].Field)
+		assert.Equal(t, "is not an email address", br.FieldViolations[0].Description)
+	}
+
+	{
+		st := grpcerror.Status(httperror.WithRetryAfter(httperror.TooManyRequests, 30*time.Second))
+		assert.Equal(t, codes.ResourceExhausted, st.Code())
+		assert.Equal(t, 30*time.Second, st.Details()[0].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+	}
+
+	{
+		err := status.Error(codes.Aborted, "already a status")
+		assert.Equal(t, codes.Aborted, grpcerror.Status(err).Code(), "status errors are unchanged")
+	}
+
+	assert.Equal(t, codes.Canceled, grpcerror.Status(context.Canceled).Code())
+	assert.Equal(t, codes.DeadlineExceeded, grpcerror.Status(context.DeadlineExceeded).Code())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := grpcerror.UnaryServerInterceptor()
+
+	resp, err := interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, httperror.NotFound
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err = interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	assert.Equal(t, "resp", resp)
+	assert.Nil(t, err)
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := grpcerror.StreamServerInterceptor()
+
+	err := interceptor(nil, nil, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		return httperror.Unauthorized
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestFromStatus(t *testing.T) {
+	assert.Nil(t, grpcerror.FromStatus(status.New(codes.OK, "")))
+
+	original := httperror.WithRetryAfter(httperror.WithCode(httperror.NewPublic(429, "slow down"), "rate_limited"), 5*time.Second)
+	err := grpcerror.FromError(grpcerror.Error(original))
+
+	assert.Equal(t, 429, httperror.StatusCode(err))
+	assert.Equal(t, "slow down", httperror.PublicMessage(err))
+	assert.Equal(t, "rate_limited", httperror.ErrorCode(err))
+	assert.Equal(t, "5", httperror.Headers(err).Get("Retry-After"))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "original status is preserved")
+
+	{
+		err := grpcerror.FromError(grpcerror.Error(httperror.NewValidationError(httperror.FieldError{Field: "email", Message: "is required"})))
+		assert.Equal(t, 400, httperror.StatusCode(err))
+		assert.Equal(t, []httperror.FieldError{{Field: "email", Message: "is required"}}, httperror.FieldErrors(err))
+	}
+
+	other := errors.New("other")
+	assert.Equal(t, other, grpcerror.FromError(other))
+}
@@ -34,6 +34,7 @@ type XHandlerFunc[P any] func(w http.ResponseWriter, r *http.Request, p P) error
 func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err := h(w, r)
 	if err != nil {
+		ReportError(r.Context(), r, err)
 		DefaultErrorHandler(w, err)
 	}
 }
@@ -44,6 +45,7 @@ func (h XHandlerFunc[P]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var zeroValue P
 	err := h(w, r, zeroValue)
 	if err != nil {
+		ReportError(r.Context(), r, err)
 		DefaultErrorHandler(w, err)
 	}
 }
@@ -64,7 +66,8 @@ func (h XHandlerFunc[P]) Serve(w http.ResponseWriter, r *http.Request, p P) erro
 func WrapHandlerFunc(h func(w http.ResponseWriter, r *http.Request) error, eh ErrorHandler) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		err := h(w, r)
-		if err != nil {
+		ReportError(r.Context(), r, err)
+		if err != nil && !ResponseWritten(err) {
 			eh(w, err)
 		}
 	})
@@ -75,8 +78,100 @@ func WrapHandlerFunc(h func(w http.ResponseWriter, r *http.Request) error, eh Er
 func WrapXHandlerFunc[P any](h func(w http.ResponseWriter, r *http.Request, p P) error, eh ErrorHandler) func(w http.ResponseWriter, r *http.Request, p P) {
 	return func(w http.ResponseWriter, r *http.Request, p P) {
 		err := h(w, r, p)
-		if err != nil {
+		ReportError(r.Context(), r, err)
+		if err != nil && !ResponseWritten(err) {
 			eh(w, err)
 		}
 	}
 }
+
+// WithErrorHandler returns a [httperror.Handler] that handles errors returned
+// by h using the custom error handler eh. This lets specific routes (for
+// example an API subtree that renders JSON errors) override error rendering
+// while still being registered on a plain router.
+//
+// Since the error has already been handled, the Serve method of the returned
+// handler returns nil. Middleware that needs to see the error, such as
+// logging, should be applied to h rather than to the returned handler.
+func WithErrorHandler(h Handler, eh ErrorHandler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err := h.Serve(w, r)
+		ReportError(r.Context(), r, err)
+		if err != nil && !ResponseWritten(err) {
+			eh(w, err)
+		}
+		return nil
+	})
+}
+
+// WrapHandler wraps a [httperror.Handler], such as one produced by a router
+// or by middleware, with a custom error handler, returning a standard
+// [http.Handler]. If eh is nil, [httperror.DefaultErrorHandler] is used.
+// Options can turn on panic recovery (see [httperror.WithPanicRecovery]) or
+// use a request-aware error handler (see
+// [httperror.WithRequestErrorHandler]).
+func WrapHandler(h Handler, eh ErrorHandler, opts ...HandlerOption) http.Handler {
+	if eh == nil {
+		eh = DefaultErrorHandler
+	}
+
+	o := handlerOptions{
+		errorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			eh(w, err)
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o.handler(h)
+}
+
+// XHandlerWithProvider returns an [httperror.XHandler] that serves h, but
+// that derives the parameter from the request using provide when used as a
+// standard [http.Handler], instead of calling h with the zero value of P
+// (which can hide bugs). If provide returns an error, it is handled by
+// [DefaultErrorHandler]. Errors without a status code are treated as
+// BadRequest. The Serve method passes its parameter to h unchanged.
+func XHandlerWithProvider[P any](h XHandler[P], provide func(*http.Request) (P, error)) XHandler[P] {
+	return xHandlerWithProvider[P]{h, provide}
+}
+
+type xHandlerWithProvider[P any] struct {
+	XHandler[P]
+	provide func(*http.Request) (P, error)
+}
+
+// ServeHTTP makes xHandlerWithProvider implement the standard [http.Handler]
+// interface, deriving the parameter from the request.
+func (h xHandlerWithProvider[P]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := serveBound(h.XHandler, h.provide, w, r)
+	ReportError(r.Context(), r, err)
+	if err != nil && !ResponseWritten(err) {
+		DefaultErrorHandler(w, err)
+	}
+}
+
+// Bind returns a [httperror.Handler] that extracts the parameter of h from
+// the request using extract, and then serves h with it. This lets
+// parameterized handlers be registered on ordinary routers. Errors returned
+// by extract that have no status code are returned as BadRequest, so that
+// extraction failures are rendered as 400s through the normal error path.
+//
+//	httperror.Handle(mux, "GET /orders/{id}", httperror.Bind[OrderParams](getOrder, parseOrderParams))
+func Bind[P any](h XHandler[P], extract func(*http.Request) (P, error)) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return serveBound(h, extract, w, r)
+	})
+}
+
+func serveBound[P any](h XHandler[P], extract func(*http.Request) (P, error), w http.ResponseWriter, r *http.Request) error {
+	p, err := extract(r)
+	if err != nil {
+		if !HasStatusCode(err) {
+			err = Wrap(err, http.StatusBadRequest)
+		}
+		return err
+	}
+	return h.Serve(w, r, p)
+}
@@ -0,0 +1,101 @@
+package httperror
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithHeader wraps an error and attaches an HTTP response header, such as
+// Retry-After or WWW-Authenticate, that error handlers set when serving the
+// error response. The status code and public message of the wrapped error
+// are preserved. Attached headers can be extracted using
+// [httperror.Headers].
+func WithHeader(err error, key, value string) error {
+	h := make(http.Header)
+	h.Set(key, value)
+	return headerError{err, h}
+}
+
+// WithRetryAfter wraps an error and attaches a Retry-After header telling
+// the client how long to wait before retrying. The delay is rounded up to
+// whole seconds, and a negative delay is treated as 0.
+func WithRetryAfter(err error, d time.Duration) error {
+	d = max(d, 0)
+	seconds := (d + time.Second - 1) / time.Second
+	return WithHeader(err, "Retry-After", strconv.FormatInt(int64(seconds), 10))
+}
+
+// RangeNotSatisfiable returns a RequestedRangeNotSatisfiable error for a
+// resource of size bytes, with the Content-Range header that a 416
+// response must include (e.g. "bytes */1234") attached.
+func RangeNotSatisfiable(size int64) error {
+	return WithHeader(RequestedRangeNotSatisfiable, "Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+}
+
+// Headers extracts the HTTP response headers attached to an error and any
+// errors it wraps, from errors that have a `Headers() http.Header` method
+// (see [httperror.WithHeader]), including errors combined with
+// errors.Join. Where the same header is attached more than once, the
+// outermost (or first) value wins. Returns nil if there are no headers.
+func Headers(err error) http.Header {
+	return appendHeaders(nil, err)
+}
+
+// appendHeaders adds the headers attached to err and the errors it wraps to
+// header, walking the tree of wrapped errors depth-first like errors.As.
+func appendHeaders(header http.Header, err error) http.Header {
+	for err != nil {
+		if he, ok := err.(interface{ Headers() http.Header }); ok {
+			for k, vs := range he.Headers() {
+				if header == nil {
+					header = make(http.Header)
+				}
+				if _, exists := header[k]; !exists {
+					header[k] = vs
+				}
+			}
+		}
+
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				header = appendHeaders(header, e)
+			}
+			return header
+		default:
+			return header
+		}
+	}
+
+	return header
+}
+
+type headerError struct {
+	inner  error
+	header http.Header
+}
+
+// Error returns the error string of the wrapped error.
+func (e headerError) Error() string {
+	return e.inner.Error()
+}
+
+// Unwrap returns the inner error of a headerError
+func (e headerError) Unwrap() error {
+	return e.inner
+}
+
+func (e headerError) Headers() http.Header {
+	return e.header
+}
+
+// setErrorHeaders sets the headers attached to the error on the response.
+// Error handlers call this before writing the status code.
+func setErrorHeaders(w http.ResponseWriter, e error) {
+	for k, vs := range Headers(e) {
+		w.Header()[k] = vs
+	}
+}
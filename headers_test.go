@@ -0,0 +1,47 @@
+package httperror_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeNotSatisfiable(t *testing.T) {
+	e := httperror.RangeNotSatisfiable(1234)
+	assert.True(t, errors.Is(e, httperror.RequestedRangeNotSatisfiable))
+
+	rr := httptest.NewRecorder()
+	httperror.ProblemErrorHandler(rr, e)
+	assert.Equal(t, 416, rr.Code)
+	assert.Equal(t, "bytes */1234", rr.Header().Get("Content-Range"))
+}
+
+func TestHeaders(t *testing.T) {
+	e := httperror.WithRetryAfter(httperror.ServiceUnavailable, 1500*time.Millisecond)
+	e = httperror.WithHeader(e, "Cache-Control", "no-store")
+
+	assert.True(t, errors.Is(e, httperror.ServiceUnavailable))
+	assert.Equal(t, "503 Service Unavailable", e.Error())
+	assert.Equal(t, http.Header{"Retry-After": {"2"}, "Cache-Control": {"no-store"}}, httperror.Headers(e))
+	assert.Nil(t, httperror.Headers(httperror.NotFound))
+	assert.Equal(t, "0", httperror.Headers(httperror.WithRetryAfter(httperror.ServiceUnavailable, -time.Second)).Get("Retry-After"))
+
+	joined := errors.Join(
+		httperror.WithHeader(httperror.NotFound, "Cache-Control", "max-age=60"),
+		httperror.WithRetryAfter(httperror.ServiceUnavailable, time.Second),
+		httperror.WithHeader(httperror.BadGateway, "Cache-Control", "no-store"),
+	)
+	assert.Equal(t, http.Header{"X-Request-Id": {"42"}, "Retry-After": {"1"}, "Cache-Control": {"max-age=60"}},
+		httperror.Headers(httperror.WithHeader(joined, "X-Request-Id", "42")), "headers of joined errors, first value wins")
+
+	rr := httptest.NewRecorder()
+	httperror.DefaultErrorHandler(rr, e)
+	assert.Equal(t, 503, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+}
@@ -0,0 +1,191 @@
+package httperror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HealthCheck is a named check run by [httperror.HealthHandler]. Check
+// returns an error if the dependency it checks is unhealthy.
+type HealthCheck struct {
+	Name  string
+	Check func(context.Context) error
+}
+
+// HealthHandler returns a [httperror.HandlerFunc] for health check
+// endpoints. It runs the checks concurrently with the request context, and
+// responds with the status of each check: 200 OK if all checks pass, and
+// 503 Service Unavailable otherwise. The public message of the error of a
+// failing check (see [httperror.PublicMessage]), if any, is included.
+//
+// The response format is negotiated from the Accept header: JSON if the
+// client accepts application/json, plain text otherwise. For example:
+//
+//	{"status":"error","message":"Service Unavailable: 1 of 2 health checks failed","code":503,"data":{"checks":[{"name":"database","status":"fail"},{"name":"cache","status":"pass"}]}}
+//
+// If any checks fail, the response is written, and ServiceUnavailable is
+// returned, wrapping the errors of the failing checks (so they can be
+// inspected with errors.Is and errors.As, and logged). Since the response
+// has already been written (see [httperror.ResponseWritten]), error
+// handlers don't render it again.
+//
+//	httperror.Handle(mux, "GET /healthz", httperror.HealthHandler(
+//		httperror.HealthCheck{Name: "database", Check: db.PingContext},
+//	))
+func HealthHandler(checks ...HealthCheck) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		errs := make([]error, len(checks))
+
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func(i int, check HealthCheck) {
+				defer wg.Done()
+				errs[i] = check.Check(r.Context())
+			}(i, check)
+		}
+		wg.Wait()
+
+		results := make([]healthCheckResult, len(checks))
+		var failed []error
+		for i, err := range errs {
+			results[i] = healthCheckResult{Name: checks[i].Name, Status: "pass"}
+			if err != nil {
+				results[i].Status = "fail"
+				results[i].Message = PublicMessage(err)
+				failed = append(failed, err)
+			}
+		}
+
+		var err error
+		s := http.StatusOK
+		if len(failed) > 0 {
+			he := healthError{failed, len(checks), httpError{http.StatusServiceUnavailable}}
+			err = writtenError{he}
+			s = he.status
+		}
+
+		jsonResponse := acceptsJSON(r)
+		if jsonResponse {
+			w.Header().Set("Content-Type", contentTypeJSON)
+		} else {
+			w.Header().Set("Content-Type", contentTypeTextPlain)
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(s)
+
+		if jsonResponse {
+			writeHealthJSON(w, err, results)
+		} else {
+			writeHealthText(w, err, results)
+		}
+		return err
+	}
+}
+
+type healthCheckResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// writeHealthJSON writes a JSend response, whose data member holds the
+// results of the checks.
+func writeHealthJSON(w http.ResponseWriter, err error, results []healthCheckResult) {
+	type healthData struct {
+		Checks []healthCheckResult `json:"checks"`
+	}
+	body := struct {
+		Status  string     `json:"status"`
+		Message string     `json:"message,omitempty"`
+		Code    int        `json:"code,omitempty"`
+		Data    healthData `json:"data"`
+	}{Status: "success", Data: healthData{results}}
+
+	if err != nil {
+		s := StatusCode(err)
+		body.Status = "error"
+		body.Message = statusText(s) + ": " + PublicMessage(err)
+		body.Code = s
+	}
+
+	_ = json.NewEncoder(w).Encode(body) // No error handling for error handling
+}
+
+// writeHealthText writes "OK", or the status and public message of err,
+// followed by a line per check.
+func writeHealthText(w http.ResponseWriter, err error, results []healthCheckResult) {
+	var b bytes.Buffer
+
+	if err != nil {
+		s := StatusCode(err)
+		b.WriteString(strconv.Itoa(s) + " " + statusText(s) + ": " + PublicMessage(err) + "\n")
+	} else {
+		b.WriteString("OK\n")
+	}
+	for _, result := range results {
+		b.WriteString(result.Name + ": " + result.Status)
+		if result.Message != "" {
+			b.WriteString(": " + result.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	_, _ = w.Write(b.Bytes())
+}
+
+// acceptsJSON reports whether the Accept header of the request includes a
+// JSON media type.
+func acceptsJSON(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			if mediaType == contentTypeJSON || strings.HasSuffix(mediaType, "+json") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type healthError struct {
+	errs   []error
+	checks int
+	httpError
+}
+
+func (e healthError) Error() string {
+	var b bytes.Buffer
+
+	b.WriteString(e.httpError.Error())
+	b.WriteString(": ")
+	b.WriteString(e.PublicMessage())
+	for i, err := range e.errs {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the errors of the failing checks.
+func (e healthError) Unwrap() []error {
+	return e.errs
+}
+
+func (e healthError) PublicMessage() string {
+	return strconv.Itoa(len(e.errs)) + " of " + strconv.Itoa(e.checks) + " health checks failed"
+}
@@ -35,7 +35,7 @@ func (e httpError) Error() string {
 
 	b.WriteString(strconv.Itoa(e.status))
 	b.WriteString(" ")
-	b.WriteString(http.StatusText(e.status))
+	b.WriteString(statusText(e.status))
 	return b.String()
 }
 
@@ -71,19 +71,63 @@ type httpStatusError = interface {
 // If the error doesn't have an embedded status code, it returns InternalServerError.
 // If the error is nil, returns 200 OK.
 func StatusCode(err error) int {
-	var httpError httpStatusError
-
 	if err == nil {
 		return http.StatusOK
 	}
 
-	if errors.As(err, &httpError) {
-		return httpError.httpStatusCode()
+	if s, ok := statusCode(err); ok {
+		return s
 	}
 
 	return http.StatusInternalServerError
 }
 
+// HasStatusCode reports whether an HTTP status code is embedded in err or
+// an error it wraps. Unlike [httperror.StatusCode], it tells an error with
+// an explicit 500 apart from an error without a status code, so that error
+// mappings can leave errors that already have a status code unchanged.
+func HasStatusCode(err error) bool {
+	_, ok := statusCode(err)
+	return ok
+}
+
+// statusCode extracts the HTTP status code embedded in err, if any. Since it
+// is called for every error response, the errors created by this package are
+// matched with a type switch, and only chains containing other errors are
+// searched with errors.As.
+func statusCode(err error) (int, bool) {
+	for {
+		switch e := err.(type) {
+		case httpError:
+			return e.status, true
+		case *wrappedError:
+			return e.status, true
+		case *messageError:
+			return e.status, true
+		case *publicError:
+			return e.status, true
+		case *publicWrappedError:
+			return e.status, true
+		case validationError:
+			return e.status, true
+		case responseError:
+			return e.status, true
+		case codedError:
+			err = e.inner
+		case headerError:
+			err = e.inner
+		case writtenError:
+			err = e.inner
+		default:
+			var se httpStatusError
+			if errors.As(err, &se) {
+				return se.httpStatusCode(), true
+			}
+			return 0, false
+		}
+	}
+}
+
 // BadRequest represents the StatusBadRequest HTTP error.
 var BadRequest = httpError{http.StatusBadRequest}
 
@@ -0,0 +1,100 @@
+/*
+Package httperrortest provides utilities for testing handlers that return
+errors, error handlers, and clients. See the documentation of the parent
+package at https://github.com/johnwarden/httperror
+
+The assertion functions report failures with t.Errorf, so a test continues
+after a failed assertion, and return whether the assertion succeeded:
+
+	err := h.Serve(w, r)
+	httperrortest.AssertStatus(t, err, http.StatusNotFound)
+	httperrortest.AssertPublic(t, err, "no such order")
+*/
+package httperrortest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+)
+
+// AssertStatus asserts that the status code of err (see
+// [httperror.StatusCode]) is status. A nil error has status 200.
+func AssertStatus(t testing.TB, err error, status int) bool {
+	t.Helper()
+	if s := httperror.StatusCode(err); s != status {
+		t.Errorf("status code: got %d, want %d (error: %v)", s, status, err)
+		return false
+	}
+	return true
+}
+
+// AssertPublic asserts that the public message of err (see
+// [httperror.PublicMessage]) is message.
+func AssertPublic(t testing.TB, err error, message string) bool {
+	t.Helper()
+	if m := httperror.PublicMessage(err); m != message {
+		t.Errorf("public message: got %q, want %q (error: %v)", m, message, err)
+		return false
+	}
+	return true
+}
+
+// AssertCode asserts that the error code of err (see
+// [httperror.ErrorCode]) is code.
+func AssertCode(t testing.TB, err error, code string) bool {
+	t.Helper()
+	if c := httperror.ErrorCode(err); c != code {
+		t.Errorf("error code: got %q, want %q (error: %v)", c, code, err)
+		return false
+	}
+	return true
+}
+
+// AssertRetryable asserts that err is retryable (see
+// [httperror.IsRetryable]).
+func AssertRetryable(t testing.TB, err error) bool {
+	t.Helper()
+	if !httperror.IsRetryable(err) {
+		t.Errorf("error is not retryable: %v", err)
+		return false
+	}
+	return true
+}
+
+// AssertHeader asserts that the header key attached to err (see
+// [httperror.Headers]) has the given value.
+func AssertHeader(t testing.TB, err error, key, value string) bool {
+	t.Helper()
+	if v := httperror.Headers(err).Get(key); v != value {
+		t.Errorf("%s header: got %q, want %q (error: %v)", key, v, value, err)
+		return false
+	}
+	return true
+}
+
+// AssertResponseStatus asserts that the status code of resp is status.
+func AssertResponseStatus(t testing.TB, resp *http.Response, status int) bool {
+	t.Helper()
+	if resp.StatusCode != status {
+		t.Errorf("response status code: got %d, want %d", resp.StatusCode, status)
+		return false
+	}
+	return true
+}
+
+// AssertResponseError asserts that resp is an error response that, when
+// converted with [httperror.FromResponse], matches target with errors.Is,
+// for example a status sentinel such as httperror.NotFound, or an error
+// code sentinel created with [httperror.WithCode].
+func AssertResponseError(t testing.TB, resp *http.Response, target error) bool {
+	t.Helper()
+	err := httperror.FromResponse(resp)
+	if !errors.Is(err, target) {
+		t.Errorf("response error: got %v, want %v", err, target)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package httperrortest_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingT records the failures reported by assertions.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	err := httperror.WithRetryAfter(httperror.WithCode(httperror.NewPublic(http.StatusServiceUnavailable, "down for maintenance"), "maintenance"), time.Minute)
+
+	rt := &recordingT{}
+	assert.True(t, httperrortest.AssertStatus(rt, err, 503))
+	assert.True(t, httperrortest.AssertPublic(rt, err, "down for maintenance"))
+	assert.True(t, httperrortest.AssertCode(rt, err, "maintenance"))
+	assert.True(t, httperrortest.AssertRetryable(rt, err))
+	assert.True(t, httperrortest.AssertHeader(rt, err, "Retry-After", "60"))
+	assert.True(t, httperrortest.AssertStatus(rt, nil, 200))
+	assert.Empty(t, rt.failures)
+
+	assert.False(t, httperrortest.AssertStatus(rt, err, 404))
+	assert.False(t, httperrortest.AssertPublic(rt, err, ""))
+	assert.False(t, httperrortest.AssertCode(rt, err, "other"))
+	assert.False(t, httperrortest.AssertRetryable(rt, httperror.NotFound))
+	assert.False(t, httperrortest.AssertHeader(rt, err, "Retry-After", "1"))
+	assert.Equal(t, []string{
+		"status code: got 503, want 404 (error: 503 Service Unavailable: down for maintenance)",
+		`public message: got "down for maintenance", want "" (error: 503 Service Unavailable: down for maintenance)`,
+		`error code: got "maintenance", want "other" (error: 503 Service Unavailable: down for maintenance)`,
+		"error is not retryable: 404 Not Found",
+		`Retry-After header: got "60", want "1" (error: 503 Service Unavailable: down for maintenance)`,
+	}, rt.failures)
+}
+
+func TestAssertResponse(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rr.Header().Set("Content-Type", "application/json")
+	httperror.DefaultErrorHandler(rr, httperror.WithCode(httperror.Conflict, "out_of_stock"))
+
+	rt := &recordingT{}
+	assert.True(t, httperrortest.AssertResponseStatus(rt, rr.Result(), 409))
+	assert.True(t, httperrortest.AssertResponseError(rt, rr.Result(), httperror.Conflict))
+	assert.True(t, httperrortest.AssertResponseError(rt, rr.Result(), httperror.WithCode(httperror.Conflict, "out_of_stock")))
+	assert.Empty(t, rt.failures)
+
+	assert.False(t, httperrortest.AssertResponseStatus(rt, rr.Result(), 200))
+	assert.False(t, httperrortest.AssertResponseError(rt, rr.Result(), httperror.NotFound))
+	assert.Equal(t, []string{
+		"response status code: got 409, want 200",
+		"response error: got 409 Conflict, want 404 Not Found",
+	}, rt.failures)
+}
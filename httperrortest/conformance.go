@@ -0,0 +1,116 @@
+package httperrortest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+)
+
+// internalMessage marks the internal error messages that must not appear in
+// error responses.
+const internalMessage = "httperrortest: internal error message"
+
+// Sentinels returns the error status sentinels of the parent package (4xx
+// and 5xx), such as httperror.NotFound.
+func Sentinels() []error {
+	return []error{
+		httperror.BadRequest,
+		httperror.Unauthorized,
+		httperror.PaymentRequired,
+		httperror.Forbidden,
+		httperror.NotFound,
+		httperror.MethodNotAllowed,
+		httperror.NotAcceptable,
+		httperror.ProxyAuthRequired,
+		httperror.RequestTimeout,
+		httperror.Conflict,
+		httperror.Gone,
+		httperror.LengthRequired,
+		httperror.PreconditionFailed,
+		httperror.RequestEntityTooLarge,
+		httperror.RequestURITooLong,
+		httperror.UnsupportedMediaType,
+		httperror.RequestedRangeNotSatisfiable,
+		httperror.ExpectationFailed,
+		httperror.Teapot,
+		httperror.MisdirectedRequest,
+		httperror.UnprocessableEntity,
+		httperror.Locked,
+		httperror.FailedDependency,
+		httperror.TooEarly,
+		httperror.UpgradeRequired,
+		httperror.PreconditionRequired,
+		httperror.TooManyRequests,
+		httperror.RequestHeaderFieldsTooLarge,
+		httperror.UnavailableForLegalReasons,
+		httperror.InternalServerError,
+		httperror.NotImplemented,
+		httperror.BadGateway,
+		httperror.ServiceUnavailable,
+		httperror.GatewayTimeout,
+		httperror.HTTPVersionNotSupported,
+		httperror.VariantAlsoNegotiates,
+		httperror.InsufficientStorage,
+		httperror.LoopDetected,
+		httperror.NotExtended,
+		httperror.NetworkAuthenticationRequired,
+	}
+}
+
+// AssertFormat checks that the error handler of format f fulfills the
+// contract of error handlers for every status sentinel (see
+// [httperrortest.Sentinels]), so that custom error handlers can be
+// validated:
+//
+//   - the status code of the response is the status code of the error
+//   - the Content-Type header is set
+//   - the body isn't empty, or is empty if f.EmptyBody is true
+//   - the message of an internal error wrapped by the error (see
+//     [httperror.Wrap]) appears neither in the body nor in the headers (see
+//     [httperrortest.AssertNoLeaks] for more thorough checks)
+//
+// Error handlers that choose their format from the response Content-Type,
+// like [httperror.DefaultErrorHandler], are checked with the ContentType of
+// f set.
+//
+//	httperrortest.AssertFormat(t, httperrortest.Format{Name: "custom", ErrorHandler: customErrorHandler})
+func AssertFormat(t testing.TB, f Format) bool {
+	t.Helper()
+
+	ok := true
+	for _, sentinel := range Sentinels() {
+		s := httperror.StatusCode(sentinel)
+		err := httperror.Wrap(errors.New(internalMessage), s)
+
+		rr := httptest.NewRecorder()
+		if f.ContentType != "" {
+			rr.Header().Set("Content-Type", f.ContentType)
+		}
+		f.ErrorHandler(rr, err)
+
+		fail := func(format string, args ...interface{}) {
+			t.Helper()
+			t.Errorf("%s format, %d %s: "+format, append([]interface{}{f.Name, s, http.StatusText(s)}, args...)...)
+			ok = false
+		}
+
+		if rr.Code != s {
+			fail("got status code %d", rr.Code)
+		}
+		if rr.Header().Get("Content-Type") == "" {
+			fail("Content-Type header not set")
+		}
+		if f.EmptyBody && rr.Body.Len() != 0 {
+			fail("body not empty")
+		} else if !f.EmptyBody && rr.Body.Len() == 0 {
+			fail("empty body")
+		}
+		if where := findSecret(rr, internalMessage); where != "" {
+			fail("internal error message in %s", where)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,52 @@
+package httperrortest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertFormat(t *testing.T) {
+	for _, f := range httperrortest.Formats() {
+		httperrortest.AssertFormat(t, f)
+	}
+
+	assert.Len(t, httperrortest.Sentinels(), 40)
+
+	leaky := func(w http.ResponseWriter, err error) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(httperror.StatusCode(err))
+		w.Write([]byte(err.Error()))
+	}
+	rt := &recordingT{}
+	assert.False(t, httperrortest.AssertFormat(rt, httperrortest.Format{Name: "leaky", ErrorHandler: leaky}))
+	assert.Len(t, rt.failures, 40)
+	assert.Equal(t, "leaky format, 400 Bad Request: internal error message in body: 400 Bad Request: httperrortest: internal error message", rt.failures[0])
+
+	rt = &recordingT{}
+	assert.False(t, httperrortest.AssertFormat(rt, httperrortest.Format{Name: "untyped", ErrorHandler: func(w http.ResponseWriter, err error) {
+		w.WriteHeader(httperror.StatusCode(err))
+		w.Write([]byte("error"))
+	}}))
+	assert.Equal(t, "untyped format, 400 Bad Request: Content-Type header not set", rt.failures[0])
+
+	head := func(w http.ResponseWriter, err error) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(httperror.StatusCode(err))
+	}
+	assert.True(t, httperrortest.AssertFormat(t, httperrortest.Format{Name: "head", ErrorHandler: head, EmptyBody: true}))
+
+	rt = &recordingT{}
+	assert.False(t, httperrortest.AssertFormat(rt, httperrortest.Format{Name: "html", ErrorHandler: httperror.DefaultErrorHandler, EmptyBody: true}))
+	assert.Equal(t, "html format, 400 Bad Request: body not empty", rt.failures[0])
+
+	rt = &recordingT{}
+	assert.False(t, httperrortest.AssertFormat(rt, httperrortest.Format{Name: "teapot", ErrorHandler: func(w http.ResponseWriter, err error) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusTeapot)
+	}}))
+	assert.Equal(t, []string{"teapot format, 400 Bad Request: got status code 418", "teapot format, 400 Bad Request: empty body"}, rt.failures[:2])
+}
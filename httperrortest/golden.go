@@ -0,0 +1,140 @@
+package httperrortest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+)
+
+var update = flag.Bool("httperrortest.update", false, "update the golden files of httperrortest.AssertGolden")
+
+// Format is an error response format: an error handler, and the response
+// Content-Type set before calling it, for error handlers such as
+// [httperror.DefaultErrorHandler] that choose their format from it.
+type Format struct {
+	Name         string
+	ContentType  string
+	ErrorHandler httperror.ErrorHandler
+
+	// EmptyBody is true if the error handler suppresses the response body,
+	// e.g. because it is used for HEAD requests. [httperrortest.AssertFormat]
+	// then checks that the body is empty.
+	EmptyBody bool
+}
+
+var (
+	formatsMu sync.Mutex
+	formats   = []Format{
+		{Name: "html", ErrorHandler: httperror.DefaultErrorHandler},
+		{Name: "text", ContentType: "text/plain", ErrorHandler: httperror.DefaultErrorHandler},
+		{Name: "json", ContentType: "application/json", ErrorHandler: httperror.DefaultErrorHandler},
+		{Name: "problem", ErrorHandler: httperror.ProblemErrorHandler},
+		{Name: "stripe", ErrorHandler: httperror.StripeErrorHandler},
+		{Name: "github", ErrorHandler: httperror.GitHubErrorHandler},
+		{Name: "google", ErrorHandler: httperror.GoogleErrorHandler},
+		{Name: "s3", ErrorHandler: httperror.S3ErrorHandler},
+		{Name: "kubernetes", ErrorHandler: httperror.KubernetesErrorHandler},
+		{Name: "jsonrpc", ErrorHandler: func(w http.ResponseWriter, err error) { httperror.WriteJSONRPCError(w, nil, err) }},
+	}
+)
+
+// RegisterFormat adds a format, such as an application's custom error
+// handler, to the formats rendered by [httperrortest.AssertGolden]. A
+// format with the same name is replaced.
+func RegisterFormat(f Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	for i := range formats {
+		if formats[i].Name == f.Name {
+			formats[i] = f
+			return
+		}
+	}
+	formats = append(formats, f)
+}
+
+// Formats returns the formats rendered by [httperrortest.AssertGolden]: the
+// error handlers of the parent package, and the registered formats.
+func Formats() []Format {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	return append([]Format(nil), formats...)
+}
+
+// Render returns the error response for err in format f: the status line,
+// the response headers sorted by name, and the body.
+func Render(f Format, err error) []byte {
+	rr := httptest.NewRecorder()
+	if f.ContentType != "" {
+		rr.Header().Set("Content-Type", f.ContentType)
+	}
+	f.ErrorHandler(rr, err)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "HTTP %d %s\n", rr.Code, http.StatusText(rr.Code))
+
+	keys := make([]string, 0, len(rr.Header()))
+	for k := range rr.Header() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range rr.Header()[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+
+	b.WriteString("\n")
+	b.Write(rr.Body.Bytes())
+	return b.Bytes()
+}
+
+// AssertGolden renders err in every format (see
+// [httperrortest.Formats]), and compares each response (see
+// [httperrortest.Render]) with the golden file testdata/name/format.golden,
+// so that changes to the rendering of errors show up in review. Run the
+// tests with the -httperrortest.update flag to write the golden files:
+//
+//	go test -run TestErrorFormats -httperrortest.update
+func AssertGolden(t testing.TB, name string, err error) bool {
+	t.Helper()
+
+	ok := true
+	for _, f := range Formats() {
+		got := Render(f, err)
+		path := filepath.Join("testdata", name, f.Name+".golden")
+
+		if *update {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, got, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s format: %v (run the tests with -httperrortest.update to create the golden file)", f.Name, err)
+			ok = false
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s format: response doesn't match %s\ngot:\n%s\nwant:\n%s", f.Name, path, got, want)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,38 @@
+package httperrortest_test
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertGolden(t *testing.T) {
+	httperrortest.AssertGolden(t, "not_found", httperror.NotFound)
+	httperrortest.AssertGolden(t, "out_of_credit", httperror.WithCode(httperror.NewPublic(http.StatusPaymentRequired, "Your balance is too low."), "out_of_credit"))
+	httperrortest.AssertGolden(t, "validation", httperror.NewValidationError(httperror.FieldError{Resource: "Issue", Field: "title", Code: "missing_field", Message: "is required"}))
+	httperrortest.AssertGolden(t, "rate_limited", httperror.WithRetryAfter(httperror.TooManyRequests, time.Minute))
+
+	if flag.Lookup("httperrortest.update").Value.String() == "true" {
+		return
+	}
+
+	rt := &recordingT{}
+	assert.False(t, httperrortest.AssertGolden(rt, "not_found", httperror.Gone))
+	assert.Len(t, rt.failures, len(httperrortest.Formats()))
+	assert.True(t, strings.HasPrefix(rt.failures[0], "html format: response doesn't match testdata/not_found/html.golden"))
+
+	rt = &recordingT{}
+	assert.False(t, httperrortest.AssertGolden(rt, "no_such_golden_file", httperror.Gone))
+	assert.Contains(t, rt.failures[0], "-httperrortest.update")
+}
+
+func TestRender(t *testing.T) {
+	f := httperrortest.Format{Name: "text", ContentType: "text/plain", ErrorHandler: httperror.DefaultErrorHandler}
+	assert.Equal(t, "HTTP 404 Not Found\nContent-Type: text/plain\n\n404 Not Found\n", string(httperrortest.Render(f, httperror.NotFound)))
+}
@@ -0,0 +1,61 @@
+package httperrortest
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/johnwarden/httperror"
+)
+
+// StatusHandler returns a handler that returns an error with the given
+// status code, so that errors.Is(err, httperror.NotFound) is true for
+// StatusHandler(404). For status codes below 400, it writes the status code
+// and returns nil.
+func StatusHandler(status int) httperror.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if status < 400 {
+			w.WriteHeader(status)
+			return nil
+		}
+		return httperror.NewPublic(status, "")
+	}
+}
+
+// PanicHandler returns a handler that panics with v.
+func PanicHandler(v interface{}) httperror.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		panic(v)
+	}
+}
+
+// SlowHandler returns a handler that waits for d before writing a 200 OK
+// response. If the request context is done first, it returns the context
+// error instead.
+func SlowHandler(d time.Duration) httperror.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+			w.WriteHeader(http.StatusOK)
+			return nil
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+	}
+}
+
+// FlakyHandler returns a handler that fails with ServiceUnavailable for the
+// given fraction of requests (between 0 and 1), chosen at random, and
+// writes a 200 OK response for the others.
+func FlakyHandler(rate float64) httperror.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if rand.Float64() < rate {
+			return httperror.ServiceUnavailable
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package httperrortest_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlers(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+
+	_, _, err := httperrortest.Serve(httperrortest.StatusHandler(404), req)
+	assert.True(t, errors.Is(err, httperror.NotFound))
+	assert.Equal(t, "404 Not Found", err.Error())
+
+	s, _, err := httperrortest.Serve(httperrortest.StatusHandler(204), req)
+	assert.Equal(t, 204, s)
+	assert.NoError(t, err)
+
+	_, _, err = httperrortest.Serve(httperrortest.PanicHandler("oops"), req)
+	assert.True(t, errors.Is(err, httperror.Panic))
+
+	s, _, err = httperrortest.Serve(httperrortest.SlowHandler(time.Millisecond), req)
+	assert.Equal(t, 200, s)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, _, err = httperrortest.Serve(httperrortest.SlowHandler(time.Hour), req.WithContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, _, err = httperrortest.Serve(httperrortest.FlakyHandler(1), req)
+	assert.True(t, errors.Is(err, httperror.ServiceUnavailable))
+
+	s, _, err = httperrortest.Serve(httperrortest.FlakyHandler(0), req)
+	assert.Equal(t, 200, s)
+	assert.NoError(t, err)
+
+	failures := 0
+	for i := 0; i < 1000; i++ {
+		if _, _, err := httperrortest.Serve(httperrortest.FlakyHandler(0.5), req); err != nil {
+			failures++
+		}
+	}
+	assert.InDelta(t, 500, failures, 150)
+}
@@ -0,0 +1,93 @@
+package httperrortest
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+)
+
+// ErrorShape creates an error whose internal (non-public) messages include
+// secret, for [httperrortest.AssertNoLeaks].
+type ErrorShape struct {
+	Name string
+	New  func(secret string) error
+}
+
+// errorShapes are the ways in which internal messages commonly end up in
+// errors returned by handlers.
+var errorShapes = []ErrorShape{
+	{"wrapped error", func(secret string) error {
+		return httperror.Wrap(errors.New(secret), 500)
+	}},
+	{"Errorf", func(secret string) error {
+		return httperror.Errorf(400, "parsing %s", secret)
+	}},
+	{"fmt.Errorf chain", func(secret string) error {
+		return fmt.Errorf("loading order %s: %w", secret, httperror.NotFound)
+	}},
+	{"coded error", func(secret string) error {
+		return httperror.WithCode(httperror.Wrap(errors.New(secret), 409), "conflict")
+	}},
+	{"error with Retry-After", func(secret string) error {
+		return httperror.WithRetryAfter(httperror.Wrap(errors.New(secret), 503), time.Second)
+	}},
+	{"error without status", func(secret string) error {
+		return errors.New(secret)
+	}},
+	{"panic", func(secret string) error {
+		return httperror.PanicError(secret)
+	}},
+	{"transport error", func(secret string) error {
+		return httperror.FromTransportError(&url.Error{Op: "Get", URL: "http://internal/" + secret, Err: errors.New("connection reset")})
+	}},
+}
+
+// AssertNoLeaks checks that the error handler of format f doesn't reveal
+// internal error messages: it renders errors of common shapes (wrapped
+// errors, fmt.Errorf chains, panics, transport errors, and so on), and the
+// given shapes, each with a unique secret in its internal message, and
+// fails if a secret appears in the body or the headers of the response.
+// Public messages (see [httperror.PublicMessage]) are meant to be shown, so
+// shapes should only put secrets in internal messages.
+func AssertNoLeaks(t testing.TB, f Format, shapes ...ErrorShape) bool {
+	t.Helper()
+
+	ok := true
+	for i, shape := range append(append([]ErrorShape(nil), errorShapes...), shapes...) {
+		secret := fmt.Sprintf("httperrortest-secret-%d", i)
+
+		rr := httptest.NewRecorder()
+		if f.ContentType != "" {
+			rr.Header().Set("Content-Type", f.ContentType)
+		}
+		f.ErrorHandler(rr, shape.New(secret))
+
+		if where := findSecret(rr, secret); where != "" {
+			t.Errorf("%s format, %s: internal error message leaked in %s", f.Name, shape.Name, where)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// findSecret returns where in the response secret appears (the body or a
+// header), or the empty string.
+func findSecret(rr *httptest.ResponseRecorder, secret string) string {
+	if strings.Contains(rr.Body.String(), secret) {
+		return "body: " + rr.Body.String()
+	}
+	for k, vs := range rr.Header() {
+		for _, v := range vs {
+			if strings.Contains(v, secret) {
+				return k + " header: " + v
+			}
+		}
+	}
+	return ""
+}
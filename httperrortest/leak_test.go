@@ -0,0 +1,37 @@
+package httperrortest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertNoLeaks(t *testing.T) {
+	for _, f := range httperrortest.Formats() {
+		httperrortest.AssertNoLeaks(t, f)
+	}
+
+	leaky := func(w http.ResponseWriter, err error) {
+		w.Header().Set("Content-Type", "text/plain")
+		if httperror.StatusCode(err) >= 500 {
+			w.Header().Set("X-Debug", err.Error())
+		}
+		w.WriteHeader(httperror.StatusCode(err))
+		w.Write([]byte(httperror.PublicMessage(err)))
+	}
+
+	rt := &recordingT{}
+	assert.False(t, httperrortest.AssertNoLeaks(rt, httperrortest.Format{Name: "leaky", ErrorHandler: leaky}))
+	assert.Contains(t, rt.failures, "leaky format, wrapped error: internal error message leaked in X-Debug header: 500 Internal Server Error: httperrortest-secret-0")
+	assert.Len(t, rt.failures, 5)
+
+	rt = &recordingT{}
+	publicShape := httperrortest.ErrorShape{Name: "public message", New: func(secret string) error {
+		return httperror.NewPublic(http.StatusBadRequest, secret)
+	}}
+	assert.False(t, httperrortest.AssertNoLeaks(rt, httperrortest.Format{Name: "text", ContentType: "text/plain", ErrorHandler: httperror.DefaultErrorHandler}, publicShape))
+	assert.Equal(t, []string{"text format, public message: internal error message leaked in body: 400 Bad Request: httperrortest-secret-8\n"}, rt.failures)
+}
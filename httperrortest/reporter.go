@@ -0,0 +1,93 @@
+package httperrortest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+)
+
+// ReportedError is an error reported to a [httperrortest.Reporter].
+type ReportedError struct {
+	Err    error
+	Status int
+	Code   string
+	Method string
+	Path   string
+}
+
+// Reporter is an [httperror.ErrorReporter] that records the errors
+// reported to it, so that tests can assert which errors trigger alerts.
+// The zero value is ready to use. Since registered reporters can't be
+// removed, register a Reporter once, and reset it in each test:
+//
+//	var reporter httperrortest.Reporter
+//
+//	func init() {
+//		httperror.RegisterErrorReporter(&reporter, nil)
+//	}
+type Reporter struct {
+	mu     sync.Mutex
+	errors []ReportedError
+}
+
+// Report records err, with its status code (see [httperror.StatusCode]),
+// error code (see [httperror.ErrorCode]), and the method and path of r, if
+// any.
+func (rep *Reporter) Report(ctx context.Context, r *http.Request, err error) {
+	e := ReportedError{
+		Err:    err,
+		Status: httperror.StatusCode(err),
+		Code:   httperror.ErrorCode(err),
+	}
+	if r != nil {
+		e.Method, e.Path = r.Method, r.URL.Path
+	}
+
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	rep.errors = append(rep.errors, e)
+}
+
+// Errors returns the errors reported so far, in order.
+func (rep *Reporter) Errors() []ReportedError {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	return append([]ReportedError(nil), rep.errors...)
+}
+
+// Reset forgets the errors reported so far.
+func (rep *Reporter) Reset() {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	rep.errors = nil
+}
+
+// AssertReported asserts that an error with the given status code, error
+// code, and request path was reported. An empty code or path matches any
+// error code or path.
+func (rep *Reporter) AssertReported(t testing.TB, status int, code, path string) bool {
+	t.Helper()
+
+	errors := rep.Errors()
+	for _, e := range errors {
+		if e.Status == status && (code == "" || e.Code == code) && (path == "" || e.Path == path) {
+			return true
+		}
+	}
+	t.Errorf("no reported error with status %d, code %q, and path %q; reported: %v", status, code, path, errors)
+	return false
+}
+
+// AssertNotReported asserts that no errors were reported.
+func (rep *Reporter) AssertNotReported(t testing.TB) bool {
+	t.Helper()
+
+	if errors := rep.Errors(); len(errors) > 0 {
+		t.Errorf("unexpected reported errors: %v", errors)
+		return false
+	}
+	return true
+}
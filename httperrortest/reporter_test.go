@@ -0,0 +1,50 @@
+package httperrortest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+var reporter httperrortest.Reporter
+
+func init() {
+	httperror.RegisterErrorReporter(&reporter, nil)
+}
+
+func TestReporter(t *testing.T) {
+	reporter.Reset()
+
+	h := httperror.WrapHandler(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/broken" {
+			return httperror.WithCode(httperror.BadGateway, "upstream_failed")
+		}
+		return httperror.NotFound
+	}), httperror.DefaultErrorHandler)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	reporter.AssertNotReported(t)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/broken", nil))
+	reporter.AssertReported(t, 502, "upstream_failed", "/broken")
+	reporter.AssertReported(t, 502, "", "")
+
+	errs := reporter.Errors()
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "POST", errs[0].Method)
+		assert.Equal(t, "502 Bad Gateway", errs[0].Err.Error())
+	}
+
+	rt := &recordingT{}
+	assert.False(t, reporter.AssertReported(rt, 500, "", ""))
+	assert.False(t, reporter.AssertReported(rt, 502, "other", ""))
+	assert.False(t, reporter.AssertNotReported(rt))
+	assert.Len(t, rt.failures, 3)
+
+	reporter.Reset()
+	assert.Empty(t, reporter.Errors())
+}
@@ -0,0 +1,23 @@
+package httperrortest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/johnwarden/httperror"
+)
+
+// Serve calls h.Serve (not ServeHTTP, so the error isn't handled by an error
+// handler) with req, and returns the status code and body written by the
+// handler, and the error it returned. The status code is 0 if the handler
+// didn't write a response. Panics are recovered and returned as errors (see
+// [httperror.PanicMiddleware]).
+//
+//	status, body, err := httperrortest.Serve(h, httptest.NewRequest("GET", "/orders/42", nil))
+func Serve(h httperror.Handler, req *http.Request) (status int, body []byte, err error) {
+	rr := httptest.NewRecorder()
+	rw := httperror.NewResponseWriter(rr)
+
+	err = httperror.PanicMiddleware(h).Serve(rw, req)
+	return rw.Status(), rr.Body.Bytes(), err
+}
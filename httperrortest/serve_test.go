@@ -0,0 +1,48 @@
+package httperrortest_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe(t *testing.T) {
+	h := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/hello":
+			fmt.Fprint(w, "Hello")
+			return nil
+		case "/panic":
+			panic("oops")
+		case "/partial":
+			w.WriteHeader(http.StatusAccepted)
+			return errors.New("connection lost")
+		}
+		return httperror.NotFound
+	})
+
+	s, body, err := httperrortest.Serve(h, httptest.NewRequest("GET", "/hello", nil))
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "Hello", string(body))
+	assert.NoError(t, err)
+
+	s, body, err = httperrortest.Serve(h, httptest.NewRequest("GET", "/missing", nil))
+	assert.Equal(t, 0, s, "the error is not handled")
+	assert.Empty(t, body)
+	assert.Equal(t, httperror.NotFound, err)
+
+	s, _, err = httperrortest.Serve(h, httptest.NewRequest("GET", "/panic", nil))
+	assert.Equal(t, 0, s)
+	assert.True(t, errors.Is(err, httperror.Panic))
+	assert.Equal(t, "panic: oops", err.Error())
+
+	s, _, err = httperrortest.Serve(h, httptest.NewRequest("GET", "/partial", nil))
+	assert.Equal(t, 202, s)
+	assert.EqualError(t, err, "connection lost")
+}
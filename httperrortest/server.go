@@ -0,0 +1,77 @@
+package httperrortest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/johnwarden/httperror"
+)
+
+// Server is an [httptest.Server] whose client returns an error for 4xx and
+// 5xx responses, decoded with [httperror.FromResponse], so that end-to-end
+// tests of error paths are one-liners:
+//
+//	srv := httperrortest.NewServer(h)
+//	defer srv.Close()
+//
+//	_, err := srv.Get("/orders/42")
+//	httperrortest.AssertStatus(t, err, http.StatusNotFound)
+//	httperrortest.AssertPublic(t, err, "no such order")
+//
+// The error is wrapped in a [*url.Error] by the [http.Client], so the
+// extractors in this package, and errors.Is and errors.As, work as usual.
+// [httperror.ToProblem] converts it into a problem details document.
+type Server struct {
+	*httptest.Server
+	client *http.Client
+}
+
+// NewServer starts and returns a new [httperrortest.Server] serving h. The
+// caller should call Close when finished, to shut it down.
+func NewServer(h http.Handler) *Server {
+	s := &Server{Server: httptest.NewServer(h)}
+
+	c := *s.Server.Client()
+	c.Transport = errorTransport{c.Transport}
+	s.client = &c
+	return s
+}
+
+// Client returns an HTTP client configured for making requests to the
+// server, which returns an error for 4xx and 5xx responses, and follows
+// redirects as usual.
+func (s *Server) Client() *http.Client {
+	return s.client
+}
+
+// Get sends a GET request for path (relative to the server URL) with the
+// client of the server.
+func (s *Server) Get(path string) (*http.Response, error) {
+	return s.client.Get(s.URL + path)
+}
+
+// Do sends req with the client of the server.
+func (s *Server) Do(req *http.Request) (*http.Response, error) {
+	return s.client.Do(req)
+}
+
+// errorTransport converts 4xx and 5xx responses into errors. Redirects are
+// passed through, so that the client can follow them.
+type errorTransport struct {
+	next http.RoundTripper
+}
+
+func (t errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+	if err := httperror.FromResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+package httperrortest_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /orders/{id}", httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.PathValue("id") != "42" {
+			return httperror.WithCode(httperror.NewPublic(http.StatusNotFound, "no such order"), "order_not_found")
+		}
+		fmt.Fprint(w, "order 42")
+		return nil
+	}))
+	mux.Handle("GET /latest", http.RedirectHandler("/orders/42", http.StatusFound))
+	mux.Handle("GET /gone", http.RedirectHandler("/orders/43", http.StatusMovedPermanently))
+	mux.Handle("POST /orders", httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NewValidationError(httperror.FieldError{Field: "item", Code: "missing_field"})
+	}, httperror.ProblemErrorHandler))
+
+	srv := httperrortest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := srv.Get("/orders/42")
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "order 42", string(body))
+		resp.Body.Close()
+	}
+
+	resp, err = srv.Get("/latest")
+	if assert.NoError(t, err, "redirect") {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "order 42", string(body))
+		resp.Body.Close()
+	}
+
+	_, err = srv.Get("/gone")
+	httperrortest.AssertStatus(t, err, http.StatusNotFound)
+
+	_, err = srv.Get("/orders/43")
+	httperrortest.AssertStatus(t, err, http.StatusNotFound)
+	httperrortest.AssertPublic(t, err, "no such order")
+	assert.True(t, errors.Is(err, httperror.NotFound))
+
+	_, err = srv.Client().Post(srv.URL+"/orders", "application/json", strings.NewReader("{}"))
+	p := httperror.ToProblem(err)
+	assert.Equal(t, 422, p.Status)
+	assert.Equal(t, []httperror.FieldError{{Field: "item", Code: "missing_field"}}, httperror.FieldErrors(err))
+
+	req, _ := http.NewRequest("DELETE", srv.URL+"/orders/42", nil)
+	_, err = srv.Do(req)
+	httperrortest.AssertStatus(t, err, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,60 @@
+/*
+Package httprouteradapter registers error-returning handlers with an
+httprouter router (github.com/julienschmidt/httprouter). See the
+documentation of the parent package at https://github.com/johnwarden/httperror
+*/
+package httprouteradapter
+
+import (
+	"net/http"
+
+	"github.com/johnwarden/httperror"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Wrap converts an [httperror.XHandlerFunc] that takes the router's URL
+// parameters into an httprouter.Handle. Errors returned by h are handled by
+// eh. If eh is nil, [httperror.DefaultErrorHandler] is used.
+//
+//	router.GET("/orders/:id", httprouteradapter.Wrap(orderHandler, errorHandler))
+func Wrap(h httperror.XHandlerFunc[httprouter.Params], eh httperror.ErrorHandler) httprouter.Handle {
+	if eh == nil {
+		eh = httperror.DefaultErrorHandler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		err := h(w, r, ps)
+		httperror.ReportError(r.Context(), r, err)
+		if err != nil && !httperror.ResponseWritten(err) {
+			eh(w, err)
+		}
+	}
+}
+
+// Configure sets the NotFound, MethodNotAllowed, and PanicHandler handlers
+// of router, so that unmatched routes, unmatched methods, and panics in
+// handlers are rendered by eh as httperror.NotFound,
+// httperror.MethodNotAllowed (with the Allow header set by the router), and
+// panic errors (see [httperror.PanicError]). If eh is nil,
+// [httperror.DefaultErrorHandler] is used.
+func Configure(router *httprouter.Router, eh httperror.ErrorHandler) {
+	if eh == nil {
+		eh = httperror.DefaultErrorHandler
+	}
+
+	handle := func(w http.ResponseWriter, r *http.Request, err error) {
+		httperror.ReportError(r.Context(), r, err)
+		eh(w, err)
+	}
+
+	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(w, r, httperror.NotFound)
+	})
+	router.HandleMethodNotAllowed = true
+	router.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(w, r, httperror.MethodNotAllowed)
+	})
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, recovered interface{}) {
+		handle(w, r, httperror.PanicError(recovered))
+	}
+}
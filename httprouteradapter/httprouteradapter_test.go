@@ -0,0 +1,107 @@
+package httprouteradapter_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/httperrortest"
+	"github.com/johnwarden/httperror/httprouteradapter"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func orderHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	switch id := ps.ByName("id"); id {
+	case "0":
+		return httperror.NotFound
+	case "panic":
+		panic("oops")
+	default:
+		_, _ = w.Write([]byte("order " + id))
+		return nil
+	}
+}
+
+func TestRouter(t *testing.T) {
+	var errs []error
+	eh := func(w http.ResponseWriter, err error) {
+		errs = append(errs, err)
+		w.WriteHeader(httperror.StatusCode(err))
+	}
+
+	router := httprouter.New()
+	httprouteradapter.Configure(router, eh)
+	router.GET("/orders/:id", httprouteradapter.Wrap(orderHandler, eh))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	{
+		rr := serve("GET", "/orders/42")
+		assert.Equal(t, 200, rr.Code)
+		assert.Equal(t, "order 42", rr.Body.String(), "URL parameters are passed to the handler")
+	}
+
+	{
+		rr := serve("GET", "/orders/0")
+		assert.Equal(t, 404, rr.Code)
+	}
+
+	{
+		rr := serve("GET", "/missing")
+		assert.Equal(t, 404, rr.Code)
+	}
+
+	{
+		rr := serve("POST", "/orders/42")
+		assert.Equal(t, 405, rr.Code)
+		assert.Equal(t, "GET, OPTIONS", rr.Header().Get("Allow"))
+	}
+
+	{
+		rr := serve("GET", "/orders/panic")
+		assert.Equal(t, 500, rr.Code)
+	}
+
+	assert.Len(t, errs, 4)
+	assert.Equal(t, httperror.NotFound, errs[0])
+	assert.Equal(t, httperror.NotFound, errs[1])
+	assert.Equal(t, httperror.MethodNotAllowed, errs[2])
+	assert.True(t, errors.Is(errs[3], httperror.Panic), "panics are passed to the error handler")
+}
+
+func TestWrapDefaultErrorHandler(t *testing.T) {
+	router := httprouter.New()
+	router.GET("/orders/:id", httprouteradapter.Wrap(orderHandler, nil))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/orders/0", nil))
+	assert.Equal(t, 404, rr.Code)
+}
+
+func TestReportError(t *testing.T) {
+	var rep httperrortest.Reporter
+	httperror.RegisterErrorReporter(&rep, func(err error) bool { return true })
+
+	router := httprouter.New()
+	httprouteradapter.Configure(router, nil)
+	router.GET("/orders/:id", httprouteradapter.Wrap(orderHandler, nil))
+
+	for _, path := range []string{"/orders/0", "/missing", "/orders/panic"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders/42", nil))
+
+	errs := rep.Errors()
+	if assert.Len(t, errs, 4) {
+		assert.Equal(t, []int{404, 404, 500, 405}, []int{errs[0].Status, errs[1].Status, errs[2].Status, errs[3].Status})
+		assert.Equal(t, "/missing", errs[1].Path)
+	}
+}
@@ -0,0 +1,304 @@
+package httperror
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord is the state of a request with an Idempotency-Key, as
+// kept by an [httperror.IdempotencyStore].
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request (its method, URI and body), to
+	// detect keys reused for different requests.
+	Fingerprint string
+
+	// Done is false while the request is being processed.
+	Done bool
+
+	// Status, Header and Body are the response, once Done.
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// maxIdempotencyBodyBytes is the maximum size of the body of requests with
+// an Idempotency-Key, which [httperror.IdempotencyMiddleware] reads into
+// memory to fingerprint the request.
+const maxIdempotencyBodyBytes = 1 << 20
+
+// IdempotencyStore keeps the requests processed by
+// [httperror.IdempotencyMiddleware].
+type IdempotencyStore interface {
+	// Start creates an in-progress record for key with the given
+	// fingerprint, unless there already is a record for key, in which case
+	// it returns that record and false.
+	Start(key, fingerprint string) (existing IdempotencyRecord, created bool, err error)
+
+	// Finish stores the response for key.
+	Finish(key string, rec IdempotencyRecord) error
+
+	// Abort deletes the record for key, so that the request can be retried.
+	Abort(key string) error
+}
+
+// IdempotencyMiddleware returns an [httperror.Middleware] that makes POST
+// and PATCH requests with an Idempotency-Key header safe to retry: the
+// response to the first request with a key is stored, and replayed for
+// later requests with the same key, with an Idempotent-Replayed header.
+// Requests without the header are served normally. Keys are scoped by the
+// value of scope, such as the API key of the client (see
+// [httperror.KeyByHeader]), unless scope is nil.
+//
+// Instead of writing a response, conflicting requests return errors with a
+// public message and a machine-readable code (see [httperror.ErrorCode]):
+//
+//   - Conflict, with the code "idempotency_key_in_use", while the first
+//     request with the key is still being processed
+//   - UnprocessableEntity, with the code "idempotency_key_reused", if the
+//     key was used for a request with a different method, URI or body. The
+//     fingerprint of the original request can be extracted with
+//     [httperror.IdempotencyFingerprint], and is written in the details of
+//     JSON and problem+json error responses (see
+//     [httperror.DefaultErrorHandler] and [httperror.ToProblem]).
+//
+// The request body is read to fingerprint the request. Bodies larger than
+// 1 MiB return RequestEntityTooLarge.
+//
+// Only successful responses are stored: if the handler returns an error,
+// panics, or writes a server error (5xx), the key is released, so that the
+// client can retry.
+func IdempotencyMiddleware(store IdempotencyStore, scope Keyer) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				return h.Serve(w, r)
+			}
+			if scope != nil {
+				key = scope(r) + "\x00" + key
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotencyBodyBytes))
+			if err != nil {
+				var mbe *http.MaxBytesError
+				if errors.As(err, &mbe) {
+					return Wrap(err, http.StatusRequestEntityTooLarge)
+				}
+				return err
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := requestFingerprint(r, body)
+			existing, created, err := store.Start(key, fingerprint)
+			if err != nil {
+				return err
+			}
+
+			if !created {
+				switch {
+				case existing.Fingerprint != fingerprint:
+					return idempotencyError{
+						WithCode(NewPublic(http.StatusUnprocessableEntity, "this idempotency key was already used for a different request"), "idempotency_key_reused"),
+						existing.Fingerprint,
+					}
+				case !existing.Done:
+					return WithCode(NewPublic(http.StatusConflict, "a request with this idempotency key is being processed"), "idempotency_key_in_use")
+				}
+
+				for k, vs := range existing.Header {
+					w.Header()[k] = vs
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(existing.Status)
+				_, err := w.Write(existing.Body)
+				return err
+			}
+
+			finished := false
+			defer func() {
+				if !finished {
+					_ = store.Abort(key)
+				}
+			}()
+
+			rec := &recordingWriter{ResponseWriter: w}
+			err = h.Serve(rec, r)
+			if err != nil || rec.status >= 500 {
+				return err
+			}
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			finished = true
+			return store.Finish(key, IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Done:        true,
+				Status:      status,
+				Header:      w.Header().Clone(),
+				Body:        rec.body.Bytes(),
+			})
+		})
+	}
+}
+
+// IdempotencyFingerprint returns the fingerprint of the original request,
+// for errors returned by [httperror.IdempotencyMiddleware] when an
+// idempotency key is reused for a different request, or "".
+func IdempotencyFingerprint(err error) string {
+	var ie idempotencyError
+	if errors.As(err, &ie) {
+		return ie.fingerprint
+	}
+	return ""
+}
+
+type idempotencyError struct {
+	inner       error
+	fingerprint string
+}
+
+func (e idempotencyError) Error() string {
+	return e.inner.Error()
+}
+
+func (e idempotencyError) Unwrap() error {
+	return e.inner
+}
+
+// requestFingerprint returns a hash of the method, URI and body of r.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter is an http.ResponseWriter that keeps a copy of the
+// status code and body written through it.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(s int) {
+	if rw.status == 0 && s >= 200 {
+		rw.status = s
+	}
+	rw.ResponseWriter.WriteHeader(s)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying ResponseWriter, if it supports it.
+func (rw *recordingWriter) Flush() {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	flush(rw.ResponseWriter)
+}
+
+// ReadFrom copies from src to the response, using the ReadFrom method of
+// the underlying ResponseWriter if it has one (see [io.ReaderFrom]), and
+// keeps a copy of the body.
+func (rw *recordingWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	return readFrom(rw.ResponseWriter, io.TeeReader(src, &rw.body))
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// [http.ResponseController].
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// MemoryIdempotencyStore is an in-memory [httperror.IdempotencyStore], for
+// single-instance deployments and tests. Records expire after a TTL.
+type MemoryIdempotencyStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	records   map[string]memoryIdempotencyRecord
+	lastSweep time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	IdempotencyRecord
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore returns a [httperror.MemoryIdempotencyStore]
+// whose records expire after ttl.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:       ttl,
+		records:   make(map[string]memoryIdempotencyRecord),
+		lastSweep: time.Now(),
+	}
+}
+
+// Start implements [httperror.IdempotencyStore].
+func (s *MemoryIdempotencyStore) Start(key, fingerprint string) (IdempotencyRecord, bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[key]; ok && now.Before(rec.expires) {
+		return rec.IdempotencyRecord, false, nil
+	}
+
+	s.sweep(now)
+
+	s.records[key] = memoryIdempotencyRecord{IdempotencyRecord{Fingerprint: fingerprint}, now.Add(s.ttl)}
+	return IdempotencyRecord{}, true, nil
+}
+
+// Finish implements [httperror.IdempotencyStore].
+func (s *MemoryIdempotencyStore) Finish(key string, rec IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = memoryIdempotencyRecord{rec, time.Now().Add(s.ttl)}
+	return nil
+}
+
+// Abort implements [httperror.IdempotencyStore].
+func (s *MemoryIdempotencyStore) Abort(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep removes expired records, at most once per TTL, to keep memory
+// bounded by the number of recently used keys.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+
+	for key, rec := range s.records {
+		if !now.Before(rec.expires) {
+			delete(s.records, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,121 @@
+package httperror_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var calls int
+	started, release := make(chan struct{}), make(chan struct{})
+	h := httperror.IdempotencyMiddleware(httperror.NewMemoryIdempotencyStore(time.Hour), httperror.KeyByHeader("X-Api-Key"))(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		switch string(body) {
+		case "fail":
+			return httperror.InternalServerError
+		case "slow":
+			close(started)
+			<-release
+		case "stream":
+			w.(http.Flusher).Flush()
+			_, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("streamed order"))
+			return err
+		}
+		w.Header().Set("Location", "/orders/1")
+		w.WriteHeader(http.StatusCreated)
+		_, err := fmt.Fprintf(w, "order %d", calls)
+		return err
+	}))
+
+	post := func(key, apiKey, body string) (*httptest.ResponseRecorder, error) {
+		r := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+		r.Header.Set("Idempotency-Key", key)
+		r.Header.Set("X-Api-Key", apiKey)
+		rr := httptest.NewRecorder()
+		return rr, h.Serve(rr, r)
+	}
+
+	{
+		rr, err := post("k1", "alice", "widget")
+		assert.NoError(t, err)
+		assert.Equal(t, 201, rr.Code)
+		assert.Equal(t, "order 1", rr.Body.String())
+	}
+
+	{
+		rr, err := post("k1", "alice", "widget")
+		assert.NoError(t, err)
+		assert.Equal(t, 201, rr.Code)
+		assert.Equal(t, "order 1", rr.Body.String(), "the response is replayed")
+		assert.Equal(t, "/orders/1", rr.Header().Get("Location"))
+		assert.Equal(t, "true", rr.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 1, calls)
+	}
+
+	{
+		_, err := post("k1", "alice", "gadget")
+		assert.Equal(t, 422, httperror.StatusCode(err))
+		assert.Equal(t, "idempotency_key_reused", httperror.ErrorCode(err))
+		fingerprint := httperror.IdempotencyFingerprint(err)
+		assert.Len(t, fingerprint, 64)
+
+		rr := httptest.NewRecorder()
+		rr.Header().Set("Content-Type", "application/json")
+		httperror.DefaultErrorHandler(rr, err)
+		assert.Contains(t, rr.Body.String(), `"data":{"code":"idempotency_key_reused","fingerprint":"`+fingerprint+`"}`)
+		assert.Equal(t, fingerprint, httperror.ToProblem(err).Extensions["fingerprint"])
+	}
+
+	{
+		rr, err := post("k4", "alice", strings.Repeat("x", 1<<20+1))
+		assert.Equal(t, 413, httperror.StatusCode(err))
+		assert.Equal(t, 0, rr.Body.Len())
+		assert.Equal(t, 1, calls, "the handler isn't called")
+	}
+
+	{
+		rr, err := post("k1", "bob", "gadget")
+		assert.NoError(t, err, "keys are scoped")
+		assert.Equal(t, "order 2", rr.Body.String())
+	}
+
+	{
+		_, err := post("k2", "alice", "fail")
+		assert.Equal(t, 500, httperror.StatusCode(err))
+		_, err = post("k2", "alice", "fail")
+		assert.Equal(t, 500, httperror.StatusCode(err), "failed requests release the key")
+		assert.Equal(t, 4, calls)
+	}
+
+	{
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = post("k3", "alice", "slow")
+		}()
+		<-started
+		_, err := post("k3", "alice", "slow")
+		assert.Equal(t, 409, httperror.StatusCode(err))
+		assert.Equal(t, "idempotency_key_in_use", httperror.ErrorCode(err))
+		close(release)
+		<-done
+	}
+
+	{
+		rr, err := post("k5", "alice", "stream")
+		assert.NoError(t, err)
+		assert.True(t, rr.Flushed, "Flush is passed through")
+		rr, _ = post("k5", "alice", "stream")
+		assert.Equal(t, "streamed order", rr.Body.String(), "the response written with ReadFrom is replayed")
+	}
+}
@@ -0,0 +1,171 @@
+package httperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// FromJSONError converts an error returned when decoding a JSON request
+// body with encoding/json into a client error with a precise public
+// message, so that malformed requests are not reported as server errors:
+//
+//   - *json.SyntaxError: BadRequest, with the offset of the error
+//   - *json.UnmarshalTypeError: BadRequest, with the field and the expected
+//     JSON type
+//   - unknown fields (see json.Decoder.DisallowUnknownFields): BadRequest,
+//     with the field
+//   - io.ErrUnexpectedEOF: BadRequest
+//   - io.EOF (an empty body): BadRequest
+//   - *http.MaxBytesError: RequestEntityTooLarge
+//
+// The original error remains in the chain. Other errors, and errors that
+// already have a status code, are returned unchanged.
+//
+//	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+//		return httperror.FromJSONError(err)
+//	}
+func FromJSONError(err error) error {
+	if err == nil || HasStatusCode(err) {
+		return err
+	}
+
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return wrapPublic(err, http.StatusBadRequest, fmt.Sprintf("malformed JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error()))
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return wrapPublic(err, http.StatusBadRequest, fmt.Sprintf("invalid JSON value: expected %s, got %s", jsonTypeName(typeErr.Type), typeErr.Value))
+		}
+		return wrapPublic(err, http.StatusBadRequest, fmt.Sprintf("invalid value for field %q: expected %s, got %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value))
+	case errors.As(err, &maxBytesErr):
+		return wrapPublic(err, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body larger than %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return wrapPublic(err, http.StatusBadRequest, "unexpected end of JSON input")
+	case errors.Is(err, io.EOF):
+		return wrapPublic(err, http.StatusBadRequest, "empty request body")
+	}
+
+	// encoding/json doesn't export a type for unknown field errors.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return wrapPublic(err, http.StatusBadRequest, "unknown field "+field)
+	}
+
+	return err
+}
+
+// jsonTypeName returns the name of the JSON type that decodes into t.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	}
+	return t.String()
+}
+
+// defaultMaxBodyBytes is the default size limit of [httperror.DecodeJSON].
+const defaultMaxBodyBytes = 1 << 20
+
+// DecodeOption configures [httperror.DecodeJSON].
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	maxBytes           int64
+	allowUnknownFields bool
+}
+
+// MaxBodyBytes sets the maximum size of the request body decoded by
+// [httperror.DecodeJSON]. The default is 1 MiB.
+func MaxBodyBytes(n int64) DecodeOption {
+	return func(o *decodeOptions) {
+		o.maxBytes = n
+	}
+}
+
+// AllowUnknownFields makes [httperror.DecodeJSON] ignore object fields that
+// don't match a field of the destination, instead of rejecting them.
+func AllowUnknownFields() DecodeOption {
+	return func(o *decodeOptions) {
+		o.allowUnknownFields = true
+	}
+}
+
+// DecodeJSON decodes the JSON body of r into dst, returning errors that
+// are ready to be returned by a handler:
+//
+//   - UnsupportedMediaType if the Content-Type is not application/json (or
+//     another JSON media type, such as application/merge-patch+json)
+//
+//   - RequestEntityTooLarge if the body is larger than the limit (see
+//     [httperror.MaxBodyBytes])
+//
+//   - BadRequest, with a public message, if the body is malformed, has the
+//     wrong types, has unknown fields (unless
+//     [httperror.AllowUnknownFields] is used), or contains more than one
+//     JSON value (see [httperror.FromJSONError])
+//
+// Handlers return the error directly:
+//
+//	var order Order
+//	if err := httperror.DecodeJSON(r, &order); err != nil {
+//		return err
+//	}
+func DecodeJSON(r *http.Request, dst interface{}, opts ...DecodeOption) error {
+	o := decodeOptions{maxBytes: defaultMaxBodyBytes}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if !isJSONMediaType(r.Header.Get("Content-Type")) {
+		return NewPublic(http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+	}
+
+	d := json.NewDecoder(http.MaxBytesReader(nil, r.Body, o.maxBytes))
+	if !o.allowUnknownFields {
+		d.DisallowUnknownFields()
+	}
+
+	if err := d.Decode(dst); err != nil {
+		return FromJSONError(err)
+	}
+
+	if _, err := d.Token(); err != io.EOF {
+		if err != nil {
+			return FromJSONError(err)
+		}
+		return NewPublic(http.StatusBadRequest, "request body must contain a single JSON value")
+	}
+	return nil
+}
+
+// isJSONMediaType reports whether a Content-Type header value is a JSON
+// media type.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,121 @@
+package httperror
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// appendJsonErrorBody appends a JSON error response body, followed by a
+// newline, to dst:
+//
+//	{"status":"error","message":"...","code":404,"data":{"code":"...","errors":[...],"fingerprint":"..."}}
+//
+// The message, code and data members, and the members of data, are omitted
+// if empty. The output is identical to that of a json.Encoder, without the
+// reflection and allocations, since it is written for every JSON error
+// response.
+func appendJsonErrorBody(dst []byte, s int, m []byte, data *jsonErrorData) []byte {
+	dst = append(dst, `{"status":"error"`...)
+	if len(m) > 0 {
+		dst = append(dst, `,"message":`...)
+		dst = appendJSONString(dst, m)
+	}
+	if s != 0 {
+		dst = append(dst, `,"code":`...)
+		dst = strconv.AppendInt(dst, int64(s), 10)
+	}
+	if data != nil {
+		dst = append(dst, `,"data":{`...)
+		dst = appendJSONMember(dst, "code", data.Code)
+		if len(data.Errors) > 0 {
+			dst = appendJSONSeparator(dst)
+			dst = append(dst, `"errors":[`...)
+			for i, fe := range data.Errors {
+				if i > 0 {
+					dst = append(dst, ',')
+				}
+				dst = append(dst, '{')
+				dst = appendJSONMember(dst, "resource", fe.Resource)
+				dst = appendJSONMember(dst, "field", fe.Field)
+				dst = appendJSONMember(dst, "code", fe.Code)
+				dst = appendJSONMember(dst, "message", fe.Message)
+				dst = append(dst, '}')
+			}
+			dst = append(dst, ']')
+		}
+		dst = appendJSONMember(dst, "fingerprint", data.Fingerprint)
+		dst = append(dst, '}')
+	}
+	return append(dst, "}\n"...)
+}
+
+// appendJSONMember appends a string member of a JSON object, omitting it if
+// the value is empty (like the omitempty struct tag option). The name must
+// not need escaping.
+func appendJSONMember(dst []byte, name, value string) []byte {
+	if value == "" {
+		return dst
+	}
+	dst = appendJSONSeparator(dst)
+	dst = append(dst, '"')
+	dst = append(dst, name...)
+	dst = append(dst, `":`...)
+	return appendJSONString(dst, value)
+}
+
+// appendJSONSeparator appends a comma, unless dst ends with the start of
+// an object.
+func appendJSONSeparator(dst []byte) []byte {
+	if len(dst) > 0 && dst[len(dst)-1] != '{' {
+		dst = append(dst, ',')
+	}
+	return dst
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a quoted JSON string, escaped like
+// encoding/json does by default: control characters, <, >, and & (so that
+// the JSON can be embedded in HTML), U+2028 and U+2029 (so that it can be
+// embedded in JavaScript), and invalid UTF-8, which is replaced by U+FFFD.
+func appendJSONString[S string | []byte](dst []byte, s S) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&':
+				dst = append(dst, c)
+			case c == '"' || c == '\\':
+				dst = append(dst, '\\', c)
+			case c == '\b':
+				dst = append(dst, '\\', 'b')
+			case c == '\f':
+				dst = append(dst, '\\', 'f')
+			case c == '\n':
+				dst = append(dst, '\\', 'n')
+			case c == '\r':
+				dst = append(dst, '\\', 'r')
+			case c == '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			continue
+		}
+
+		var buf [utf8.UTFMax]byte
+		r, size := utf8.DecodeRune(buf[:copy(buf[:], s[i:])])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
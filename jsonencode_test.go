@@ -0,0 +1,77 @@
+package httperror_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONErrorBody(t *testing.T) {
+	type data struct {
+		Code   string                 `json:"code,omitempty"`
+		Errors []httperror.FieldError `json:"errors,omitempty"`
+	}
+	type body struct {
+		Status  string `json:"status"`
+		Message string `json:"message,omitempty"`
+		Code    int    `json:"code,omitempty"`
+		Data    *data  `json:"data,omitempty"`
+	}
+	encode := func(b body) string {
+		var buf bytes.Buffer
+		_ = json.NewEncoder(&buf).Encode(b)
+		return buf.String()
+	}
+
+	for _, m := range []string{
+		"",
+		"missing 'name' parameter",
+		`quotes " and backslashes \\`,
+		"<script>alert(1) && 2</script>",
+		"control\b\f\n\r\t\x00\x1f\x7f characters",
+		"line\u2028and paragraph\u2029separators",
+		"unicode: h\u00e9llo, \u4e16\u754c, \U0001F600",
+	} {
+		rr := httptest.NewRecorder()
+		rr.Header().Set("Content-Type", "application/json")
+		httperror.WriteResponse(rr, http.StatusBadRequest, []byte(m))
+		assert.Equal(t, encode(body{Status: "error", Message: m, Code: http.StatusBadRequest}), rr.Body.String(), "%q", m)
+	}
+
+	// Versions of encoding/json replace invalid UTF-8 differently, so only
+	// the decoded message is compared.
+	{
+		m := "invalid \xff\xfe UTF-8 \xe2\x82"
+		rr := httptest.NewRecorder()
+		rr.Header().Set("Content-Type", "application/json")
+		httperror.WriteResponse(rr, http.StatusBadRequest, []byte(m))
+
+		var expected, actual body
+		assert.NoError(t, json.Unmarshal([]byte(encode(body{Status: "error", Message: m, Code: http.StatusBadRequest})), &expected))
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &actual))
+		assert.Equal(t, expected, actual)
+	}
+
+	fieldErrors := []httperror.FieldError{
+		{Field: "email", Code: "invalid", Message: "must contain an <@>"},
+		{Resource: "order", Field: "items", Code: "missing_field"},
+		{},
+	}
+	err := httperror.WithCode(httperror.NewValidationError(fieldErrors...), "invalid_order")
+
+	rr := httptest.NewRecorder()
+	rr.Header().Set("Content-Type", "application/json")
+	httperror.DefaultErrorHandler(rr, err)
+	assert.Equal(t, encode(body{
+		Status:  "error",
+		Message: http.StatusText(httperror.StatusCode(err)),
+		Code:    httperror.StatusCode(err),
+		Data:    &data{Code: "invalid_order", Errors: fieldErrors},
+	}), rr.Body.String())
+}
@@ -0,0 +1,135 @@
+package httperror
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const contentTypeJSONRPC = "application/json-rpc"
+
+// Standard JSON-RPC 2.0 error codes, as defined in
+// https://www.jsonrpc.org/specification#error_object
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+	JSONRPCServerError    = -32000
+)
+
+// JSONRPCError is a JSON-RPC 2.0 error object.
+type JSONRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// jsonRPCErrorData is the data member of JSON-RPC error objects created by
+// this package. It preserves the HTTP status and error code so that
+// [httperror.FromJSONRPCError] can reconstruct the original error.
+type jsonRPCErrorData struct {
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+}
+
+// ToJSONRPCError converts an error into a JSON-RPC 2.0 error object. The
+// JSON-RPC error code is derived from the HTTP status code of the error (for
+// example, 400 Bad Request maps to Invalid params, 501 Not Implemented to
+// Method not found, and other statuses such as 404 Not Found to Server
+// error). The message is the public message (see
+// [httperror.PublicMessage]) or the status text if there is none. The data
+// member holds the HTTP status code and the error code (see
+// [httperror.ErrorCode]).
+func ToJSONRPCError(err error) JSONRPCError {
+	s := StatusCode(err)
+
+	e := JSONRPCError{
+		Code:    jsonRPCCode(s),
+		Message: PublicMessage(err),
+		Data:    jsonRPCErrorData{s, ErrorCode(err)},
+	}
+	if e.Message == "" {
+		e.Message = statusText(s)
+	}
+	return e
+}
+
+// FromJSONRPCError converts a JSON-RPC 2.0 error object into an error with
+// an embedded HTTP status code. If the data member contains the status and
+// code added by [httperror.ToJSONRPCError], they are restored. Otherwise the
+// status is derived from the JSON-RPC error code. The message becomes the
+// public message of the returned error.
+func FromJSONRPCError(e JSONRPCError) error {
+	s, code := jsonRPCStatus(e.Code), ""
+
+	switch data := e.Data.(type) {
+	case jsonRPCErrorData:
+		s, code = data.Status, data.Code
+	case map[string]interface{}:
+		if status, ok := data["status"].(float64); ok && status >= 400 && status < 600 {
+			s = int(status)
+		}
+		code, _ = data["code"].(string)
+	}
+
+	err := NewPublic(s, e.Message)
+	if code != "" {
+		err = WithCode(err, code)
+	}
+	return err
+}
+
+// WriteJSONRPCError writes a JSON-RPC 2.0 error response for the request with
+// the given id, with Content-Type application/json-rpc and the HTTP status
+// code of the error. A nil id is written as null, as required by the
+// specification when the id of the request could not be determined.
+func WriteJSONRPCError(w http.ResponseWriter, id json.RawMessage, err error) {
+	response := jsonRPCResponse{
+		JSONRPC: "2.0",
+		Error:   ToJSONRPCError(err),
+		ID:      id,
+	}
+	if response.ID == nil {
+		response.ID = json.RawMessage("null")
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSONRPC)
+	setErrorHeaders(w, err)
+	w.WriteHeader(StatusCode(err))
+
+	json, _ := json.Marshal(response) // No error handling for error handling
+
+	_, _ = w.Write(json)
+	_, _ = w.Write([]byte("\n"))
+}
+
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Error   JSONRPCError    `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func jsonRPCCode(s int) int {
+	switch s {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return JSONRPCInvalidParams
+	case http.StatusNotImplemented:
+		// A 404 or 405 usually means a missing resource, not a missing
+		// RPC method, so only 501 maps to Method not found.
+		return JSONRPCMethodNotFound
+	case http.StatusInternalServerError:
+		return JSONRPCInternalError
+	}
+	return JSONRPCServerError
+}
+
+func jsonRPCStatus(code int) int {
+	switch code {
+	case JSONRPCParseError, JSONRPCInvalidRequest, JSONRPCInvalidParams:
+		return http.StatusBadRequest
+	case JSONRPCMethodNotFound:
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,101 @@
+/*
+Package jwterror converts JSON Web Token validation errors from
+github.com/golang-jwt/jwt/v5 into httperrors. See the documentation of the
+parent package at https://github.com/johnwarden/httperror
+*/
+package jwterror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/johnwarden/httperror"
+)
+
+// mapping describes the httperror for a class of JWT validation errors.
+type mapping struct {
+	targets []error
+	status  int
+	code    string
+	message string
+}
+
+// mappings are checked in order. The more specific claim errors come before
+// the generic ones that they also match.
+var mappings = []mapping{
+	{[]error{jwt.ErrTokenExpired}, http.StatusUnauthorized, "token_expired", "token has expired"},
+	{[]error{jwt.ErrTokenNotValidYet, jwt.ErrTokenUsedBeforeIssued}, http.StatusUnauthorized, "token_not_yet_valid", "token is not valid yet"},
+	{[]error{jwt.ErrTokenSignatureInvalid, jwt.ErrTokenUnverifiable}, http.StatusUnauthorized, "invalid_signature", "token signature is invalid"},
+	{[]error{jwt.ErrTokenMalformed}, http.StatusUnauthorized, "malformed_token", "token is malformed"},
+	{[]error{jwt.ErrTokenRequiredClaimMissing}, http.StatusUnauthorized, "missing_claim", "token is missing a required claim"},
+	{[]error{jwt.ErrTokenInvalidAudience}, http.StatusForbidden, "invalid_audience", "token is not valid for this audience"},
+	{[]error{jwt.ErrTokenInvalidIssuer}, http.StatusForbidden, "invalid_issuer", "token issuer is not trusted"},
+	{[]error{jwt.ErrTokenInvalidSubject, jwt.ErrTokenInvalidId, jwt.ErrTokenInvalidClaims}, http.StatusUnauthorized, "invalid_claims", "token claims are invalid"},
+}
+
+// keyErrors are caused by a misconfigured verification key rather than a
+// bad token, so they are left as server errors even though jwt wraps them
+// with ErrTokenSignatureInvalid.
+var keyErrors = []error{jwt.ErrInvalidKey, jwt.ErrInvalidKeyType}
+
+// Map converts an error returned by jwt.Parse (or a similar function) into
+// an httperror with a status code, a machine-readable code (see
+// [httperror.ErrorCode]), and a safe public message (see
+// [httperror.PublicMessage]):
+//
+//   - expired, not yet valid, bad signature, malformed, and missing or
+//     invalid claims: 401 Unauthorized, with the codes "token_expired",
+//     "token_not_yet_valid", "invalid_signature", "malformed_token",
+//     "missing_claim", and "invalid_claims"
+//   - wrong audience or issuer: 403 Forbidden, with the codes
+//     "invalid_audience" and "invalid_issuer"
+//
+// The original error is wrapped, so errors.Is still matches the jwt
+// errors. Errors caused by an invalid or wrong type of key (jwt.ErrInvalidKey
+// and jwt.ErrInvalidKeyType) are a server misconfiguration, and are returned
+// unchanged like other errors and errors that already have a status code
+// (see [httperror.HasStatusCode]), including an explicit 500.
+func Map(err error) error {
+	if err == nil || httperror.HasStatusCode(err) {
+		return err
+	}
+
+	for _, target := range keyErrors {
+		if errors.Is(err, target) {
+			return err
+		}
+	}
+
+	for _, m := range mappings {
+		for _, target := range m.targets {
+			if errors.Is(err, target) {
+				return httperror.WithCode(publicError{httperror.Wrap(err, m.status), m.message}, m.code)
+			}
+		}
+	}
+	return err
+}
+
+// Middleware wraps h, converting the JWT validation errors it returns with
+// [Map], so that they are handled as 401 or 403 errors instead of generic
+// 500 errors.
+func Middleware(h httperror.Handler) httperror.Handler {
+	return httperror.ErrorFilter(h, func(r *http.Request, err error) error {
+		return Map(err)
+	})
+}
+
+// publicError attaches a public message to an error.
+type publicError struct {
+	error
+	message string
+}
+
+func (e publicError) Unwrap() error {
+	return e.error
+}
+
+func (e publicError) PublicMessage() string {
+	return e.message
+}
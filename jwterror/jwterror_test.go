@@ -0,0 +1,82 @@
+package jwterror_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/jwterror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var key = []byte("secret")
+
+func parse(claims jwt.MapClaims, opts ...jwt.ParserOption) error {
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		panic(err)
+	}
+	_, err = jwt.Parse(s, func(*jwt.Token) (interface{}, error) { return key, nil }, opts...)
+	return err
+}
+
+func TestMap(t *testing.T) {
+	now := time.Now()
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"expired", parse(jwt.MapClaims{"exp": now.Add(-time.Hour).Unix()}), 401, "token_expired"},
+		{"not valid yet", parse(jwt.MapClaims{"nbf": now.Add(time.Hour).Unix()}), 401, "token_not_yet_valid"},
+		{"audience", parse(jwt.MapClaims{"aud": "other"}, jwt.WithAudience("api")), 403, "invalid_audience"},
+		{"missing claim", parse(jwt.MapClaims{}, jwt.WithExpirationRequired()), 401, "missing_claim"},
+		{"malformed", func() error { _, err := jwt.Parse("garbage", nil); return err }(), 401, "malformed_token"},
+	} {
+		err := jwterror.Map(tc.err)
+		assert.Equal(t, tc.status, httperror.StatusCode(err), tc.name)
+		assert.Equal(t, tc.code, httperror.ErrorCode(err), tc.name)
+		assert.NotEmpty(t, httperror.PublicMessage(err), tc.name)
+		assert.True(t, errors.Is(err, tc.err), "original error is wrapped")
+	}
+
+	{
+		s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{}).SignedString([]byte("other"))
+		_, err := jwt.Parse(s, func(*jwt.Token) (interface{}, error) { return key, nil })
+		assert.Equal(t, "invalid_signature", httperror.ErrorCode(jwterror.Map(err)))
+	}
+
+	{
+		s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{}).SignedString(key)
+		_, err := jwt.Parse(s, func(*jwt.Token) (interface{}, error) { return "not a []byte", nil })
+		assert.True(t, errors.Is(err, jwt.ErrInvalidKeyType))
+		assert.Equal(t, 500, httperror.StatusCode(jwterror.Map(err)), "misconfigured key is a server error")
+	}
+
+	assert.Nil(t, jwterror.Map(nil))
+
+	other := errors.New("database down")
+	assert.Equal(t, other, jwterror.Map(other), "other errors are unchanged")
+	assert.Equal(t, httperror.NotFound, jwterror.Map(httperror.NotFound))
+
+	explicit := httperror.Wrap(parse(jwt.MapClaims{"exp": now.Add(-time.Hour).Unix()}), http.StatusInternalServerError)
+	assert.Equal(t, explicit, jwterror.Map(explicit), "errors with an explicit 500 are unchanged")
+}
+
+func TestMiddleware(t *testing.T) {
+	h := jwterror.Middleware(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return parse(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})
+	}))
+
+	rr := httptest.NewRecorder()
+	httperror.WrapHandler(h, nil).ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 401, rr.Code)
+	assert.Contains(t, rr.Body.String(), "token has expired", "public message is rendered")
+}
@@ -0,0 +1,117 @@
+package httperror
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// KubernetesErrorHandler is an [httperror.ErrorHandler] that writes a
+// Kubernetes-style Status object (metav1.Status), as returned by the
+// Kubernetes API server, aggregated API servers, and admission webhooks:
+//
+//	{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"...","reason":"NotFound","code":404}
+//
+// The reason is extracted using [httperror.ErrorCode], or derived from the
+// status code if the error has no code. The message is the public message
+// (see [httperror.PublicMessage]) or the status text if there is none.
+// Field errors (see [httperror.NewValidationError]) are listed as causes in
+// the details member.
+func KubernetesErrorHandler(w http.ResponseWriter, e error) {
+	s := StatusCode(e)
+
+	body := kubernetesStatus{
+		Kind:       "Status",
+		APIVersion: "v1",
+		Metadata:   struct{}{},
+		Status:     "Failure",
+		Message:    PublicMessage(e),
+		Reason:     ErrorCode(e),
+		Code:       s,
+	}
+	if body.Message == "" {
+		body.Message = statusText(s)
+	}
+	if body.Reason == "" {
+		body.Reason = kubernetesReason(s)
+	}
+
+	if fieldErrors := FieldErrors(e); len(fieldErrors) > 0 {
+		body.Details = &kubernetesStatusDetails{}
+		for _, f := range fieldErrors {
+			body.Details.Causes = append(body.Details.Causes, kubernetesStatusCause{
+				Type:    f.Code,
+				Message: f.Message,
+				Field:   f.Field,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	setErrorHeaders(w, e)
+	w.WriteHeader(s)
+
+	json, _ := json.Marshal(body) // No error handling for error handling
+
+	_, _ = w.Write(json)
+	_, _ = w.Write([]byte("\n"))
+}
+
+type kubernetesStatus struct {
+	Kind       string                   `json:"kind"`
+	APIVersion string                   `json:"apiVersion"`
+	Metadata   struct{}                 `json:"metadata"`
+	Status     string                   `json:"status"`
+	Message    string                   `json:"message,omitempty"`
+	Reason     string                   `json:"reason,omitempty"`
+	Details    *kubernetesStatusDetails `json:"details,omitempty"`
+	Code       int                      `json:"code"`
+}
+
+type kubernetesStatusDetails struct {
+	Causes []kubernetesStatusCause `json:"causes,omitempty"`
+}
+
+type kubernetesStatusCause struct {
+	Type    string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Field   string `json:"field,omitempty"`
+}
+
+// kubernetesReason maps an HTTP status code to the corresponding
+// metav1.StatusReason. Returns the empty string (StatusReasonUnknown) for
+// status codes without a specific reason.
+func kubernetesReason(s int) string {
+	switch s {
+	case http.StatusBadRequest:
+		return "BadRequest"
+	case http.StatusUnauthorized:
+		return "Unauthorized"
+	case http.StatusForbidden:
+		return "Forbidden"
+	case http.StatusNotFound:
+		return "NotFound"
+	case http.StatusMethodNotAllowed:
+		return "MethodNotAllowed"
+	case http.StatusNotAcceptable:
+		return "NotAcceptable"
+	case http.StatusConflict:
+		return "Conflict"
+	case http.StatusGone:
+		return "Gone"
+	case http.StatusRequestEntityTooLarge:
+		return "RequestEntityTooLarge"
+	case http.StatusUnsupportedMediaType:
+		return "UnsupportedMediaType"
+	case http.StatusUnprocessableEntity:
+		return "Invalid"
+	case http.StatusTooManyRequests:
+		return "TooManyRequests"
+	case http.StatusInternalServerError:
+		return "InternalError"
+	case http.StatusServiceUnavailable:
+		return "ServiceUnavailable"
+	case http.StatusGatewayTimeout:
+		return "Timeout"
+	}
+	return ""
+}
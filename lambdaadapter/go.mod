@@ -0,0 +1,15 @@
+module github.com/johnwarden/httperror/lambdaadapter
+
+go 1.22
+
+require (
+	github.com/aws/aws-lambda-go v1.47.0
+	github.com/johnwarden/httperror v0.0.0-20261016035624-08a2347946c7
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
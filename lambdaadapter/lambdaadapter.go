@@ -0,0 +1,199 @@
+/*
+Package lambdaadapter runs httperror handlers on AWS Lambda behind API
+Gateway (REST API proxy integrations), using the event types of
+github.com/aws/aws-lambda-go. See the documentation of the parent package
+at https://github.com/johnwarden/httperror
+*/
+package lambdaadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/johnwarden/httperror"
+)
+
+// HandlerFunc is a Lambda handler for API Gateway proxy requests. It can be
+// passed to lambda.Start.
+type HandlerFunc = func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// Wrap wraps a Lambda handler so that the errors it returns are converted
+// into proxy responses with eh, with the status code of the error (see
+// [httperror.StatusCode]), instead of failing the invocation (which API
+// Gateway turns into a bare 502). If eh is nil,
+// [httperror.DefaultErrorHandler] is used. The format is negotiated from
+// the Accept header of the request: JSON (the default), HTML, or plain
+// text.
+//
+//	lambda.Start(lambdaadapter.Wrap(handleOrder, nil))
+func Wrap(h HandlerFunc, eh httperror.ErrorHandler) HandlerFunc {
+	if eh == nil {
+		eh = httperror.DefaultErrorHandler
+	}
+
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		resp, err := h(ctx, req)
+		if err == nil || httperror.ResponseWritten(err) {
+			return resp, nil
+		}
+
+		w := &responseWriter{header: make(http.Header)}
+		w.handleError(eh, requestHeader(req), err)
+		return w.response(), nil
+	}
+}
+
+// Handler returns a Lambda handler that serves proxy requests with h, an
+// [httperror.Handler] (such as a router), so that the same handlers can be
+// deployed on a server or on Lambda. Errors returned by h are handled with
+// eh, as in [Wrap]. If eh is nil, [httperror.DefaultErrorHandler] is used.
+//
+//	lambda.Start(lambdaadapter.Handler(httperror.MuxHandler(mux), nil))
+func Handler(h httperror.Handler, eh httperror.ErrorHandler) HandlerFunc {
+	if eh == nil {
+		eh = httperror.DefaultErrorHandler
+	}
+
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		r, err := Request(ctx, req)
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, err
+		}
+
+		w := &responseWriter{header: make(http.Header)}
+		err = h.Serve(w, r)
+		httperror.ReportError(ctx, r, err)
+		if err != nil && !httperror.ResponseWritten(err) {
+			w.handleError(eh, r.Header, err)
+		}
+		return w.response(), nil
+	}
+}
+
+// Request converts an API Gateway proxy request into an [http.Request].
+func Request(ctx context.Context, req events.APIGatewayProxyRequest) (*http.Request, error) {
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return nil, httperror.Wrap(err, http.StatusBadRequest)
+		}
+	}
+
+	u := url.URL{Path: req.Path}
+	query := url.Values{}
+	for k, vs := range req.MultiValueQueryStringParameters {
+		query[k] = vs
+	}
+	for k, v := range req.QueryStringParameters {
+		if _, ok := query[k]; !ok {
+			query.Set(k, v)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	r, err := http.NewRequestWithContext(ctx, req.HTTPMethod, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, httperror.Wrap(err, http.StatusBadRequest)
+	}
+	r.RequestURI = u.RequestURI()
+	r.RemoteAddr = req.RequestContext.Identity.SourceIP
+	r.Header = requestHeader(req)
+	r.Host = r.Header.Get("Host")
+	return r, nil
+}
+
+// requestHeader returns the headers of an API Gateway proxy request.
+func requestHeader(req events.APIGatewayProxyRequest) http.Header {
+	header := make(http.Header)
+	for k, vs := range req.MultiValueHeaders {
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+	for k, v := range req.Headers {
+		if header.Get(k) == "" {
+			header.Set(k, v)
+		}
+	}
+	return header
+}
+
+// negotiate returns the first of the supported content types (JSON, HTML,
+// and plain text) accepted by the Accept header, or JSON.
+func negotiate(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			return "application/json"
+		case "text/html", "text/*":
+			return "text/html"
+		case "text/plain":
+			return "text/plain"
+		}
+	}
+	return "application/json"
+}
+
+// responseWriter is an http.ResponseWriter that buffers a proxy response.
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// handleError handles err with eh, in the format negotiated from the
+// request headers unless the content type has already been set.
+func (w *responseWriter) handleError(eh httperror.ErrorHandler, requestHeader http.Header, err error) {
+	if w.header.Get("Content-Type") == "" {
+		w.header.Set("Content-Type", negotiate(requestHeader.Get("Accept")))
+	}
+	eh(w, err)
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(s int) {
+	if w.status == 0 {
+		w.status = s
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) response() events.APIGatewayProxyResponse {
+	resp := events.APIGatewayProxyResponse{
+		StatusCode:        w.status,
+		MultiValueHeaders: w.header,
+	}
+	if resp.StatusCode == 0 {
+		resp.StatusCode = http.StatusOK
+	}
+	if w.body.Len() > 0 && w.header.Get("Content-Type") == "" {
+		w.header.Set("Content-Type", http.DetectContentType(w.body.Bytes()))
+	}
+	if utf8.Valid(w.body.Bytes()) {
+		resp.Body = w.body.String()
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(w.body.Bytes())
+		resp.IsBase64Encoded = true
+	}
+	return resp
+}
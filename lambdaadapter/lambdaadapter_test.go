@@ -0,0 +1,68 @@
+package lambdaadapter_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/lambdaadapter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	h := lambdaadapter.Wrap(func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		if req.PathParameters["id"] == "" {
+			return events.APIGatewayProxyResponse{}, httperror.NewPublic(http.StatusNotFound, "no such order")
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "order " + req.PathParameters["id"]}, nil
+	}, nil)
+
+	resp, err := h(context.Background(), events.APIGatewayProxyRequest{PathParameters: map[string]string{"id": "42"}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "order 42", resp.Body)
+
+	resp, err = h(context.Background(), events.APIGatewayProxyRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, []string{"application/json"}, resp.MultiValueHeaders["Content-Type"])
+	assert.Contains(t, resp.Body, "no such order")
+
+	resp, err = h(context.Background(), events.APIGatewayProxyRequest{Headers: map[string]string{"accept": "text/plain"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "404 Not Found: no such order\n", resp.Body)
+}
+
+func TestHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	httperror.Handle(mux, "GET /orders/{id}", httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.PathValue("id") != "42" {
+			return httperror.NotFound
+		}
+		fmt.Fprintf(w, "order %s, expand=%s", r.PathValue("id"), r.URL.Query().Get("expand"))
+		return nil
+	}))
+	h := lambdaadapter.Handler(httperror.MuxHandler(mux), nil)
+
+	resp, err := h(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:            http.MethodGet,
+		Path:                  "/orders/42",
+		QueryStringParameters: map[string]string{"expand": "items"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "order 42, expand=items", resp.Body)
+	assert.Equal(t, []string{"text/plain; charset=utf-8"}, resp.MultiValueHeaders["Content-Type"])
+
+	resp, err = h(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/orders/7",
+		Headers:    map[string]string{"Accept": "text/html, */*"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, []string{"text/html"}, resp.MultiValueHeaders["Content-Type"])
+}
@@ -0,0 +1,146 @@
+package httperror
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Severity indicates how serious an error is, for logging and alerting.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the lower-case name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "info"
+}
+
+// ErrorSeverity returns the severity of an error: SeverityError for panics
+// and server errors (5xx), SeverityWarning for client errors (4xx), and
+// SeverityInfo otherwise (including nil errors).
+func ErrorSeverity(err error) Severity {
+	if errors.Is(err, Panic) {
+		return SeverityError
+	}
+
+	s := StatusCode(err)
+	switch {
+	case s >= 500:
+		return SeverityError
+	case s >= 400:
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+// requestIDHeader is the request header from which request IDs are read.
+const requestIDHeader = "X-Request-Id"
+
+// LogEntry describes a request that returned an error, or a non-fatal
+// error recorded during a request (see [httperror.Collect]).
+type LogEntry struct {
+	Method    string
+	Path      string
+	Status    int
+	Duration  time.Duration
+	RequestID string
+	Severity  Severity
+	Err       error
+
+	// NonFatal is true for errors recorded with [httperror.Collect], which
+	// didn't determine the response.
+	NonFatal bool
+}
+
+// String formats the log entry as a single line.
+func (e LogEntry) String() string {
+	var b bytes.Buffer
+
+	b.WriteString(e.Severity.String())
+	b.WriteString(" ")
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteString(" ")
+	b.WriteString(e.Method)
+	b.WriteString(" ")
+	b.WriteString(e.Path)
+	b.WriteString(" ")
+	b.WriteString(e.Duration.String())
+	if e.RequestID != "" {
+		b.WriteString(" request_id=")
+		b.WriteString(e.RequestID)
+	}
+	if e.NonFatal {
+		b.WriteString(" non-fatal")
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+// Logger is the interface used by [httperror.LoggingMiddleware] to log
+// errors.
+type Logger interface {
+	Log(e LogEntry)
+}
+
+// LoggerFunc is an adapter that allows the use of an ordinary function as
+// an [httperror.Logger].
+type LoggerFunc func(e LogEntry)
+
+// Log calls f(e).
+func (f LoggerFunc) Log(e LogEntry) {
+	f(e)
+}
+
+// StdLogger returns an [httperror.Logger] that prints log entries as single
+// lines to a standard library [log.Logger].
+func StdLogger(l *log.Logger) Logger {
+	return LoggerFunc(func(e LogEntry) {
+		l.Println(e.String())
+	})
+}
+
+// LoggingMiddleware returns an [httperror.Middleware] that logs errors
+// returned by the wrapped handler, along with the request method, path,
+// status code (see [httperror.StatusCode]), duration, request ID (from the
+// X-Request-Id request header), and severity (see
+// [httperror.ErrorSeverity]). The error is then returned unchanged, so it
+// can still be handled by the error handler.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+
+			err := h.Serve(w, r)
+			if err != nil {
+				logger.Log(LogEntry{
+					Method:    r.Method,
+					Path:      r.URL.Path,
+					Status:    StatusCode(err),
+					Duration:  time.Since(start),
+					RequestID: r.Header.Get(requestIDHeader),
+					Severity:  ErrorSeverity(err),
+					Err:       err,
+				})
+			}
+
+			return err
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package httperror_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	var entries []httperror.LogEntry
+	logger := httperror.LoggerFunc(func(e httperror.LogEntry) {
+		entries = append(entries, e)
+	})
+
+	{
+		h := httperror.LoggingMiddleware(logger)(notFoundHandler)
+		s, m := testRequest(h, "/foo")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, "404 Not Found\n", m)
+
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "GET", entries[0].Method)
+		assert.Equal(t, "/foo", entries[0].Path)
+		assert.Equal(t, 404, entries[0].Status)
+		assert.Equal(t, httperror.SeverityWarning, entries[0].Severity)
+		assert.Equal(t, httperror.NotFound, entries[0].Err)
+	}
+
+	{
+		entries = nil
+		h := httperror.LoggingMiddleware(logger)(okHandler)
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 200, s)
+		assert.Empty(t, entries)
+	}
+
+	{
+		var b bytes.Buffer
+		h := httperror.LoggingMiddleware(httperror.StdLogger(log.New(&b, "", 0)))(httperror.PanicMiddleware(getMeOuttaHere))
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 500, s)
+		assert.Regexp(t, `^error 500 GET / \S+: panic: Get me outta here!\n$`, b.String())
+	}
+}
+
+func TestCollectMiddleware(t *testing.T) {
+	errCacheMiss := errors.New("cache miss")
+
+	var entries []httperror.LogEntry
+	logger := httperror.LoggerFunc(func(e httperror.LogEntry) {
+		entries = append(entries, e)
+	})
+
+	h := httperror.CollectMiddleware(logger)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		httperror.Collect(r.Context(), errCacheMiss)
+		httperror.Collect(r.Context(), httperror.Wrap(sentinalError, http.StatusBadGateway))
+		httperror.Collect(r.Context(), nil)
+		assert.Len(t, httperror.Collected(r.Context()), 2)
+
+		_, _ = w.Write([]byte("OK\n"))
+		return nil
+	}))
+
+	s, _ := testRequest(h, "/")
+	assert.Equal(t, 200, s, "non-fatal errors don't change the response")
+
+	assert.Len(t, entries, 2)
+	assert.Equal(t, errCacheMiss, entries[0].Err)
+	assert.True(t, entries[0].NonFatal)
+	assert.Equal(t, 200, entries[0].Status)
+	assert.Equal(t, httperror.SeverityWarning, entries[1].Severity, "severity is at most warning")
+	assert.Regexp(t, `^warning 200 GET / \S+ non-fatal: cache miss$`, entries[0].String())
+
+	// Collecting without the middleware is a no-op.
+	httperror.Collect(context.Background(), errCacheMiss)
+	assert.Nil(t, httperror.Collected(context.Background()))
+}
+
+func TestGo(t *testing.T) {
+	errUpstream := errors.New("upstream failed")
+
+	var mu sync.Mutex
+	var entries []httperror.LogEntry
+	logged := make(chan struct{}, 2)
+	logger := httperror.LoggerFunc(func(e httperror.LogEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, e)
+		logged <- struct{}{}
+	})
+
+	release := make(chan struct{})
+	h := httperror.CollectMiddleware(logger)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		httperror.Go(r.Context(), func() error {
+			<-release
+			return errUpstream
+		})
+		httperror.Go(r.Context(), func() error {
+			<-release
+			panic("boom")
+		})
+		return nil
+	}))
+
+	s, _ := testRequest(h, "/")
+	assert.Equal(t, 200, s)
+
+	// The goroutines finish after the request, and their errors are logged
+	// right away.
+	close(release)
+	<-logged
+	<-logged
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var errs []error
+	for _, e := range entries {
+		assert.True(t, e.NonFatal)
+		errs = append(errs, e.Err)
+	}
+	assert.Contains(t, errs, errUpstream)
+	assert.True(t, errors.Is(errs[0], httperror.Panic) || errors.Is(errs[1], httperror.Panic), "panics are reported as errors")
+}
+
+func TestErrorSeverity(t *testing.T) {
+	assert.Equal(t, httperror.SeverityInfo, httperror.ErrorSeverity(nil))
+	assert.Equal(t, httperror.SeverityWarning, httperror.ErrorSeverity(httperror.BadRequest))
+	assert.Equal(t, httperror.SeverityError, httperror.ErrorSeverity(httperror.Wrap(sentinalError, http.StatusBadGateway)))
+	assert.Equal(t, "warning", httperror.SeverityWarning.String())
+}
+
+func TestSlogMiddleware(t *testing.T) {
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	{
+		h := httperror.SlogMiddleware(logger)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.WithCode(httperror.NewPublic(http.StatusNotFound, "no such order"), "order_not_found")
+		}))
+
+		r := httptest.NewRequest("GET", "/orders/42", nil)
+		r.Header.Set("X-Request-Id", "abc123")
+		r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		assert.Equal(t, `level=WARN msg="http request" method=GET path=/orders/42 status=404 request_id=abc123 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 error.msg="404 Not Found: no such order" error.status=404 error.code=order_not_found error.public="no such order"`+"\n", b.String())
+	}
+
+	{
+		b.Reset()
+		h := httperror.SlogMiddleware(logger)(okHandler)
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, `level=INFO msg="http request" method=GET path=/ status=200`+"\n", b.String())
+	}
+
+	{
+		b.Reset()
+		h := httperror.SlogMiddleware(logger)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}))
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 204, s)
+		assert.Equal(t, `level=INFO msg="http request" method=GET path=/ status=204`+"\n", b.String(), "logs the status written by the handler")
+	}
+}
+
+// reportedError is only matched by the reporter registered in
+// TestRegisterErrorReporter, since registered reporters are global.
+var reportedError = errors.New("reported")
+
+func TestRegisterErrorReporter(t *testing.T) {
+	var reported []string
+	httperror.RegisterErrorReporter(httperror.ErrorReporterFunc(func(ctx context.Context, r *http.Request, err error) {
+		reported = append(reported, r.URL.Path)
+	}), func(err error) bool {
+		return errors.Is(err, reportedError)
+	})
+
+	fail := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.Wrap(reportedError, http.StatusBadGateway)
+	})
+
+	{
+		s, _ := testRequest(fail, "/default")
+		assert.Equal(t, 502, s)
+	}
+
+	{
+		s, _ := testRequest(httperror.WrapHandler(fail, customErrorHandler), "/custom")
+		assert.Equal(t, 502, s)
+	}
+
+	{
+		s, _ := testRequest(httperror.WrapHandler(httperror.WithErrorHandler(fail, customErrorHandler), nil), "/route")
+		assert.Equal(t, 502, s)
+	}
+
+	{
+		s, _ := testRequest(notFoundHandler, "/other")
+		assert.Equal(t, 404, s)
+	}
+
+	assert.Equal(t, []string{"/default", "/custom", "/route"}, reported, "reported once per request, for matching errors only")
+}
@@ -0,0 +1,16 @@
+module github.com/johnwarden/httperror/logruserror
+
+go 1.22
+
+require (
+	github.com/johnwarden/httperror v0.0.0-20261016035624-08a2347946c7
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
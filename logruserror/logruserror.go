@@ -0,0 +1,65 @@
+/*
+Package logruserror provides structured logging of httperrors with logrus
+(github.com/sirupsen/logrus). See the documentation of the parent package
+at https://github.com/johnwarden/httperror
+*/
+package logruserror
+
+import (
+	"github.com/johnwarden/httperror"
+	"github.com/sirupsen/logrus"
+)
+
+// Fields returns logrus fields describing err: its status code (see
+// [httperror.StatusCode]), error code (see [httperror.ErrorCode]) and
+// public message (see [httperror.PublicMessage]), if any, and its severity
+// (see [httperror.ErrorSeverity]). A nil error returns no fields.
+//
+//	log.WithError(err).WithFields(logruserror.Fields(err)).Error("request failed")
+func Fields(err error) logrus.Fields {
+	if err == nil {
+		return nil
+	}
+
+	fields := logrus.Fields{
+		"status":   httperror.StatusCode(err),
+		"severity": httperror.ErrorSeverity(err).String(),
+	}
+	if c := httperror.ErrorCode(err); c != "" {
+		fields["code"] = c
+	}
+	if m := httperror.PublicMessage(err); m != "" {
+		fields["public"] = m
+	}
+	return fields
+}
+
+// Hook is a logrus hook that adds the fields returned by [Fields] to
+// entries with an error (see logrus.WithError), so that existing log calls
+// get them without changes:
+//
+//	logrus.AddHook(logruserror.Hook{})
+//
+// Fields already set on the entry are not overwritten.
+type Hook struct{}
+
+var _ logrus.Hook = Hook{}
+
+// Levels returns all levels.
+func (Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the fields for the error of the entry, if any.
+func (Hook) Fire(entry *logrus.Entry) error {
+	err, ok := entry.Data[logrus.ErrorKey].(error)
+	if !ok {
+		return nil
+	}
+	for k, v := range Fields(err) {
+		if _, exists := entry.Data[k]; !exists {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
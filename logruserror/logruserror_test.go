@@ -0,0 +1,43 @@
+package logruserror_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/logruserror"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields(t *testing.T) {
+	assert.Nil(t, logruserror.Fields(nil))
+
+	err := httperror.WithCode(httperror.NewPublic(http.StatusConflict, "order already paid"), "already_paid")
+	assert.Equal(t, logrus.Fields{
+		"status":   409,
+		"code":     "already_paid",
+		"public":   "order already paid",
+		"severity": "warning",
+	}, logruserror.Fields(err))
+}
+
+func TestHook(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(logruserror.Hook{})
+	hook := test.NewLocal(logger)
+
+	logger.WithError(httperror.BadGateway).WithField("status", "upstream").Error("request failed")
+	logger.Info("no error")
+
+	entries := hook.AllEntries()
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+	assert.Equal(t, "upstream", entries[0].Data["status"])
+	assert.Equal(t, "error", entries[0].Data["severity"])
+	assert.NotContains(t, entries[1].Data, "severity")
+}
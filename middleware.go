@@ -0,0 +1,57 @@
+package httperror
+
+import (
+	"net/http"
+)
+
+// Middleware is an error-aware handler wrapper. Unlike a
+// [httperror.StandardMiddleware], the wrapped handler returns an error that
+// the middleware can inspect, transform, or handle.
+type Middleware = func(Handler) Handler
+
+// XMiddleware is a generic version of [httperror.Middleware] that wraps an
+// [httperror.XHandler].
+type XMiddleware[P any] func(XHandler[P]) XHandler[P]
+
+// Chain composes middleware into a single [httperror.Middleware]. The first
+// middleware is the outermost: Chain(m1, m2)(h) is equivalent to m1(m2(h)).
+func Chain(ms ...Middleware) Middleware {
+	return func(h Handler) Handler {
+		for i := len(ms) - 1; i >= 0; i-- {
+			h = ms[i](h)
+		}
+		return h
+	}
+}
+
+// XChain is a generic version of [httperror.Chain] that composes
+// [httperror.XMiddleware]. The first middleware is the outermost.
+func XChain[P any](ms ...XMiddleware[P]) XMiddleware[P] {
+	return func(h XHandler[P]) XHandler[P] {
+		for i := len(ms) - 1; i >= 0; i-- {
+			h = ms[i](h)
+		}
+		return h
+	}
+}
+
+// ErrorFilter wraps h, passing errors returned by h through f on the way
+// out. This gives a service one central place to map domain errors to
+// status codes, attach public messages, or replace sensitive messages. f is
+// only called for non-nil errors, and may return nil to suppress an error.
+//
+//	h = httperror.ErrorFilter(h, func(r *http.Request, err error) error {
+//		if errors.Is(err, sql.ErrNoRows) {
+//			return httperror.Wrap(err, http.StatusNotFound)
+//		}
+//		return err
+//	})
+func ErrorFilter(h Handler, f func(*http.Request, error) error) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err := h.Serve(w, r)
+		if err == nil {
+			return nil
+		}
+		return f(r, err)
+	})
+}
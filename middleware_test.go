@@ -0,0 +1,409 @@
+package httperror_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+
+	m := func(name string) httperror.Middleware {
+		return func(h httperror.Handler) httperror.Handler {
+			return httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				calls = append(calls, name)
+				return h.Serve(w, r)
+			})
+		}
+	}
+
+	h := httperror.Chain(m("first"), m("second"))(notFoundHandler)
+
+	s, _ := testRequest(h, "/")
+	assert.Equal(t, 404, s)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestXChain(t *testing.T) {
+	prefix := func(p string) httperror.XMiddleware[string] {
+		return func(h httperror.XHandler[string]) httperror.XHandler[string] {
+			return httperror.XHandlerFunc[string](func(w http.ResponseWriter, r *http.Request, name string) error {
+				return h.Serve(w, r, p+name)
+			})
+		}
+	}
+
+	inner := httperror.XChain(prefix("Mr. "), prefix("Dr. "))(nameHandler)
+
+	h := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return inner.Serve(w, r, "Bill")
+	})
+
+	s, m := testRequest(h, "/")
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "Hello, Dr. Mr. Bill\n", m)
+}
+
+func TestResponseWriter(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rw := httperror.NewResponseWriter(rr)
+	assert.Same(t, rw, httperror.NewResponseWriter(rw), "existing ResponseWriter is reused")
+
+	assert.False(t, rw.Written())
+	assert.Equal(t, 0, rw.Status())
+
+	_, _ = rw.Write([]byte("hello"))
+	_, _ = rw.Write([]byte(", world"))
+	assert.True(t, rw.Written())
+	assert.Equal(t, 200, rw.Status())
+	assert.Equal(t, int64(12), rw.BytesWritten())
+
+	rw = httperror.NewResponseWriter(httptest.NewRecorder())
+	err := notFoundHandler.Serve(rw, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, httperror.NotFound, err)
+	assert.False(t, rw.Written(), "handler returned an error without writing a response")
+}
+
+func TestErrorFilter(t *testing.T) {
+	errNoSuchOrder := errors.New("no such order")
+
+	h := httperror.ErrorFilter(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/order":
+			return errNoSuchOrder
+		case "/secret":
+			return httperror.NewPublic(http.StatusForbidden, "user 42 lacks role admin")
+		}
+		return nil
+	}), func(r *http.Request, err error) error {
+		if errors.Is(err, errNoSuchOrder) {
+			return httperror.NewPublic(http.StatusNotFound, err.Error())
+		}
+		if httperror.StatusCode(err) == http.StatusForbidden {
+			return httperror.Forbidden
+		}
+		return err
+	})
+
+	{
+		s, m := testRequest(h, "/order")
+		assert.Equal(t, 404, s)
+		assert.Contains(t, m, "Not Found: no such order")
+	}
+
+	{
+		s, m := testRequest(h, "/secret")
+		assert.Equal(t, 403, s)
+		assert.NotContains(t, m, "admin", "sensitive message is replaced")
+	}
+
+	{
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 200, s)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	api := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api" {
+			return httperror.NotFound
+		}
+		_, _ = w.Write([]byte("api\n"))
+		return nil
+	})
+	static := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path != "/static" {
+			return httperror.NotFound
+		}
+		_, _ = w.Write([]byte("static\n"))
+		return nil
+	})
+	index := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("index\n"))
+		return nil
+	})
+
+	{
+		h := httperror.Fallback(api, static, index)
+
+		_, m := testRequest(h, "/api")
+		assert.Equal(t, "api\n", m)
+
+		rr := httptest.NewRecorder()
+		assert.Nil(t, h.Serve(rr, httptest.NewRequest("GET", "/static", nil)))
+		assert.Equal(t, "static\n", rr.Body.String())
+		assert.NotEqual(t, "application/json", rr.Header().Get("Content-Type"), "headers of fallen back handlers are discarded")
+
+		_, m = testRequest(h, "/other")
+		assert.Equal(t, "index\n", m)
+	}
+
+	{
+		s, _ := testRequest(httperror.Fallback(api, static), "/other")
+		assert.Equal(t, 404, s, "error of the last handler is returned")
+	}
+
+	{
+		h := httperror.FallbackIf(func(err error) bool {
+			return errors.Is(err, httperror.Forbidden)
+		}, api, index)
+
+		s, _ := testRequest(h, "/other")
+		assert.Equal(t, 404, s, "NotFound doesn't fall back with a custom predicate")
+	}
+}
+
+func TestContextErrorMiddleware(t *testing.T) {
+	serve := func(ctx context.Context, h httperror.HandlerFunc) (*httptest.ResponseRecorder, error) {
+		rr := httptest.NewRecorder()
+		err := httperror.ContextErrorMiddleware(h).Serve(rr, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+		return rr, err
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	{
+		_, err := serve(canceled, func(w http.ResponseWriter, r *http.Request) error {
+			return nil
+		})
+		assert.Equal(t, 499, httperror.StatusCode(err))
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, "499 Client Closed Request: context canceled", err.Error())
+
+		rr := httptest.NewRecorder()
+		httperror.DefaultErrorHandler(rr, err)
+		assert.Contains(t, rr.Body.String(), "<body>Client Closed Request</body>")
+	}
+
+	{
+		_, err := serve(expired, func(w http.ResponseWriter, r *http.Request) error {
+			return r.Context().Err()
+		})
+		assert.Equal(t, 504, httperror.StatusCode(err))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	}
+
+	{
+		_, err := serve(canceled, func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.BadRequest
+		})
+		assert.Equal(t, httperror.BadRequest, err, "errors with a status code are unchanged")
+	}
+
+	{
+		_, err := serve(canceled, func(w http.ResponseWriter, r *http.Request) error {
+			_, _ = w.Write([]byte("partial"))
+			return nil
+		})
+		assert.Equal(t, 499, httperror.StatusCode(err))
+		assert.True(t, httperror.ResponseWritten(err))
+		assert.True(t, errors.Is(err, context.Canceled), "cause is kept")
+	}
+
+	{
+		_, err := serve(context.Background(), okHandler)
+		assert.Nil(t, err)
+	}
+}
+
+func TestRequestValidationMiddleware(t *testing.T) {
+	h := httperror.RequestValidationMiddleware(httperror.RequestRules{
+		ContentTypes:   []string{"application/json"},
+		Headers:        []string{"idempotency-key"},
+		MaxHeaderBytes: 100,
+	})(okHandler)
+
+	serve := func(contentType, body string, header http.Header) (int, string) {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		for k, vs := range header {
+			r.Header[k] = vs
+		}
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		r.Header.Set("Accept", "text/plain")
+		rr := httptest.NewRecorder()
+		rr.Header().Set("Content-Type", "text/plain")
+		h.ServeHTTP(rr, r)
+		return rr.Code, rr.Body.String()
+	}
+
+	key := http.Header{"Idempotency-Key": {"abc"}}
+
+	{
+		s, _ := serve("application/json; charset=utf-8", "{}", key)
+		assert.Equal(t, 200, s)
+	}
+
+	{
+		s, m := serve("text/xml", "<a/>", key)
+		assert.Equal(t, 415, s)
+		assert.Equal(t, "415 Unsupported Media Type: unsupported Content-Type text/xml, expected application/json\n", m)
+	}
+
+	{
+		s, _ := serve("", "", key)
+		assert.Equal(t, 200, s, "Content-Type is not required without a body")
+	}
+
+	{
+		s, m := serve("application/json", "{}", nil)
+		assert.Equal(t, 400, s)
+		assert.Equal(t, "400 Bad Request: missing required header Idempotency-Key\n", m)
+	}
+
+	{
+		s, _ := serve("application/json", "{}", http.Header{"Idempotency-Key": {"abc"}, "Cookie": {strings.Repeat("x", 100)}})
+		assert.Equal(t, 431, s)
+	}
+}
+
+func TestWriteGuardMiddleware(t *testing.T) {
+	var entries []httperror.LogEntry
+	logger := httperror.LoggerFunc(func(e httperror.LogEntry) {
+		entries = append(entries, e)
+	})
+
+	var saved http.ResponseWriter
+	h := httperror.WriteGuardMiddleware(httperror.DefaultErrorHandler, logger)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain")
+		saved = w
+		return httperror.NotFound
+	}))
+
+	rr := httptest.NewRecorder()
+	err := h.Serve(rr, httptest.NewRequest("GET", "/", nil))
+	assert.True(t, errors.Is(err, httperror.NotFound))
+	assert.True(t, httperror.ResponseWritten(err))
+	assert.Equal(t, 404, rr.Code)
+	assert.Equal(t, "404 Not Found\n", rr.Body.String())
+
+	// A late write, e.g. from a goroutine, is discarded.
+	saved.Header().Set("X-Late", "yes")
+	_, writeErr := saved.Write([]byte("late"))
+	_, _ = saved.Write([]byte("later"))
+	assert.Equal(t, httperror.ErrWriteAfterError, writeErr)
+	assert.Equal(t, "404 Not Found\n", rr.Body.String())
+	assert.Equal(t, "", rr.Header().Get("X-Late"))
+
+	assert.Len(t, entries, 1, "first discarded write is logged")
+	assert.Equal(t, httperror.ErrWriteAfterError, entries[0].Err)
+	assert.Equal(t, 404, entries[0].Status)
+
+	// Outer wrappers don't write a second response.
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "404 Not Found\n", rr.Body.String())
+}
+
+// hijackableWriter is an http.ResponseWriter that supports hijacking and
+// records what was done to it.
+type hijackableWriter struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+	readFrom bool
+}
+
+func (w *hijackableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func (w *hijackableWriter) ReadFrom(src io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(w.ResponseRecorder, src)
+}
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	base := &hijackableWriter{ResponseRecorder: httptest.NewRecorder()}
+	rw := httperror.NewResponseWriter(base)
+
+	var w http.ResponseWriter = rw
+	assert.Equal(t, base, httperror.Unwrap(w))
+
+	_, ok := w.(http.Hijacker)
+	assert.False(t, ok, "wrappers don't claim capabilities of the underlying writer")
+	_, ok = w.(http.Pusher)
+	assert.False(t, ok)
+
+	_, _, err := http.NewResponseController(w).Hijack()
+	assert.Nil(t, err)
+	assert.True(t, base.hijacked, "ResponseController hijacks through the wrapper")
+
+	n, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), n)
+	assert.True(t, base.readFrom, "ReadFrom is delegated")
+	assert.Equal(t, int64(5), rw.BytesWritten())
+
+	// Without support in the underlying writer, Hijack fails and ReadFrom
+	// falls back to Write.
+	rr := httptest.NewRecorder()
+	w = httperror.NewResponseWriter(rr)
+	_, _, err = http.NewResponseController(w).Hijack()
+	assert.True(t, errors.Is(err, http.ErrNotSupported))
+	_, _ = w.(io.ReaderFrom).ReadFrom(strings.NewReader("hello"))
+	assert.Equal(t, "hello", rr.Body.String())
+	assert.Equal(t, rr, httperror.Unwrap(w))
+
+	// Hijacking works through other wrappers, such as FromStandard.
+	base = &hijackableWriter{ResponseRecorder: httptest.NewRecorder()}
+	err = httperror.FromStandard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, _ = http.NewResponseController(w).Hijack()
+	})).Serve(httperror.NewResponseWriter(base), httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, err)
+	assert.True(t, base.hijacked)
+}
+
+func TestStack(t *testing.T) {
+	var order []string
+	mark := func(name string) httperror.Middleware {
+		return func(h httperror.Handler) httperror.Handler {
+			return httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				order = append(order, name)
+				return h.Serve(w, r)
+			})
+		}
+	}
+	markStandard := func(name string) httperror.StandardMiddleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	var s httperror.Stack
+	s.Use(mark("a")).UseStandard(markStandard("b"), markStandard("c")).Use(mark("d"))
+	s.ErrorHandler = customErrorHandler
+
+	{
+		s, _ := testRequest(s.Then(okHandler), "/")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, order, "middleware is applied in the declared order")
+	}
+
+	{
+		s, m := testRequest(s.Then(httperror.HandlerFunc(helloHandler)), "/")
+		assert.Equal(t, 400, s)
+		assert.Equal(t, "400 Sorry, we couldn't parse your request: missing 'name' parameter\n", m, "errors pass through standard middleware to the error handler")
+	}
+}
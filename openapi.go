@@ -0,0 +1,123 @@
+package httperror
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// OpenAPIComponents returns the OpenAPI 3 components for the problem types
+// registered in the [httperror.DefaultCatalog] (see
+// [httperror.Catalog.OpenAPIComponents]).
+func OpenAPIComponents() ([]byte, error) {
+	return DefaultCatalog.OpenAPIComponents()
+}
+
+// OpenAPIComponents returns a JSON OpenAPI 3 components object describing
+// the problem details responses (see [httperror.ProblemErrorHandler]) for
+// the problem types registered in the catalog, so that API specs stay in
+// sync with the errors handlers return. It contains a Problem schema, and
+// a response for each problem type, named by its code, and for each
+// status code of a problem type, named "Problem" followed by the status
+// code (e.g. "Problem403"), with an example for each problem type with that
+// status. Operations can then reference the responses:
+//
+//	responses:
+//	  "403":
+//	    $ref: "#/components/responses/Problem403"
+func (c *Catalog) OpenAPIComponents() ([]byte, error) {
+	responses := make(map[string]openAPIResponse)
+	for _, t := range c.ProblemTypes() {
+		example := Problem{
+			Type:   c.TypeURI(t.Code),
+			Title:  t.Title,
+			Status: t.Status,
+		}
+
+		responses[t.Code] = openAPIResponse{
+			Description: problemTypeDescription(t),
+			Content: map[string]openAPIMediaType{
+				contentTypeProblemJSON: {Schema: problemSchemaRef, Example: &example},
+			},
+		}
+
+		if t.Status == 0 {
+			continue
+		}
+		name := "Problem" + strconv.Itoa(t.Status)
+		r, ok := responses[name]
+		if !ok {
+			r = openAPIResponse{
+				Description: statusText(t.Status),
+				Content: map[string]openAPIMediaType{
+					contentTypeProblemJSON: {Schema: problemSchemaRef, Examples: make(map[string]openAPIExample)},
+				},
+			}
+			responses[name] = r
+		}
+		r.Content[contentTypeProblemJSON].Examples[t.Code] = openAPIExample{Summary: t.Title, Value: example}
+	}
+
+	return json.Marshal(openAPIComponents{
+		Schemas:   map[string]interface{}{"Problem": problemSchema},
+		Responses: responses,
+	})
+}
+
+func problemTypeDescription(t ProblemType) string {
+	if t.Description == "" {
+		return t.Title
+	}
+	if t.Title == "" {
+		return t.Description
+	}
+	return t.Title + " " + t.Description
+}
+
+type openAPIComponents struct {
+	Schemas   map[string]interface{}     `json:"schemas"`
+	Responses map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema   interface{}               `json:"schema"`
+	Example  *Problem                  `json:"example,omitempty"`
+	Examples map[string]openAPIExample `json:"examples,omitempty"`
+}
+
+type openAPIExample struct {
+	Summary string  `json:"summary,omitempty"`
+	Value   Problem `json:"value"`
+}
+
+var problemSchemaRef = map[string]string{"$ref": "#/components/schemas/Problem"}
+
+// problemSchema is the JSON schema of problem details responses.
+var problemSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"type", "title", "status"},
+	"properties": map[string]interface{}{
+		"type":     map[string]string{"type": "string", "format": "uri-reference"},
+		"title":    map[string]string{"type": "string"},
+		"status":   map[string]string{"type": "integer"},
+		"detail":   map[string]string{"type": "string"},
+		"instance": map[string]string{"type": "string", "format": "uri-reference"},
+		"code":     map[string]string{"type": "string"},
+		"errors": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"resource": map[string]string{"type": "string"},
+					"field":    map[string]string{"type": "string"},
+					"code":     map[string]string{"type": "string"},
+					"message":  map[string]string{"type": "string"},
+				},
+			},
+		},
+	},
+}
@@ -0,0 +1,151 @@
+package httperror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// RequestErrorHandler is like [httperror.ErrorHandler], but also receives
+// the request, so that the error response can depend on it (for example on
+// the Accept header, or a request ID).
+type RequestErrorHandler = func(http.ResponseWriter, *http.Request, error)
+
+// HandlerOption configures the [http.Handler] returned by
+// [httperror.NewHandler] or [httperror.WrapHandler].
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	errorHandler  RequestErrorHandler
+	recoverPanics bool
+	logger        Logger
+	timeout       time.Duration
+	maxBytes      int64
+}
+
+// NewHandler returns a standard [http.Handler] for f, configured by the
+// given options, so that the common setup of an error handler, panic
+// recovery, and logging doesn't need several nested wrappers. Errors are
+// handled by [httperror.DefaultErrorHandler] unless another error handler
+// is set.
+//
+//	h := httperror.NewHandler(helloHandler,
+//		httperror.WithErrorHandlerFunc(customErrorHandler),
+//		httperror.WithPanicRecovery(true),
+//		httperror.WithLogger(logger),
+//		httperror.WithTimeout(10*time.Second),
+//		httperror.WithMaxBytes(1<<20),
+//	)
+func NewHandler(f HandlerFunc, opts ...HandlerOption) http.Handler {
+	return WrapHandler(f, DefaultErrorHandler, opts...)
+}
+
+// WithErrorHandlerFunc sets the error handler, replacing
+// [httperror.DefaultErrorHandler] or the error handler passed to
+// [httperror.WrapHandler].
+func WithErrorHandlerFunc(eh ErrorHandler) HandlerOption {
+	return WithRequestErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		eh(w, err)
+	})
+}
+
+// WithPanicRecovery turns panic recovery on or off. When on, panics in the
+// handler are recovered and handled as errors by the error handler (see
+// [httperror.PanicMiddleware]). It is off by default.
+func WithPanicRecovery(enabled bool) HandlerOption {
+	return func(o *handlerOptions) {
+		o.recoverPanics = enabled
+	}
+}
+
+// WithRequestErrorHandler sets a request-aware error handler, which takes
+// precedence over the error handler passed to [httperror.WrapHandler].
+func WithRequestErrorHandler(eh RequestErrorHandler) HandlerOption {
+	return func(o *handlerOptions) {
+		o.errorHandler = eh
+	}
+}
+
+// WithLogger logs errors, including recovered panics, using logger (see
+// [httperror.LoggingMiddleware]).
+func WithLogger(logger Logger) HandlerOption {
+	return func(o *handlerOptions) {
+		o.logger = logger
+	}
+}
+
+// WithTimeout sets a deadline of d on the request context. If the handler
+// returns an error caused by the deadline (context.DeadlineExceeded) that
+// has no status code, it is returned as GatewayTimeout, as by
+// [httperror.ContextErrorMiddleware]. Handlers must
+// respect the request context for the timeout to have an effect.
+func WithTimeout(d time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.timeout = d
+	}
+}
+
+// WithMaxBytes limits the size of the request body to n bytes (see
+// [http.MaxBytesReader]). If the handler returns an error caused by reading
+// past the limit that has no status code, it is returned as
+// RequestEntityTooLarge.
+func WithMaxBytes(n int64) HandlerOption {
+	return func(o *handlerOptions) {
+		o.maxBytes = n
+	}
+}
+
+// handler applies the options to h.
+func (o handlerOptions) handler(h Handler) http.Handler {
+	if o.recoverPanics {
+		h = PanicMiddleware(h)
+	}
+	if o.timeout > 0 {
+		h = timeoutMiddleware(h, o.timeout)
+	}
+	if o.maxBytes > 0 {
+		h = maxBytesMiddleware(h, o.maxBytes)
+	}
+	if o.logger != nil {
+		h = LoggingMiddleware(o.logger)(h)
+	}
+
+	eh := o.errorHandler
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := h.Serve(w, r)
+		ReportError(r.Context(), r, err)
+		if err != nil && !ResponseWritten(err) {
+			eh(w, r, err)
+		}
+	})
+}
+
+func timeoutMiddleware(h Handler, d time.Duration) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+
+		err := h.Serve(w, r.WithContext(ctx))
+		if errors.Is(err, context.DeadlineExceeded) && !HasStatusCode(err) {
+			return Wrap(err, http.StatusGatewayTimeout)
+		}
+		return err
+	})
+}
+
+func maxBytesMiddleware(h Handler, n int64) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		// Don't modify the caller's request, like http.MaxBytesHandler.
+		r2 := *r
+		r2.Body = http.MaxBytesReader(w, r.Body, n)
+
+		err := h.Serve(w, &r2)
+
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) && !HasStatusCode(err) {
+			return Wrap(err, http.StatusRequestEntityTooLarge)
+		}
+		return err
+	})
+}
@@ -0,0 +1,64 @@
+package otelerror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/johnwarden/httperror"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes returns the attributes describing an error, following the
+// OpenTelemetry semantic conventions for HTTP:
+//
+//   - http.response.status_code: the status code of the error (see
+//     [httperror.StatusCode])
+//   - error.type: the status code as a string, or "panic" for panics (see
+//     [httperror.Panic])
+//   - app.error.code: the machine-readable error code (see
+//     [httperror.ErrorCode]), if the error has one
+//
+// A nil error returns only http.response.status_code, with the value 200.
+func Attributes(err error) []attribute.KeyValue {
+	s := httperror.StatusCode(err)
+	attrs := []attribute.KeyValue{attribute.Int("http.response.status_code", s)}
+	if err == nil {
+		return attrs
+	}
+
+	errorType := strconv.Itoa(s)
+	if errors.Is(err, httperror.Panic) {
+		errorType = "panic"
+	}
+	attrs = append(attrs, attribute.String("error.type", errorType))
+
+	if code := httperror.ErrorCode(err); code != "" {
+		attrs = append(attrs, attribute.String("app.error.code", code))
+	}
+	return attrs
+}
+
+// RecordError records an error on the active span of ctx, the same way as
+// [Middleware]: the error is recorded with span.RecordError, the attributes
+// returned by [Attributes] are set, and the span status is set to Error for
+// server errors (5xx). It does nothing if err is nil.
+func RecordError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	recordError(trace.SpanFromContext(ctx), err)
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetAttributes(Attributes(err)...)
+
+	if s := httperror.StatusCode(err); s >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(s))
+	}
+}
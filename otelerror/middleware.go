@@ -0,0 +1,71 @@
+/*
+Package otelerror provides OpenTelemetry instrumentation for handlers that
+return errors. See the documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package otelerror
+
+import (
+	"net/http"
+
+	"github.com/johnwarden/httperror"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/johnwarden/httperror/otelerror"
+
+// Middleware returns an [httperror.Middleware] that starts a server span for
+// each request, continuing any trace propagated in the request headers. The
+// span is driven by the error returned by the wrapped handler: the error is
+// recorded with span.RecordError, and the attributes returned by
+// [Attributes] are set, including http.response.status_code. If the handler
+// returned no error, http.response.status_code is set to the status it
+// wrote. The span status is set to Error for server errors (5xx). Client
+// errors (4xx) leave the span status unset, as recommended by the
+// OpenTelemetry semantic conventions for server spans.
+//
+// If tp is nil, the global tracer provider is used.
+func Middleware(tp trace.TracerProvider) httperror.Middleware {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	tracer := tp.Tracer(tracerName)
+
+	return func(h httperror.Handler) httperror.Handler {
+		return httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			rw := httperror.NewResponseWriter(w)
+			err := h.Serve(rw, r.WithContext(ctx))
+
+			if err != nil {
+				recordError(span, err)
+				return err
+			}
+
+			s := http.StatusOK
+			if rw.Written() {
+				s = rw.Status()
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", s))
+			if s >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(s))
+			}
+			return nil
+		})
+	}
+}
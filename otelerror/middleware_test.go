@@ -0,0 +1,82 @@
+package otelerror_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/otelerror"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	serve := func(err error) sdktrace.ReadOnlySpan {
+		h := otelerror.Middleware(tp)(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return err
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders/42", nil))
+
+		spans := sr.Ended()
+		return spans[len(spans)-1]
+	}
+
+	{
+		span := serve(httperror.ServiceUnavailable)
+		assert.Equal(t, "GET", span.Name())
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", 503))
+		assert.Len(t, span.Events(), 1)
+		assert.Equal(t, "exception", span.Events()[0].Name)
+	}
+
+	{
+		span := serve(httperror.NotFound)
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", 404))
+		assert.Len(t, span.Events(), 1)
+	}
+
+	{
+		span := serve(nil)
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", 200))
+		assert.Empty(t, span.Events())
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	assert.Equal(t, []attribute.KeyValue{attribute.Int("http.response.status_code", 200)}, otelerror.Attributes(nil))
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.Int("http.response.status_code", 402),
+		attribute.String("error.type", "402"),
+		attribute.String("app.error.code", "card_declined"),
+	}, otelerror.Attributes(httperror.WithCode(httperror.PaymentRequired, "card_declined")))
+
+	assert.Contains(t, otelerror.Attributes(httperror.PanicError("oops")), attribute.String("error.type", "panic"))
+}
+
+func TestRecordError(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "work")
+	otelerror.RecordError(ctx, httperror.BadGateway)
+	otelerror.RecordError(ctx, nil)
+	span.End()
+
+	ended := sr.Ended()[0]
+	assert.Equal(t, codes.Error, ended.Status().Code)
+	assert.Contains(t, ended.Attributes(), attribute.String("error.type", "502"))
+	assert.Len(t, ended.Events(), 1)
+}
@@ -31,19 +31,54 @@ func (e panicError) Is(other error) bool {
 	return errors.Is(e.innerError, other)
 }
 
+// PanicConverter converts a value recovered from a panic into an error.
+type PanicConverter = func(recovered interface{}) error
+
+// PanicError is the default [httperror.PanicConverter]. It converts a value
+// recovered from a panic into an error that can be identified using
+// errors.Is(err, httperror.Panic). If the value is an error, it is wrapped,
+// so that errors.Is and errors.As also match the panic value.
+//
+// The exception is [http.ErrAbortHandler], which is the sanctioned way to
+// abort a response and must propagate to the server: PanicError panics again
+// with this value instead of converting it. To convert it into an error
+// instead, use a custom converter that handles it before falling back to
+// PanicError.
+func PanicError(recovered interface{}) error {
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+	return recoveredError(recovered)
+}
+
+// recoveredError converts a value recovered from a panic into an error,
+// without special handling for [http.ErrAbortHandler].
+func recoveredError(recovered interface{}) error {
+	if err, isErr := recovered.(error); isErr {
+		return panicError{err, ""}
+	}
+	return panicError{nil, fmt.Sprintf("%v", recovered)}
+}
+
 // PanicMiddleware wraps a [httperror.Handler], returning a new [httperror.HandlerFunc] that
 // recovers from panics and returns them as errors. Panic error can be identified using
-// errors.Is(err, httperror.Panic)
+// errors.Is(err, httperror.Panic). Panics with [http.ErrAbortHandler] are
+// not recovered (see [httperror.PanicError]).
 func PanicMiddleware(h Handler) HandlerFunc {
+	return PanicMiddlewareWithConverter(h, PanicError)
+}
+
+// PanicMiddlewareWithConverter is like [httperror.PanicMiddleware], but uses
+// the given function to convert recovered values into errors. This can be
+// used to preserve typed panic values, to attach stack traces (the converter
+// is called in the panicking goroutine, so debug.Stack() includes the
+// panic site), or to map specific panics to specific status codes. A
+// converter can fall back to [httperror.PanicError] for other values.
+func PanicMiddlewareWithConverter(h Handler, convert PanicConverter) HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) (err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				isErr := false
-				if err, isErr = r.(error); !isErr {
-					err = panicError{nil, fmt.Sprintf("%v", r)}
-				} else {
-					err = panicError{err, ""}
-				}
+				err = convert(r)
 			}
 		}()
 
@@ -54,17 +89,19 @@ func PanicMiddleware(h Handler) HandlerFunc {
 
 // XPanicMiddleware wraps a [httperror.XHandler], returning a new [httperror.XHandlerFunc] that
 // recovers from panics and returns them as errors. Panic error can be identified using
-// errors.Is(err, httperror.Panic)
+// errors.Is(err, httperror.Panic). Panics with [http.ErrAbortHandler] are
+// not recovered (see [httperror.PanicError]).
 func XPanicMiddleware[P any](h XHandler[P]) XHandlerFunc[P] {
+	return XPanicMiddlewareWithConverter(h, PanicError)
+}
+
+// XPanicMiddlewareWithConverter is a generic version of
+// [httperror.PanicMiddlewareWithConverter].
+func XPanicMiddlewareWithConverter[P any](h XHandler[P], convert PanicConverter) XHandlerFunc[P] {
 	return func(w http.ResponseWriter, r *http.Request, p P) (err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				isErr := false
-				if err, isErr = r.(error); !isErr {
-					err = panicError{nil, fmt.Sprintf("%v", r)}
-				} else {
-					err = panicError{err, ""}
-				}
+				err = convert(r)
 			}
 		}()
 
@@ -0,0 +1,22 @@
+module github.com/johnwarden/httperror/pgerror
+
+go 1.22
+
+require (
+	github.com/jackc/pgx/v5 v5.6.0
+	github.com/johnwarden/httperror v0.0.0-20261016035624-08a2347946c7
+	github.com/lib/pq v1.10.9
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rogpeppe/go-internal v1.12.0 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
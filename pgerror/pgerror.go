@@ -0,0 +1,128 @@
+/*
+Package pgerror converts Postgres errors from the pgx
+(github.com/jackc/pgx/v5) and lib/pq (github.com/lib/pq) drivers into
+httperrors, so that repositories can return database errors directly. See
+the documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package pgerror
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/johnwarden/httperror"
+	"github.com/lib/pq"
+)
+
+// retryAfter is the delay suggested to clients for retryable errors.
+const retryAfter = time.Second
+
+// pgError holds the fields of a Postgres error that are common to the
+// drivers.
+type pgError struct {
+	code       string
+	message    string
+	constraint string
+}
+
+// fields extracts the Postgres error in the chain of err.
+func fields(err error) (pgError, bool) {
+	var pgxErr *pgconn.PgError
+	if errors.As(err, &pgxErr) {
+		return pgError{pgxErr.Code, pgxErr.Message, pgxErr.ConstraintName}, true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pgError{string(pqErr.Code), pqErr.Message, pqErr.Constraint}, true
+	}
+
+	return pgError{}, false
+}
+
+// Status returns the status code for a Postgres error in the chain of err
+// (see [Map]), or 0 if there is none or its error code is not mapped. It
+// can be registered with [httperror.RegisterSQLErrorClassifier].
+func Status(err error) int {
+	e, ok := fields(err)
+	if !ok {
+		return 0
+	}
+	s, _ := classify(e)
+	return s
+}
+
+// classify returns the status code and condition name for a Postgres
+// error, or 0 if its error code is not mapped.
+func classify(e pgError) (int, string) {
+	switch e.code {
+	case "23505":
+		return http.StatusConflict, "unique_violation"
+	case "23P01":
+		return http.StatusConflict, "exclusion_violation"
+	case "23503":
+		// Deleting or updating a referenced row conflicts with the rows
+		// referencing it. Inserting or updating a row that references a
+		// missing row is invalid input.
+		if strings.HasPrefix(e.message, "update or delete") {
+			return http.StatusConflict, "foreign_key_violation"
+		}
+		return http.StatusUnprocessableEntity, "foreign_key_violation"
+	case "23502":
+		return http.StatusUnprocessableEntity, "not_null_violation"
+	case "23514":
+		return http.StatusUnprocessableEntity, "check_violation"
+	case "40001":
+		return http.StatusServiceUnavailable, "serialization_failure"
+	case "40P01":
+		return http.StatusServiceUnavailable, "deadlock_detected"
+	}
+	return 0, ""
+}
+
+// Map converts a Postgres error in the chain of err into an httperror with
+// a status code and an error code (see [httperror.ErrorCode]) named after
+// the Postgres condition:
+//
+//   - unique_violation, exclusion_violation: 409 Conflict
+//   - foreign_key_violation: 409 Conflict when deleting or updating a
+//     referenced row, and 422 Unprocessable Entity when referencing a
+//     missing row
+//   - not_null_violation, check_violation: 422 Unprocessable Entity
+//   - serialization_failure, deadlock_detected: 503 Service Unavailable,
+//     with a Retry-After header, since the transaction can be retried
+//
+// The name of the violated constraint can be extracted with [Constraint].
+// The original error remains in the chain. Other errors, and errors that
+// already have a status code, are returned unchanged.
+func Map(err error) error {
+	if err == nil || httperror.StatusCode(err) != http.StatusInternalServerError {
+		return err
+	}
+
+	e, ok := fields(err)
+	if !ok {
+		return err
+	}
+	s, code := classify(e)
+	if s == 0 {
+		return err
+	}
+
+	err = httperror.WithCode(httperror.Wrap(err, s), code)
+	if s == http.StatusServiceUnavailable {
+		err = httperror.WithRetryAfter(err, retryAfter)
+	}
+	return err
+}
+
+// Constraint returns the name of the constraint violated by a Postgres
+// error in the chain of err, or "" if there is none.
+func Constraint(err error) string {
+	e, _ := fields(err)
+	return e.constraint
+}
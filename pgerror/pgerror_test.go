@@ -0,0 +1,53 @@
+package pgerror_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/pgerror"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}, http.StatusConflict, "unique_violation"},
+		{&pq.Error{Code: "23505", Constraint: "users_email_key"}, http.StatusConflict, "unique_violation"},
+		{&pgconn.PgError{Code: "23503", Message: `insert or update on table "orders" violates foreign key constraint "orders_user_id_fkey"`}, http.StatusUnprocessableEntity, "foreign_key_violation"},
+		{&pgconn.PgError{Code: "23503", Message: `update or delete on table "users" violates foreign key constraint "orders_user_id_fkey" on table "orders"`}, http.StatusConflict, "foreign_key_violation"},
+		{&pq.Error{Code: "23514"}, http.StatusUnprocessableEntity, "check_violation"},
+		{&pgconn.PgError{Code: "40001"}, http.StatusServiceUnavailable, "serialization_failure"},
+	}
+	for _, tt := range tests {
+		err := pgerror.Map(fmt.Errorf("create user: %w", tt.err))
+		assert.Equal(t, tt.status, httperror.StatusCode(err), tt.code)
+		assert.Equal(t, tt.code, httperror.ErrorCode(err))
+		assert.True(t, errors.Is(err, tt.err))
+	}
+
+	err := pgerror.Map(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
+	assert.Equal(t, "users_email_key", pgerror.Constraint(err))
+	assert.Empty(t, httperror.Headers(err))
+
+	err = pgerror.Map(&pq.Error{Code: "40P01"})
+	assert.Equal(t, "1", httperror.Headers(err).Get("Retry-After"))
+
+	syntaxErr := &pgconn.PgError{Code: "42601"}
+	assert.Equal(t, error(syntaxErr), pgerror.Map(syntaxErr))
+	assert.Equal(t, httperror.NotFound, pgerror.Map(httperror.NotFound))
+	assert.Nil(t, pgerror.Map(nil))
+}
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, http.StatusConflict, pgerror.Status(&pq.Error{Code: "23505"}))
+	assert.Equal(t, 0, pgerror.Status(&pq.Error{Code: "42601"}))
+	assert.Equal(t, 0, pgerror.Status(errors.New("not a postgres error")))
+}
@@ -0,0 +1,202 @@
+package httperror
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+)
+
+const contentTypeProblemJSON = "application/problem+json"
+
+// ProblemErrorHandler is an [httperror.ErrorHandler] that writes an RFC 9457
+// problem details response (application/problem+json), using the problem
+// types registered in the [httperror.DefaultCatalog].
+func ProblemErrorHandler(w http.ResponseWriter, e error) {
+	DefaultCatalog.ProblemErrorHandler(w, e)
+}
+
+// ProblemErrorHandler is an [httperror.ErrorHandler] that writes an RFC 9457
+// problem details response (application/problem+json), converting the error
+// with [httperror.Catalog.ToProblem]:
+//
+//	{"type":"/problems/out_of_credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50."}
+func (c *Catalog) ProblemErrorHandler(w http.ResponseWriter, e error) {
+	body := c.ToProblem(e)
+
+	w.Header().Set("Content-Type", contentTypeProblemJSON)
+	setErrorHeaders(w, e)
+	w.WriteHeader(body.Status)
+
+	json, _ := json.Marshal(body) // No error handling for error handling
+
+	_, _ = w.Write(json)
+	_, _ = w.Write([]byte("\n"))
+}
+
+// Problem is an RFC 9457 problem details document. Members other than the
+// standard ones are extension members, which are marshaled and unmarshaled
+// as members of the same JSON object.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// problemMembers are the standard members of a problem details document,
+// which can't be used as extension members.
+var problemMembers = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+// MarshalJSON writes the standard members, followed by the extension
+// members sorted by name. Extension members named like a standard member
+// are ignored.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type standardMembers Problem
+	b, err := json.Marshal(standardMembers(p))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(p.Extensions))
+	for name := range p.Extensions {
+		if !problemMembers[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-1])
+	for _, name := range names {
+		value, err := json.Marshal(p.Extensions[name])
+		if err != nil {
+			return nil, err
+		}
+		key, _ := json.Marshal(name)
+
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads the standard members, and the other members into
+// Extensions.
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	type standardMembers Problem
+	var standard standardMembers
+	if err := json.Unmarshal(b, &standard); err != nil {
+		return err
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	for name := range problemMembers {
+		delete(members, name)
+	}
+
+	*p = Problem(standard)
+	p.Extensions = nil
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// ToProblem converts an error into a problem details document, using the
+// problem types registered in the [httperror.DefaultCatalog] (see
+// [httperror.Catalog.ToProblem]).
+func ToProblem(err error) Problem {
+	return DefaultCatalog.ToProblem(err)
+}
+
+// ToProblem converts an error into a problem details document. If the error
+// code (see [httperror.ErrorCode]) is registered in the catalog, the type is
+// the problem type URI and the title is the registered title. Otherwise the
+// type is "about:blank", the title is the status text, and the error code
+// is written in a code extension member. The detail is the public message
+// (see [httperror.PublicMessage]). Field errors (see
+// [httperror.FieldErrors]), the request ID (see [httperror.RequestID]), and
+// the fingerprint of the original request of a reused idempotency key (see
+// [httperror.IdempotencyFingerprint]), if any, are written in errors,
+// request_id, and fingerprint extension members.
+func (c *Catalog) ToProblem(err error) Problem {
+	s := StatusCode(err)
+
+	p := Problem{
+		Type:   "about:blank",
+		Title:  statusText(s),
+		Status: s,
+		Detail: PublicMessage(err),
+	}
+
+	extensions := make(map[string]interface{})
+	if t, ok := c.Lookup(ErrorCode(err)); ok {
+		p.Type = c.TypeURI(t.Code)
+		p.Title = t.Title
+	} else if code := ErrorCode(err); code != "" {
+		extensions["code"] = code
+	}
+	if fieldErrors := FieldErrors(err); len(fieldErrors) > 0 {
+		extensions["errors"] = fieldErrors
+	}
+	if id := RequestID(err); id != "" {
+		extensions["request_id"] = id
+	}
+	if fingerprint := IdempotencyFingerprint(err); fingerprint != "" {
+		extensions["fingerprint"] = fingerprint
+	}
+	if len(extensions) > 0 {
+		p.Extensions = extensions
+	}
+	return p
+}
+
+// FromProblem converts a problem details document into an error with an
+// embedded HTTP status code, reversing [httperror.Catalog.ToProblem]. The
+// detail becomes the public message. The error code is the code extension
+// member, or else the last segment of the problem type URI. Field errors
+// and the request ID are restored from the errors and request_id extension
+// members. A problem without a status returns InternalServerError.
+func FromProblem(p Problem) error {
+	return problemError(p)
+}
+
+func problemError(p Problem) responseError {
+	s := p.Status
+	if s == 0 {
+		s = http.StatusInternalServerError
+	}
+
+	e := responseError{message: p.Detail, httpError: httpError{s}}
+	_ = problemExtension(p, "code", &e.code)
+	_ = problemExtension(p, "errors", &e.fieldErrors)
+	_ = problemExtension(p, "request_id", &e.requestID)
+	if e.code == "" && p.Type != "" && p.Type != "about:blank" {
+		e.code = path.Base(p.Type)
+	}
+	return e
+}
+
+// problemExtension decodes the named extension member into dst.
+func problemExtension(p Problem, name string, dst interface{}) error {
+	v, ok := p.Extensions[name]
+	if !ok {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
@@ -0,0 +1,96 @@
+/*
+Package promerror counts the errors handled by httperror handlers with a
+Prometheus (github.com/prometheus/client_golang) collector, for
+applications that want error metrics without full request instrumentation.
+See the documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package promerror
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/johnwarden/httperror"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector counts handled errors in the counter
+// httperror_handled_errors_total, with the labels status (the status code,
+// see [httperror.StatusCode]), code (the error code, see
+// [httperror.ErrorCode]) and route. Collector is a prometheus.Collector, so
+// it can be registered on any registry:
+//
+//	c := promerror.NewCollector(nil)
+//	prometheus.MustRegister(c)
+//
+// Errors are counted either by wrapping handlers with the middleware
+// returned by the Middleware method, or by registering the collector as an
+// [httperror.ErrorReporter]:
+//
+//	httperror.RegisterErrorReporter(c, func(error) bool { return true })
+type Collector struct {
+	route   func(*http.Request) string
+	counter *prometheus.CounterVec
+}
+
+var (
+	_ prometheus.Collector    = (*Collector)(nil)
+	_ httperror.ErrorReporter = (*Collector)(nil)
+)
+
+// NewCollector returns a Collector that derives the route label of errors
+// counted by its Report method from the request using route. If route is
+// nil, or the request is unknown, the route label is empty.
+func NewCollector(route func(*http.Request) string) *Collector {
+	return &Collector{
+		route: route,
+		counter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "httperror_handled_errors_total",
+			Help: "Number of errors returned by HTTP handlers, by status code, error code and route.",
+		}, []string{"status", "code", "route"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.counter.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.counter.Collect(ch)
+}
+
+// Observe counts err under route. It does nothing if err is nil or has a
+// status code below 400, such as a redirect (see [httperror.Redirect]).
+func (c *Collector) Observe(err error, route string) {
+	if err == nil || httperror.StatusCode(err) < 400 {
+		return
+	}
+	c.counter.WithLabelValues(strconv.Itoa(httperror.StatusCode(err)), httperror.ErrorCode(err), route).Inc()
+}
+
+// Report counts err, with the route label derived from r. It makes
+// Collector an [httperror.ErrorReporter].
+func (c *Collector) Report(ctx context.Context, r *http.Request, err error) {
+	var route string
+	if c.route != nil && r != nil {
+		route = c.route(r)
+	}
+	c.Observe(err, route)
+}
+
+// Middleware returns an [httperror.Middleware] that counts the errors
+// returned by the wrapped handler under route, and passes them on
+// unchanged.
+func (c *Collector) Middleware(route string) httperror.Middleware {
+	return func(h httperror.Handler) httperror.Handler {
+		return httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := h.Serve(w, r)
+			c.Observe(err, route)
+			return err
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package promerror_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/promerror"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectorMiddleware(t *testing.T) {
+	c := promerror.NewCollector(nil)
+
+	h := c.Middleware("/orders")(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("fail") != "" {
+			return httperror.WithCode(httperror.NotFound, "order_not_found")
+		}
+		if r.URL.Query().Get("moved") != "" {
+			return httperror.Redirect(w, r, "/orders/42", http.StatusMovedPermanently)
+		}
+		return nil
+	}))
+
+	for _, target := range []string{"/orders", "/orders?fail=1", "/orders?fail=1", "/orders?moved=1"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	expected := `
+# HELP httperror_handled_errors_total Number of errors returned by HTTP handlers, by status code, error code and route.
+# TYPE httperror_handled_errors_total counter
+httperror_handled_errors_total{code="order_not_found",route="/orders",status="404"} 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
+
+func TestCollectorReport(t *testing.T) {
+	c := promerror.NewCollector(func(r *http.Request) string { return r.URL.Path })
+
+	reg := prometheus.NewRegistry()
+	assert.NoError(t, reg.Register(c))
+
+	r := httptest.NewRequest(http.MethodGet, "/checkout", nil)
+	c.Report(context.Background(), r, httperror.InternalServerError)
+	c.Report(context.Background(), nil, httperror.BadGateway)
+	c.Report(context.Background(), r, nil)
+
+	expected := `
+# HELP httperror_handled_errors_total Number of errors returned by HTTP handlers, by status code, error code and route.
+# TYPE httperror_handled_errors_total counter
+httperror_handled_errors_total{code="",route="",status="502"} 1
+httperror_handled_errors_total{code="",route="/checkout",status="500"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected)))
+}
@@ -1,11 +1,8 @@
 package httperror
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"net/http"
-	"strconv"
 )
 
 // Public is an interface that requires a PublicMessage() string method.
@@ -35,7 +32,7 @@ func PublicMessage(err error) string {
 // error message generated using the format string and arguments. The
 // resulting error value implements the the [httperror.Public] interface.
 func NewPublic(status int, message string) error {
-	return publicError{message, httpError{status}}
+	return &publicError{message: message, httpError: httpError{status}}
 }
 
 // PublicErrorf returns a new public error with the given status code and
@@ -43,29 +40,46 @@ func NewPublic(status int, message string) error {
 // [httperror.Public] interface.
 
 func PublicErrorf(status int, format string, args ...interface{}) error {
-	return publicError{fmt.Sprintf(format, args...), httpError{status}}
+	return &publicError{message: fmt.Sprintf(format, args...), httpError: httpError{status}}
 }
 
 type publicError struct {
 	message string
 	httpError
+	text errorText
 }
 
 // Error returns the text corresponding to this HTTP error status code.
-func (e publicError) Error() string {
-	var b bytes.Buffer
+func (e *publicError) Error() string {
+	return e.text.get(func() string { return formatError(e.status, e.message) })
+}
+
+func (e *publicError) PublicMessage() string {
+	return e.message
+}
 
-	b.WriteString(strconv.Itoa(e.status))
-	b.WriteString(" ")
-	b.WriteString(http.StatusText(e.status))
+// wrapPublic wraps err with a status code and a public message. Unlike
+// [httperror.NewPublic], err remains in the chain, so errors.Is and
+// errors.As match it and Error includes its message.
+func wrapPublic(err error, status int, message string) error {
+	return &publicWrappedError{inner: err, message: message, httpError: httpError{status}}
+}
 
-	if e.message != "" {
-		b.WriteString(": ")
-		b.WriteString(e.message)
-	}
-	return b.String()
+type publicWrappedError struct {
+	inner   error
+	message string
+	httpError
+	text errorText
+}
+
+func (e *publicWrappedError) Error() string {
+	return e.text.get(func() string { return formatError(e.status, e.inner.Error()) })
+}
+
+func (e *publicWrappedError) Unwrap() error {
+	return e.inner
 }
 
-func (e publicError) PublicMessage() string {
+func (e *publicWrappedError) PublicMessage() string {
 	return e.message
 }
@@ -0,0 +1,133 @@
+package httperror
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides whether a request may proceed. Requests are grouped by
+// a key, such as the client IP address (see [httperror.Keyer]).
+type RateLimiter interface {
+	// Allow reports whether a request with the given key may proceed. If
+	// not, it also returns how long the client should wait before retrying.
+	Allow(key string) (bool, time.Duration)
+}
+
+// Keyer extracts the key used to group requests for rate limiting.
+type Keyer = func(r *http.Request) string
+
+// KeyByIP is a [httperror.Keyer] that groups requests by the IP address of
+// the client, taken from r.RemoteAddr.
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// KeyByHeader returns a [httperror.Keyer] that groups requests by the value
+// of the given request header, e.g. an API key.
+func KeyByHeader(name string) Keyer {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// RateLimitMiddleware returns an [httperror.Middleware] that limits requests
+// using limiter, grouped by key. Rejected requests return TooManyRequests
+// with a Retry-After header attached (see [httperror.WithRetryAfter])
+// instead of writing a response, so that the application's error handler
+// and logging apply to throttled requests too.
+func RateLimitMiddleware(limiter RateLimiter, key Keyer) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if ok, retryAfter := limiter.Allow(key(r)); !ok {
+				return WithRetryAfter(TooManyRequests, retryAfter)
+			}
+			return h.Serve(w, r)
+		})
+	}
+}
+
+// TokenBucketLimiter is a [httperror.RateLimiter] that implements the token
+// bucket algorithm, with a separate bucket for each key.
+type TokenBucketLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketLimiter returns a [httperror.TokenBucketLimiter] that allows
+// bursts of up to burst requests per key, and refills at rate requests per
+// second. It panics if rate or burst is not positive.
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	if !(rate > 0) {
+		panic("httperror: NewTokenBucketLimiter rate must be positive, got " + strconv.FormatFloat(rate, 'g', -1, 64))
+	}
+	if burst < 1 {
+		panic("httperror: NewTokenBucketLimiter burst must be positive, got " + strconv.Itoa(burst))
+	}
+	return &TokenBucketLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket for key, if there is one.
+func (l *TokenBucketLimiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / l.rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// sweep removes buckets that have had time to refill completely, since they
+// are equivalent to new buckets. This keeps memory bounded by the number of
+// recently active keys.
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,40 @@
+package httperror_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := httperror.NewTokenBucketLimiter(1, 2)
+	h := httperror.RateLimitMiddleware(limiter, httperror.KeyByIP)(okHandler)
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		return rr
+	}
+
+	assert.Equal(t, 200, serve("192.0.2.1:1234").Code)
+	assert.Equal(t, 200, serve("192.0.2.1:1235").Code)
+
+	rr := serve("192.0.2.1:1236")
+	assert.Equal(t, 429, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	assert.Equal(t, 200, serve("192.0.2.2:1234").Code, "other clients are not limited")
+
+	assert.PanicsWithValue(t, "httperror: NewTokenBucketLimiter rate must be positive, got 0", func() {
+		httperror.NewTokenBucketLimiter(0, 2)
+	})
+	assert.Panics(t, func() { httperror.NewTokenBucketLimiter(-1, 2) })
+	assert.PanicsWithValue(t, "httperror: NewTokenBucketLimiter burst must be positive, got 0", func() {
+		httperror.NewTokenBucketLimiter(1, 0)
+	})
+}
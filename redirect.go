@@ -0,0 +1,35 @@
+package httperror
+
+import (
+	"net/http"
+)
+
+// Redirect redirects the request to url with the given status code (which
+// should be in the 3xx range) like [http.Redirect], and returns an error with
+// the status code for the handler to return, so that middleware such as
+// [httperror.LoggingMiddleware] sees the redirect (with severity
+// SeverityInfo). Since the response has already been written, the error is
+// marked as such (see [httperror.ResponseWritten]): error handlers don't
+// render it, and [httperror.ReportError] doesn't report it, since it is not
+// a failure.
+//
+//	return httperror.Redirect(w, r, "/login", http.StatusFound)
+func Redirect(w http.ResponseWriter, r *http.Request, url string, status int) error {
+	http.Redirect(w, r, url, status)
+	return writtenError{httpError{status}}
+}
+
+// SeeOther redirects the request to url with 303 See Other, for example
+// after a successful POST. See [httperror.Redirect].
+func SeeOther(w http.ResponseWriter, r *http.Request, url string) error {
+	return Redirect(w, r, url, http.StatusSeeOther)
+}
+
+// RedirectHandler returns a [httperror.HandlerFunc] that redirects every
+// request to url with the given status code, like [http.RedirectHandler].
+// See [httperror.Redirect].
+func RedirectHandler(url string, status int) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return Redirect(w, r, url, status)
+	}
+}
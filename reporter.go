@@ -0,0 +1,83 @@
+package httperror
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrorReporter reports errors to an error tracking or alerting service,
+// separately from rendering the error response.
+type ErrorReporter interface {
+	Report(ctx context.Context, r *http.Request, err error)
+}
+
+// ErrorReporterFunc is an adapter that allows the use of an ordinary
+// function as an [httperror.ErrorReporter].
+type ErrorReporterFunc func(ctx context.Context, r *http.Request, err error)
+
+// Report calls f(ctx, r, err).
+func (f ErrorReporterFunc) Report(ctx context.Context, r *http.Request, err error) {
+	f(ctx, r, err)
+}
+
+type registeredReporter struct {
+	reporter ErrorReporter
+	when     func(error) bool
+}
+
+var (
+	reportersMu sync.Mutex
+	reporters   atomic.Pointer[[]registeredReporter]
+)
+
+// RegisterErrorReporter registers a reporter that is called for errors for
+// which when returns true. If when is nil, errors with severity
+// SeverityError (server errors and panics, see [httperror.ErrorSeverity])
+// are reported.
+//
+// Registered reporters are called by [httperror.ReportError], which the
+// handlers in this package call whenever they pass an error to an error
+// handler (such as [httperror.DefaultErrorHandler]), including errors whose
+// response has already been written (see [httperror.ResponseWritten]). So
+// alerting is decoupled from rendering: error handlers don't need to know
+// about reporters.
+func RegisterErrorReporter(reporter ErrorReporter, when func(error) bool) {
+	if when == nil {
+		when = func(err error) bool {
+			return ErrorSeverity(err) == SeverityError
+		}
+	}
+
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+
+	var rs []registeredReporter
+	if p := reporters.Load(); p != nil {
+		rs = append(rs, *p...)
+	}
+	rs = append(rs, registeredReporter{reporter, when})
+	reporters.Store(&rs)
+}
+
+// ReportError calls the reporters registered with
+// [httperror.RegisterErrorReporter] whose predicates match err. Call it
+// from custom handler wrappers that handle errors without using the
+// handlers in this package. Errors with a non-error status, such as
+// [httperror.NotModified], are not reported.
+func ReportError(ctx context.Context, r *http.Request, err error) {
+	if err == nil || StatusCode(err) < 400 {
+		return
+	}
+
+	p := reporters.Load()
+	if p == nil {
+		return
+	}
+	for _, rr := range *p {
+		if rr.when(err) {
+			rr.reporter.Report(ctx, r, err)
+		}
+	}
+}
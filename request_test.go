@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/johnwarden/httperror"
 
@@ -48,6 +49,139 @@ func TestCustomErrorHandler(t *testing.T) {
 	assert.Equal(t, "400 Sorry, we couldn't parse your request: missing 'name' parameter\n", m, "got custom error message")
 }
 
+func TestWithErrorHandler(t *testing.T) {
+	h := httperror.WithErrorHandler(httperror.HandlerFunc(helloHandler), customErrorHandler)
+
+	s, m := testRequest(h, "/")
+	assert.Equal(t, 400, s, "got 400 Bad request response")
+	assert.Equal(t, "400 Sorry, we couldn't parse your request: missing 'name' parameter\n", m, "got custom error message")
+
+	rr := httptest.NewRecorder()
+	err := h.Serve(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Nil(t, err, "error is handled by the route's error handler")
+	assert.Equal(t, 400, rr.Code)
+}
+
+func TestErrorHandlerRouter(t *testing.T) {
+	router := httperror.NewErrorHandlerRouter(nil).
+		PathPrefix("/api/", httperror.ProblemErrorHandler).
+		Host("Custom.example.com", customErrorHandler)
+
+	h := router.Wrap(httperror.HandlerFunc(helloHandler))
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+
+	{
+		rr := serve("/api/hello")
+		assert.Equal(t, 400, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"), "path prefix route")
+	}
+
+	{
+		rr := serve("http://custom.example.com:8080/hello")
+		assert.Equal(t, 400, rr.Code)
+		assert.Equal(t, "400 Sorry, we couldn't parse your request: missing 'name' parameter\n", rr.Body.String(), "host route ignores case and port")
+	}
+
+	{
+		rr := serve("/hello")
+		assert.Equal(t, 400, rr.Code)
+		assert.Equal(t, "400 Bad Request: missing 'name' parameter\n", rr.Body.String(), "fallback")
+	}
+
+	{
+		s, _ := testRequest(httperror.WrapHandler(httperror.HandlerFunc(helloHandler), nil,
+			httperror.WithRequestErrorHandler(router.HandleError)), "/api/hello")
+		assert.Equal(t, 400, s, "usable as a request error handler")
+	}
+}
+
+func TestWrapHandler(t *testing.T) {
+	{
+		s, m := testRequest(httperror.WrapHandler(httperror.HandlerFunc(helloHandler), customErrorHandler), "/")
+		assert.Equal(t, 400, s, "got 400 Bad request response")
+		assert.Equal(t, "400 Sorry, we couldn't parse your request: missing 'name' parameter\n", m, "got custom error message")
+	}
+
+	{
+		s, _ := testRequest(httperror.WrapHandler(notFoundHandler, nil), "/")
+		assert.Equal(t, 404, s, "nil error handler falls back to the default")
+	}
+
+	{
+		var e error
+		h := httperror.WrapHandler(getMeOuttaHere, nil,
+			httperror.WithPanicRecovery(true),
+			httperror.WithRequestErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+				e = err
+				w.Header().Set("X-Path", r.URL.Path)
+				httperror.DefaultErrorHandler(w, err)
+			}),
+		)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", "/panic", nil))
+		assert.Equal(t, 500, rr.Code)
+		assert.Equal(t, "/panic", rr.Header().Get("X-Path"))
+		assert.True(t, errors.Is(e, httperror.Panic))
+	}
+
+	{
+		h := httperror.WrapHandler(getMeOuttaHere, nil, httperror.WithPanicRecovery(false))
+		assert.Panics(t, func() { testRequest(h, "/") })
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	{
+		var entries []httperror.LogEntry
+		h := httperror.NewHandler(getMeOuttaHere,
+			httperror.WithErrorHandlerFunc(customErrorHandler),
+			httperror.WithPanicRecovery(true),
+			httperror.WithLogger(httperror.LoggerFunc(func(e httperror.LogEntry) {
+				entries = append(entries, e)
+			})),
+		)
+
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 500, s)
+		assert.Len(t, entries, 1, "recovered panic is logged")
+		assert.True(t, errors.Is(entries[0].Err, httperror.Panic))
+	}
+
+	{
+		h := httperror.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+			<-r.Context().Done()
+			return r.Context().Err()
+		}, httperror.WithTimeout(time.Millisecond))
+
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 504, s, "deadline exceeded is a 504")
+	}
+
+	{
+		h := httperror.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+			_, err := io.ReadAll(r.Body)
+			return err
+		}, httperror.WithMaxBytes(4))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", "/", strings.NewReader("too long")))
+		assert.Equal(t, 413, rr.Code, "body over the limit is a 413")
+
+		rr = httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/", strings.NewReader("ok"))
+		body := r.Body
+		h.ServeHTTP(rr, r)
+		assert.Equal(t, 200, rr.Code)
+		assert.Equal(t, body, r.Body, "caller's request is not modified")
+	}
+}
+
 func TestPanic(t *testing.T) {
 	{
 		h := getMeOuttaHere
@@ -86,6 +220,68 @@ func TestPanic(t *testing.T) {
 	}
 }
 
+func TestPanicMiddlewareWithConverter(t *testing.T) {
+	convert := func(recovered interface{}) error {
+		if recovered == sentinalError {
+			return httperror.Wrap(sentinalError, http.StatusServiceUnavailable)
+		}
+		return httperror.PanicError(recovered)
+	}
+
+	{
+		h := httperror.PanicMiddlewareWithConverter(fail, convert)
+
+		var e error
+		errorHandler := func(w http.ResponseWriter, err error) {
+			e = err
+			httperror.DefaultErrorHandler(w, err)
+		}
+
+		s, _ := testRequest(httperror.WrapHandlerFunc(h, errorHandler), "/")
+		assert.Equal(t, 503, s)
+		assert.False(t, errors.Is(e, httperror.Panic))
+		assert.True(t, errors.Is(e, sentinalError))
+	}
+
+	{
+		h := httperror.PanicMiddlewareWithConverter(getMeOuttaHere, convert)
+
+		var e error
+		errorHandler := func(w http.ResponseWriter, err error) {
+			e = err
+			httperror.DefaultErrorHandler(w, err)
+		}
+
+		s, _ := testRequest(httperror.WrapHandlerFunc(h, errorHandler), "/")
+		assert.Equal(t, 500, s)
+		assert.True(t, errors.Is(e, httperror.Panic))
+		assert.Equal(t, "panic: Get me outta here!", e.Error())
+	}
+}
+
+func TestPanicErrAbortHandler(t *testing.T) {
+	abort := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	{
+		h := httperror.PanicMiddleware(abort)
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { testRequest(h, "/") })
+	}
+
+	{
+		h := httperror.PanicMiddlewareWithConverter(abort, func(recovered interface{}) error {
+			if recovered == http.ErrAbortHandler {
+				return httperror.Wrap(http.ErrAbortHandler, http.StatusServiceUnavailable)
+			}
+			return httperror.PanicError(recovered)
+		})
+
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 503, s)
+	}
+}
+
 func TestApplyStandardMiddleware(t *testing.T) {
 	{
 		h := httperror.ApplyStandardMiddleware(okHandler, myMiddleware)
@@ -111,6 +307,201 @@ func TestApplyStandardMiddleware(t *testing.T) {
 		assert.Equal(t, 200, s)
 		assert.Equal(t, "Hello, Bill\n", m, "got middleware output")
 	}
+
+	{
+		// Pooled carriers don't leak errors into later requests.
+		h := httperror.ApplyStandardMiddleware(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.URL.Path == "/missing" {
+				return httperror.NotFound
+			}
+			return nil
+		}), myMiddleware)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, httperror.NotFound, h.Serve(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil)))
+			assert.Nil(t, h.Serve(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)))
+		}
+	}
+
+	{
+		// A writer kept by a handler after it returns still writes to its
+		// own response, not to that of a later request.
+		var kept http.ResponseWriter
+		h := httperror.ApplyStandardMiddleware(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if kept == nil {
+				kept = w
+				return nil
+			}
+			_, _ = kept.Write([]byte("late"))
+			return nil
+		}), passThrough)
+
+		first, second := httptest.NewRecorder(), httptest.NewRecorder()
+		assert.Nil(t, h.Serve(first, httptest.NewRequest("GET", "/", nil)))
+		assert.Nil(t, h.Serve(second, httptest.NewRequest("GET", "/", nil)))
+		assert.Equal(t, "late", first.Body.String())
+		assert.Equal(t, "", second.Body.String())
+	}
+}
+
+func TestApplyStandardMiddlewareShortCircuit(t *testing.T) {
+	deny := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "denied", http.StatusForbidden)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	h := httperror.ApplyStandardMiddleware(okHandler, deny)
+
+	{
+		rr := httptest.NewRecorder()
+		err := h.Serve(rr, httptest.NewRequest("GET", "/", nil))
+		assert.True(t, errors.Is(err, httperror.Forbidden), "short circuit is returned as an error")
+		assert.True(t, httperror.ResponseWritten(err))
+		assert.Equal(t, "403 Forbidden", err.Error())
+		assert.Equal(t, 403, rr.Code)
+	}
+
+	{
+		var logged error
+		s, m := testRequest(httperror.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := h.Serve(w, r)
+			logged = err
+			return err
+		}, customErrorHandler), "/")
+		assert.Equal(t, 403, s)
+		assert.Equal(t, "denied\n", m, "error handler doesn't write a second response")
+		assert.True(t, errors.Is(logged, httperror.Forbidden))
+	}
+
+	{
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer x")
+		rr := httptest.NewRecorder()
+		assert.Nil(t, h.Serve(rr, r))
+		assert.Equal(t, 200, rr.Code)
+	}
+}
+
+func TestApplyStandardMiddlewareWrittenStatus(t *testing.T) {
+	// rejectOrigin calls the next handler with a buffered writer, then
+	// rejects the response, like a CORS middleware checking the response.
+	rejectOrigin := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+		})
+	}
+
+	{
+		rr := httptest.NewRecorder()
+		err := httperror.ApplyStandardMiddleware(okHandler, rejectOrigin).Serve(rr, httptest.NewRequest("GET", "/", nil))
+		assert.True(t, errors.Is(err, httperror.Forbidden), "status written by middleware after calling the inner handler is returned as an error")
+		assert.True(t, httperror.ResponseWritten(err))
+		assert.Equal(t, 403, rr.Code)
+	}
+
+	{
+		rr := httptest.NewRecorder()
+		err := httperror.ApplyStandardMiddleware(notFoundHandler, rejectOrigin).Serve(rr, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, httperror.NotFound, err, "error returned by the inner handler takes precedence")
+	}
+
+	{
+		inner := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			http.Error(w, "gone", http.StatusGone)
+			return nil
+		})
+		rr := httptest.NewRecorder()
+		err := httperror.ApplyStandardMiddleware(inner, passThrough).Serve(rr, httptest.NewRequest("GET", "/", nil))
+		assert.Nil(t, err, "status written by the inner handler is not an error")
+		assert.Equal(t, 410, rr.Code)
+	}
+}
+
+func TestApplyStandardMiddlewareTimeoutHandler(t *testing.T) {
+	// Run with -race: the inner handler keeps running in its own goroutine
+	// after http.TimeoutHandler has written the response.
+	release := make(chan struct{})
+	done := make(chan struct{})
+	inner := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		defer close(done)
+		<-release
+		return nil
+	})
+	timeout := func(h http.Handler) http.Handler {
+		return http.TimeoutHandler(h, 10*time.Millisecond, "timeout")
+	}
+
+	rr := httptest.NewRecorder()
+	err := httperror.ApplyStandardMiddleware(inner, timeout).Serve(rr, httptest.NewRequest("GET", "/", nil))
+	assert.True(t, errors.Is(err, httperror.ServiceUnavailable))
+	assert.True(t, httperror.ResponseWritten(err))
+	assert.Equal(t, 503, rr.Code)
+
+	close(release)
+	<-done
+}
+
+// discardWriter is an http.ResponseWriter that discards everything, so that
+// benchmarks measure the handler and not the recorder.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header         { return w.header }
+func (w discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w discardWriter) WriteHeader(int)             {}
+
+var passThrough = func(h http.Handler) http.Handler { return h }
+
+func BenchmarkApplyStandardMiddleware(b *testing.B) {
+	h := httperror.ApplyStandardMiddleware(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httperror.NotFound
+	}), passThrough)
+
+	w := discardWriter{make(http.Header)}
+	r := httptest.NewRequest("GET", "/", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = h.Serve(w, r)
+	}
+}
+
+func BenchmarkXApplyStandardMiddleware(b *testing.B) {
+	h := httperror.XApplyStandardMiddleware[string](httperror.XHandlerFunc[string](func(w http.ResponseWriter, r *http.Request, name string) error {
+		return httperror.NotFound
+	}), passThrough)
+
+	w := discardWriter{make(http.Header)}
+	r := httptest.NewRequest("GET", "/", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = h.Serve(w, r, "Bill")
+	}
+}
+
+func BenchmarkDefaultErrorHandler(b *testing.B) {
+	err := httperror.NewPublic(http.StatusBadRequest, "missing 'name' parameter")
+
+	for _, contentType := range []string{"text/html", "text/plain", "application/json"} {
+		b.Run(contentType, func(b *testing.B) {
+			w := discardWriter{http.Header{"Content-Type": {contentType}}}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				httperror.DefaultErrorHandler(w, err)
+			}
+		})
+	}
 }
 
 var sentinalError = fmt.Errorf("SOME_ERROR")
@@ -187,3 +578,64 @@ func myMiddleware(h http.Handler) http.Handler {
 		h.ServeHTTP(w, r)
 	})
 }
+
+func TestXHandlerWithProvider(t *testing.T) {
+	h := httperror.XHandlerWithProvider[string](nameHandler, func(r *http.Request) (string, error) {
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			return "", errors.New("missing name")
+		}
+		return name, nil
+	})
+
+	{
+		s, m := testRequest(h, "/?name=Bill")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "Hello, Bill\n", m, "parameter is derived from the request")
+	}
+
+	{
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 400, s, "provider errors are bad requests")
+	}
+
+	{
+		rr := httptest.NewRecorder()
+		assert.Nil(t, h.Serve(rr, httptest.NewRequest("GET", "/", nil), "Alice"))
+		assert.Equal(t, "Hello, Alice\n", rr.Body.String(), "Serve passes the parameter through")
+	}
+}
+
+func TestBind(t *testing.T) {
+	var e error
+	h := httperror.WrapHandlerFunc(httperror.Bind[string](nameHandler, func(r *http.Request) (string, error) {
+		switch name := r.URL.Query().Get("name"); name {
+		case "":
+			return "", errors.New("missing name")
+		case "root":
+			return "", httperror.Forbidden
+		default:
+			return name, nil
+		}
+	}).Serve, func(w http.ResponseWriter, err error) {
+		e = err
+		httperror.DefaultErrorHandler(w, err)
+	})
+
+	{
+		s, m := testRequest(h, "/?name=Bill")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "Hello, Bill\n", m)
+	}
+
+	{
+		s, _ := testRequest(h, "/")
+		assert.Equal(t, 400, s, "extraction failures are bad requests")
+		assert.Equal(t, "400 Bad Request: missing name", e.Error())
+	}
+
+	{
+		s, _ := testRequest(h, "/?name=root")
+		assert.Equal(t, 403, s, "status codes of extraction errors are kept")
+	}
+}
@@ -0,0 +1,258 @@
+package httperror
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxErrorBodyBytes is the default limit on how much of an error response
+// body [httperror.FromResponse] reads.
+const maxErrorBodyBytes = 64 << 10
+
+// ResponseOption configures [httperror.FromResponse].
+type ResponseOption func(*responseOptions)
+
+type responseOptions struct {
+	captureBytes int64
+}
+
+// CaptureBytes limits how much of an error response body
+// [httperror.FromResponse] reads to n bytes, instead of 64 KiB. Bodies
+// longer than that are parsed only if the error format can be recognized
+// from the first n bytes.
+func CaptureBytes(n int64) ResponseOption {
+	return func(o *responseOptions) {
+		o.captureBytes = n
+	}
+}
+
+// FromResponse returns nil if resp has a 2xx status code, and otherwise an
+// error with the status code of the response, so that for example
+// errors.Is(err, httperror.NotFound) is true for a 404 response.
+//
+// The body is parsed in the formats written by the error handlers in this
+// package, so that errors round-trip between services: problem+json (see
+// [httperror.ProblemErrorHandler]), and JSON or JSend, plain text, and HTML
+// (see [httperror.DefaultErrorHandler]). The public message (see
+// [httperror.PublicMessage]), error code (see [httperror.ErrorCode]), and
+// field errors (see [httperror.FieldErrors]) are reconstructed from the
+// body, and the request ID (see [httperror.RequestID]) from a request_id
+// member of the body or the X-Request-Id response header. A Retry-After
+// header of the response is attached to the error (see
+// [httperror.Headers]).
+//
+// FromResponse reads at most 64 KiB of the body (see
+// [httperror.CaptureBytes]), which can be extracted from the error with
+// [httperror.ResponseBody], and doesn't close it: the body can still be read,
+// or drained, from the start after FromResponse returns.
+func FromResponse(resp *http.Response, opts ...ResponseOption) error {
+	if resp == nil || resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	o := responseOptions{captureBytes: maxErrorBodyBytes}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, o.captureBytes))
+		resp.Body = rewoundBody{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	}
+
+	e := parseErrorBody(resp, body)
+	e.httpError = httpError{resp.StatusCode}
+	e.body = body
+	if e.requestID == "" {
+		e.requestID = resp.Header.Get(requestIDHeader)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		e.header = http.Header{"Retry-After": {retryAfter}}
+	}
+	return e
+}
+
+// RequestID extracts the request ID from errors that have a
+// `RequestID() string` method, such as the errors returned by
+// [httperror.FromResponse].
+func RequestID(err error) string {
+	var e interface{ RequestID() string }
+	if errors.As(err, &e) {
+		return e.RequestID()
+	}
+	return ""
+}
+
+// ResponseBody extracts the beginning of the error response body captured
+// by [httperror.FromResponse], from errors that have a
+// `ResponseBody() []byte` method.
+func ResponseBody(err error) []byte {
+	var e interface{ ResponseBody() []byte }
+	if errors.As(err, &e) {
+		return e.ResponseBody()
+	}
+	return nil
+}
+
+// responseError is an error response received by a client.
+type responseError struct {
+	body        []byte
+	message     string
+	code        string
+	fieldErrors []FieldError
+	requestID   string
+	header      http.Header
+	httpError
+}
+
+// Error returns the status text followed by the public message, or the list
+// of invalid fields if there is none.
+func (e responseError) Error() string {
+	if e.message == "" && len(e.fieldErrors) > 0 {
+		return validationError{e.fieldErrors, e.httpError}.Error()
+	}
+	return formatError(e.status, e.message)
+}
+
+// Is returns true if the target error is a status error with the same HTTP
+// status code, or an error created by [httperror.WithCode] with the same
+// error code, just like the error returned by the server.
+func (e responseError) Is(target error) bool {
+	if t, ok := target.(codedError); ok {
+		return e.code != "" && e.code == t.code
+	}
+	return e.httpError.Is(target)
+}
+
+func (e responseError) PublicMessage() string {
+	return e.message
+}
+
+func (e responseError) ErrorCode() string {
+	return e.code
+}
+
+func (e responseError) FieldErrors() []FieldError {
+	return e.fieldErrors
+}
+
+func (e responseError) RequestID() string {
+	return e.requestID
+}
+
+func (e responseError) ResponseBody() []byte {
+	return e.body
+}
+
+func (e responseError) Headers() http.Header {
+	return e.header
+}
+
+// rewoundBody is a response body whose beginning has been read and is
+// read again.
+type rewoundBody struct {
+	io.Reader
+	io.Closer
+}
+
+// parseErrorBody reconstructs the error described by an error response
+// body, without the status code.
+func parseErrorBody(resp *http.Response, body []byte) responseError {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	switch {
+	case mediaType == contentTypeProblemJSON:
+		return parseProblem(body)
+	case mediaType == contentTypeJSON || strings.HasSuffix(mediaType, "+json"):
+		return parseJSend(resp.StatusCode, body)
+	case mediaType == contentTypeTextPlain || mediaType == contentTypeText:
+		m := strings.TrimSpace(string(body))
+		m = strings.TrimPrefix(m, strconv.Itoa(resp.StatusCode)+" ")
+		return responseError{message: trimStatusText(resp.StatusCode, m)}
+	case mediaType == "text/html" || mediaType == "":
+		return parseHTML(resp.StatusCode, body)
+	}
+	return responseError{}
+}
+
+// parseHTML parses the message from an HTML body written by
+// [httperror.DefaultErrorHandler]. Other HTML bodies have no message.
+func parseHTML(s int, body []byte) responseError {
+	const start, end = "</title></head><body>", "</body></html>"
+
+	if !bytes.HasPrefix(body, []byte("<html><head>")) {
+		return responseError{}
+	}
+	i, j := bytes.Index(body, []byte(start)), bytes.LastIndex(body, []byte(end))
+	if i < 0 || j < i+len(start) {
+		return responseError{}
+	}
+	return responseError{message: trimStatusText(s, string(body[i+len(start):j]))}
+}
+
+// parseProblem parses a problem+json body (see [httperror.FromProblem]).
+func parseProblem(body []byte) responseError {
+	var p Problem
+	if json.Unmarshal(body, &p) != nil {
+		return responseError{}
+	}
+	return problemError(p)
+}
+
+type jsendBody struct {
+	Status    string          `json:"status"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+	RequestID string          `json:"request_id"`
+}
+
+// parseJSend parses a JSON body: either a JSend error response, as written
+// by [httperror.DefaultErrorHandler], whose data member holds the error
+// code and field errors, or a JSend fail response, whose data member maps
+// the invalid fields to messages.
+func parseJSend(s int, body []byte) responseError {
+	var j jsendBody
+	if json.Unmarshal(body, &j) != nil {
+		return responseError{}
+	}
+
+	e := responseError{message: trimStatusText(s, j.Message), requestID: j.RequestID}
+
+	if j.Status == "fail" {
+		var fields map[string]json.RawMessage
+		_ = json.Unmarshal(j.Data, &fields)
+		for field, raw := range fields {
+			var message string
+			if json.Unmarshal(raw, &message) == nil {
+				e.fieldErrors = append(e.fieldErrors, FieldError{Field: field, Message: message})
+			}
+		}
+		sort.Slice(e.fieldErrors, func(i, k int) bool { return e.fieldErrors[i].Field < e.fieldErrors[k].Field })
+		return e
+	}
+
+	var data jsonErrorData
+	if json.Unmarshal(j.Data, &data) == nil {
+		e.code = data.Code
+		e.fieldErrors = data.Errors
+	}
+	return e
+}
+
+// trimStatusText removes the status text that the error handlers in this
+// package write in front of the public message (e.g. "Not Found: ").
+func trimStatusText(s int, m string) string {
+	text := statusText(s)
+	if m == text {
+		return ""
+	}
+	return strings.TrimPrefix(m, text+": ")
+}
@@ -0,0 +1,136 @@
+package httperror
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// ResponseWriter wraps an [http.ResponseWriter] and records the status code
+// and the number of body bytes written through it, so that middleware can
+// observe what the handler actually sent alongside the returned error.
+//
+//	rw := httperror.NewResponseWriter(w)
+//	err := h.Serve(rw, r)
+//	if err == nil && rw.Status() >= 400 {
+//		// the handler wrote an error response itself
+//	}
+//
+// The status is recorded atomically, so that it can be read while another
+// goroutine writes the response, for example the inner handler of an
+// [http.TimeoutHandler] after it has timed out.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status atomic.Int32
+	bytes  int64
+}
+
+// NewResponseWriter returns a ResponseWriter wrapping w. If w is already a
+// *ResponseWriter, it is returned as is, so that nested middleware share
+// the same record.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// Status returns the status code written, 200 if the body was written
+// without calling WriteHeader, or 0 if nothing has been written yet.
+// Informational (1xx) statuses other than 101 Switching Protocols are not
+// recorded.
+func (rw *ResponseWriter) Status() int {
+	return int(rw.status.Load())
+}
+
+// BytesWritten returns the number of body bytes written.
+func (rw *ResponseWriter) BytesWritten() int64 {
+	return rw.bytes
+}
+
+// Written reports whether the status code has been written, after which
+// the headers can no longer be changed.
+func (rw *ResponseWriter) Written() bool {
+	return rw.status.Load() != 0
+}
+
+// WriteHeader records the status code and writes it to the underlying
+// ResponseWriter.
+func (rw *ResponseWriter) WriteHeader(s int) {
+	if s >= 200 || s == http.StatusSwitchingProtocols {
+		rw.status.CompareAndSwap(0, int32(s))
+	}
+	rw.ResponseWriter.WriteHeader(s)
+}
+
+// Write records the number of bytes written and writes them to the
+// underlying ResponseWriter.
+func (rw *ResponseWriter) Write(b []byte) (int, error) {
+	rw.status.CompareAndSwap(0, http.StatusOK)
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush flushes the underlying ResponseWriter, if it supports it.
+func (rw *ResponseWriter) Flush() {
+	rw.status.CompareAndSwap(0, http.StatusOK)
+	flush(rw.ResponseWriter)
+}
+
+// ReadFrom copies from src to the response, using the ReadFrom method of
+// the underlying ResponseWriter if it has one (see [io.ReaderFrom]), and
+// records the number of bytes written.
+func (rw *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	rw.status.CompareAndSwap(0, http.StatusOK)
+	n, err := readFrom(rw.ResponseWriter, src)
+	rw.bytes += n
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by
+// [http.ResponseController].
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Unwrap returns the original ResponseWriter wrapped by w, by calling the
+// Unwrap method of w and of the writers it wraps (as used by
+// [http.ResponseController]), until it reaches one without an Unwrap
+// method.
+//
+// The ResponseWriter wrappers in this package implement [http.Flusher] and
+// [io.ReaderFrom] by delegating to the underlying writer: if it doesn't
+// support them, Flush does nothing and ReadFrom falls back to Write. They
+// don't implement [http.Hijacker] or [http.Pusher], since they can't know
+// whether the underlying writer does. Use [http.ResponseController], which
+// calls Unwrap, to hijack the connection or push resources through them.
+func Unwrap(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		w = u.Unwrap()
+	}
+}
+
+// flush flushes w, if it (or a writer it wraps) supports it.
+func flush(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).Flush()
+}
+
+// readFrom copies from src to w, using the ReadFrom method of w if it has
+// one (for example to use sendfile).
+func readFrom(w http.ResponseWriter, src io.Reader) (int64, error) {
+	if rf, ok := w.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+	return io.Copy(writerOnly{w}, src)
+}
+
+// writerOnly hides all methods of a writer other than Write, so that
+// io.Copy doesn't call ReadFrom recursively.
+type writerOnly struct {
+	io.Writer
+}
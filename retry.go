@@ -0,0 +1,184 @@
+package httperror
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IsRetryable reports whether a request that failed with err may succeed if
+// it is retried: if err has a status code that this package's middleware
+// uses for temporary conditions (408 Request Timeout, 425 Too Early, 429 Too
+// Many Requests, 502 Bad Gateway, 503 Service Unavailable, and 504 Gateway
+// Timeout), or a Retry-After header attached (see [httperror.RetryAfter]).
+func IsRetryable(err error) bool {
+	if err == nil || !HasStatusCode(err) {
+		return false
+	}
+
+	switch StatusCode(err) {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	_, ok := RetryAfter(err)
+	return ok
+}
+
+// RetryAfter returns the delay of the Retry-After header attached to err
+// (see [httperror.WithRetryAfter] and [httperror.FromResponse]), given as a
+// number of seconds or an HTTP date.
+func RetryAfter(err error) (time.Duration, bool) {
+	return parseRetryAfter(Headers(err).Get("Retry-After"))
+}
+
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// RetryConfig configures [httperror.RetryTransport]. Zero values use the
+// defaults.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// The default is 3.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, which doubles on each
+	// retry up to MaxDelay. The actual delay is chosen at random between
+	// zero and that value. The default is 100ms.
+	BaseDelay time.Duration
+
+	// MaxDelay is the maximum delay between attempts. A response whose
+	// Retry-After delay exceeds MaxDelay is returned without retrying. The
+	// default is 10s.
+	MaxDelay time.Duration
+}
+
+// RetryTransport returns an [http.RoundTripper] that retries requests made
+// with next (or [http.DefaultTransport] if next is nil) when the response,
+// converted to an error with [httperror.FromResponse], is retryable (see
+// [httperror.IsRetryable]), or when next returns an error that is
+// retryable once converted with [httperror.FromTransportError] (such as a
+// refused connection or a timeout, but not a TLS or malformed URL error),
+// until the request context is done:
+//
+//	client := &http.Client{Transport: httperror.RetryTransport(nil, httperror.RetryConfig{})}
+//
+// Retries wait for the delay of the Retry-After header of the response, or
+// else for an exponential backoff with jitter. Only requests that can be
+// safely repeated are retried: requests with an idempotent method, or with
+// an Idempotency-Key header (see [httperror.IdempotencyMiddleware]), whose
+// body, if any, can be recreated with GetBody.
+func RetryTransport(next http.RoundTripper, cfg RetryConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = 100 * time.Millisecond
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 10 * time.Second
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if !replayable(req) {
+			return next.RoundTrip(req)
+		}
+
+		attemptReq := req
+		for attempt := 1; ; attempt++ {
+			resp, err := next.RoundTrip(attemptReq)
+			if attempt == cfg.MaxAttempts || req.Context().Err() != nil {
+				return resp, err
+			}
+
+			delay := cfg.backoff(attempt)
+			if err != nil && !IsRetryable(FromTransportError(err)) {
+				return resp, err
+			}
+			if err == nil {
+				respErr := FromResponse(resp)
+				if !IsRetryable(respErr) {
+					return resp, nil
+				}
+				if d, ok := RetryAfter(respErr); ok {
+					if d > cfg.MaxDelay {
+						return resp, nil
+					}
+					delay = d
+				}
+				_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodyBytes))
+				resp.Body.Close()
+			}
+
+			if err := sleep(req.Context(), delay); err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			if req.Body != nil && req.Body != http.NoBody {
+				if attemptReq.Body, err = req.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	})
+}
+
+// backoff returns the jittered delay before the retry following the given
+// attempt.
+func (cfg RetryConfig) backoff(attempt int) time.Duration {
+	d := cfg.BaseDelay
+	for i := 1; i < attempt && d < cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	return rand.N(min(d, cfg.MaxDelay) + 1)
+}
+
+// replayable reports whether req can be safely sent again.
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
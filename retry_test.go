@@ -0,0 +1,130 @@
+package httperror_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, httperror.IsRetryable(httperror.ServiceUnavailable))
+	assert.True(t, httperror.IsRetryable(httperror.TooManyRequests))
+	assert.True(t, httperror.IsRetryable(httperror.WithRetryAfter(httperror.Conflict, time.Second)))
+	assert.False(t, httperror.IsRetryable(httperror.NotFound))
+	assert.False(t, httperror.IsRetryable(httperror.InternalServerError))
+	assert.False(t, httperror.IsRetryable(io.EOF))
+	assert.False(t, httperror.IsRetryable(nil))
+
+	d, ok := httperror.RetryAfter(httperror.WithRetryAfter(httperror.ServiceUnavailable, 1500*time.Millisecond))
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+
+	_, ok = httperror.RetryAfter(httperror.ServiceUnavailable)
+	assert.False(t, ok)
+}
+
+func TestRetryTransport(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		body, _ := io.ReadAll(r.Body)
+		switch calls.Add(1) {
+		case 1:
+			return httperror.WithRetryAfter(httperror.ServiceUnavailable, 0)
+		case 2:
+			return httperror.TooManyRequests
+		}
+		w.Write(body)
+		return nil
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: httperror.RetryTransport(nil, httperror.RetryConfig{BaseDelay: time.Millisecond})}
+
+	resp, err := client.Post(ts.URL, "text/plain", strings.NewReader("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, 503, resp.StatusCode, "POST requests are not retried")
+	assert.Equal(t, int32(1), calls.Load())
+	resp.Body.Close()
+
+	req, _ := http.NewRequest("PUT", ts.URL, strings.NewReader("hello"))
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	resp, err = client.Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	calls.Store(0)
+	client = &http.Client{Transport: httperror.RetryTransport(nil, httperror.RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond})}
+	resp, err = client.Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 429, resp.StatusCode)
+	assert.True(t, httperror.IsRetryable(httperror.FromResponse(resp)))
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		calls.Add(1)
+		return httperror.WithRetryAfter(httperror.ServiceUnavailable, time.Minute)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: httperror.RetryTransport(nil, httperror.RetryConfig{MaxDelay: time.Second})}
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load(), "a Retry-After beyond MaxDelay is not waited for")
+
+	client = &http.Client{Transport: httperror.RetryTransport(nil, httperror.RetryConfig{MaxDelay: time.Hour})}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL, nil)
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err   error
+		calls int32
+	}{
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, 3},
+		{context.DeadlineExceeded, 3},
+		{errors.New("tls: failed to verify certificate"), 1},
+		{errors.New("unsupported protocol scheme"), 1},
+	} {
+		var calls atomic.Int32
+		next := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			calls.Add(1)
+			return nil, tc.err
+		})
+
+		rt := httperror.RetryTransport(next, httperror.RetryConfig{BaseDelay: time.Millisecond})
+		_, err := rt.RoundTrip(httptest.NewRequest("GET", "http://example.com/", nil))
+		assert.Equal(t, tc.err, err)
+		assert.Equal(t, tc.calls, calls.Load(), "%v", tc.err)
+	}
+}
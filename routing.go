@@ -0,0 +1,134 @@
+package httperror
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var muxKey interface{} = contextKey("mux")
+
+type muxError struct {
+	err error
+}
+
+// Handle registers an [httperror.Handler] with a standard [http.ServeMux]
+// for the given pattern, e.g. "GET /orders/{id}". When the mux is served by
+// [httperror.MuxHandler], errors returned by the handler are returned from
+// MuxHandler. Otherwise they are handled by [DefaultErrorHandler].
+func Handle(mux *http.ServeMux, pattern string, h Handler) {
+	mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := h.Serve(w, r)
+		if err == nil {
+			return
+		}
+
+		if me, ok := r.Context().Value(muxKey).(*muxError); ok {
+			me.err = err
+			return
+		}
+
+		ReportError(r.Context(), r, err)
+		DefaultErrorHandler(w, err)
+	}))
+}
+
+// HandleFunc registers an error-returning handler function with a standard
+// [http.ServeMux] for the given pattern. See [httperror.Handle].
+func HandleFunc(mux *http.ServeMux, pattern string, h func(w http.ResponseWriter, r *http.Request) error) {
+	Handle(mux, pattern, HandlerFunc(h))
+}
+
+// MuxHandler returns an [httperror.HandlerFunc] that dispatches requests to
+// mux, returning the errors returned by handlers registered with
+// [httperror.Handle] or [httperror.HandleFunc]. Requests that match no
+// pattern return NotFound, and requests that match a pattern only for other
+// methods return MethodNotAllowed (with the Allow header set), instead of
+// the plain-text responses written by the mux. So all errors can be handled
+// by a single error handler:
+//
+//	http.ListenAndServe(":8080", httperror.WrapHandlerFunc(httperror.MuxHandler(mux), errorHandler))
+func MuxHandler(mux *http.ServeMux) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if h, pattern := mux.Handler(r); pattern == "" {
+			// The mux has no matching pattern, and h writes a 404 or 405
+			// response. Run it against a recorder to find out which, and to
+			// capture the Allow header.
+			rec := &headerRecorder{header: make(http.Header)}
+			h.ServeHTTP(rec, r)
+
+			if rec.status == http.StatusMethodNotAllowed {
+				w.Header()["Allow"] = rec.header["Allow"]
+				return MethodNotAllowed
+			}
+			return NotFound
+		}
+
+		me := &muxError{}
+		mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), muxKey, me)))
+
+		return me.err
+	}
+}
+
+// headerRecorder is an http.ResponseWriter that records the header and
+// status code, and discards the body.
+type headerRecorder struct {
+	header http.Header
+	status int
+}
+
+func (rec *headerRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *headerRecorder) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (rec *headerRecorder) WriteHeader(s int) {
+	rec.status = s
+}
+
+// Methods returns a [httperror.Handler] that dispatches requests by method
+// to the handlers in the map, keyed by method name (e.g. "GET"). Requests
+// with other methods return MethodNotAllowed, with an Allow header listing
+// the supported methods attached (see [httperror.WithHeader]). HEAD
+// requests are served by the GET handler unless there is a HEAD handler,
+// and OPTIONS requests are answered with 204 No Content and the Allow
+// header unless there is an OPTIONS handler.
+func Methods(handlers map[string]Handler) Handler {
+	allowed := make([]string, 0, len(handlers)+2)
+	for method := range handlers {
+		allowed = append(allowed, method)
+	}
+	if _, ok := handlers[http.MethodGet]; ok {
+		if _, ok := handlers[http.MethodHead]; !ok {
+			allowed = append(allowed, http.MethodHead)
+		}
+	}
+	if _, ok := handlers[http.MethodOptions]; !ok {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	sort.Strings(allowed)
+	allow := strings.Join(allowed, ", ")
+
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		h, ok := handlers[r.Method]
+		if !ok && r.Method == http.MethodHead {
+			h, ok = handlers[http.MethodGet]
+		}
+		if ok {
+			return h.Serve(w, r)
+		}
+
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+
+		return WithHeader(MethodNotAllowed, "Allow", allow)
+	})
+}
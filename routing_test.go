@@ -0,0 +1,368 @@
+package httperror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/johnwarden/httperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMuxHandler(t *testing.T) {
+	mux := http.NewServeMux()
+
+	httperror.HandleFunc(mux, "GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain")
+		if r.PathValue("id") != "42" {
+			return httperror.NotFound
+		}
+		fmt.Fprintf(w, "Order %s\n", r.PathValue("id"))
+		return nil
+	})
+
+	var e error
+	errorHandler := func(w http.ResponseWriter, err error) {
+		e = err
+		httperror.DefaultErrorHandler(w, err)
+	}
+
+	h := httperror.WrapHandlerFunc(httperror.MuxHandler(mux), errorHandler)
+
+	{
+		s, m := testRequest(h, "/orders/42")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "Order 42\n", m)
+	}
+
+	{
+		e = nil
+		s, m := testRequest(h, "/orders/43")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, "404 Not Found\n", m)
+		assert.Equal(t, httperror.NotFound, e)
+	}
+
+	{
+		e = nil
+		s, _ := testRequest(h, "/customers/42")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, httperror.NotFound, e)
+	}
+
+	{
+		e = nil
+		r := httptest.NewRequest("DELETE", "/orders/42", nil)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		assert.Equal(t, 405, rr.Code)
+		assert.Equal(t, "GET, HEAD", rr.Header().Get("Allow"))
+		assert.Equal(t, httperror.MethodNotAllowed, e)
+	}
+
+	{
+		// Handlers registered with HandleFunc still work when the mux is used directly.
+		s, m := testRequest(mux, "/orders/43")
+		assert.Equal(t, 404, s)
+		assert.Equal(t, "404 Not Found\n", m)
+	}
+}
+
+func TestMethods(t *testing.T) {
+	h := httperror.Methods(map[string]httperror.Handler{
+		"GET":  okHandler,
+		"POST": notFoundHandler,
+	})
+
+	serve := func(method string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(method, "/", nil))
+		return rr
+	}
+
+	assert.Equal(t, 200, serve("GET").Code)
+	assert.Equal(t, 200, serve("HEAD").Code, "HEAD is served by GET")
+	assert.Equal(t, 404, serve("POST").Code)
+
+	{
+		rr := serve("DELETE")
+		assert.Equal(t, 405, rr.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS, POST", rr.Header().Get("Allow"))
+	}
+
+	{
+		rr := serve("OPTIONS")
+		assert.Equal(t, 204, rr.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS, POST", rr.Header().Get("Allow"))
+	}
+}
+
+func TestFileServer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"hello.txt":        {Data: []byte("hello\n")},
+		"docs/index.html":  {Data: []byte("<h1>docs</h1>\n")},
+		"empty/readme.txt": {Data: []byte("readme\n")},
+	}
+
+	var e error
+	h := httperror.WrapHandlerFunc(httperror.FileServer(fsys).Serve, func(w http.ResponseWriter, err error) {
+		e = err
+		httperror.DefaultErrorHandler(w, err)
+	})
+
+	{
+		s, m := testRequest(h, "/hello.txt")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "hello\n", m)
+	}
+
+	{
+		s, m := testRequest(h, "/docs/")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "<h1>docs</h1>\n", m)
+	}
+
+	{
+		s, _ := testRequest(h, "/docs")
+		assert.Equal(t, 301, s, "directory without trailing slash is redirected")
+	}
+
+	{
+		s, _ := testRequest(h, "/missing.txt")
+		assert.Equal(t, 404, s)
+		assert.True(t, errors.Is(e, fs.ErrNotExist), "underlying error is preserved")
+	}
+
+	{
+		s, _ := testRequest(h, "/empty/")
+		assert.Equal(t, 404, s, "no directory listings")
+	}
+
+	{
+		rr := httptest.NewRecorder()
+		err := httperror.ServeFile(rr, httptest.NewRequest("GET", "/", nil), fsys, "hello.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "hello\n", rr.Body.String())
+	}
+
+	conditional := func(header, value string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/hello.txt", nil)
+		r.Header.Set(header, value)
+		h.ServeHTTP(rr, r)
+		return rr
+	}
+
+	{
+		rr := conditional("If-Match", `"v1"`)
+		assert.Equal(t, 412, rr.Code)
+		assert.Equal(t, httperror.PreconditionFailed, e)
+		assert.Contains(t, rr.Body.String(), "<title>Error 412</title>", "rendered by the error handler")
+	}
+
+	{
+		rr := conditional("Range", "bytes=100-")
+		assert.Equal(t, 416, rr.Code)
+		assert.Equal(t, "bytes */6", rr.Header().Get("Content-Range"))
+		assert.Contains(t, rr.Body.String(), "<title>Error 416</title>", "rendered by the error handler")
+	}
+
+	{
+		rr := conditional("Range", "bytes=1-2")
+		assert.Equal(t, 206, rr.Code)
+		assert.Equal(t, "el", rr.Body.String())
+	}
+}
+
+func TestSPAHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html": {Data: []byte("<div id=app></div>\n")},
+		"app.js":     {Data: []byte("run()\n")},
+	}
+
+	h := httperror.SPAHandler(fsys, "index.html")
+
+	{
+		s, m := testRequest(h, "/app.js")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "run()\n", m)
+	}
+
+	{
+		s, m := testRequest(h, "/orders/42")
+		assert.Equal(t, 200, s, "client-side route falls back to the index")
+		assert.Equal(t, "<div id=app></div>\n", m)
+	}
+
+	{
+		s, _ := testRequest(h, "/missing.js")
+		assert.Equal(t, 404, s, "missing asset is a real 404")
+	}
+
+	{
+		err := h.Serve(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders/42", nil))
+		assert.True(t, errors.Is(err, httperror.NotFound), "other methods don't fall back to the index")
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	var logged []httperror.LogEntry
+	logger := httperror.LoggerFunc(func(e httperror.LogEntry) {
+		logged = append(logged, e)
+	})
+
+	{
+		h := httperror.LoggingMiddleware(logger)(httperror.RedirectHandler("/new", http.StatusMovedPermanently))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", "/old", nil))
+		assert.Equal(t, 301, rr.Code)
+		assert.Equal(t, "/new", rr.Header().Get("Location"))
+		assert.Len(t, logged, 1, "logging middleware sees the redirect")
+		assert.Equal(t, httperror.SeverityInfo, logged[0].Severity)
+	}
+
+	{
+		h := httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return httperror.SeeOther(w, r, "42")
+		})
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", "/orders/", nil))
+		assert.Equal(t, 303, rr.Code)
+		assert.Equal(t, "/orders/42", rr.Header().Get("Location"), "relative URLs are resolved")
+	}
+
+	{
+		h := httperror.WrapHandler(httperror.RedirectHandler("/new", http.StatusFound), httperror.ProblemErrorHandler)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", "/old", nil))
+		assert.Equal(t, 302, rr.Code)
+		assert.Equal(t, "/new", rr.Header().Get("Location"))
+		assert.NotContains(t, rr.Header().Get("Content-Type"), "problem", "error handler doesn't render the redirect")
+		assert.True(t, httperror.ResponseWritten(httperror.Redirect(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), "/new", http.StatusFound)))
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	errDatabaseDown := errors.New("database down")
+
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errDatabaseDown }
+
+	{
+		s, m := testRequest(httperror.HealthHandler(
+			httperror.HealthCheck{Name: "database", Check: ok},
+			httperror.HealthCheck{Name: "cache", Check: ok},
+		), "/healthz")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "OK\ndatabase: pass\ncache: pass\n", m)
+	}
+
+	{
+		var e error
+		h := httperror.WrapHandlerFunc(httperror.HealthHandler(
+			httperror.HealthCheck{Name: "database", Check: down},
+			httperror.HealthCheck{Name: "cache", Check: ok},
+			httperror.HealthCheck{Name: "queue", Check: func(ctx context.Context) error {
+				return httperror.NewPublic(http.StatusServiceUnavailable, "queue is full")
+			}},
+		), func(w http.ResponseWriter, err error) {
+			e = err
+			httperror.DefaultErrorHandler(w, err)
+		})
+
+		s, m := testRequest(h, "/healthz")
+		assert.Equal(t, 503, s)
+		assert.Equal(t, "503 Service Unavailable: 2 of 3 health checks failed\ndatabase: fail\ncache: pass\nqueue: fail: queue is full\n", m)
+		assert.Nil(t, e, "the response is written by the health handler")
+
+		var err error
+		hh := httperror.HealthHandler(httperror.HealthCheck{Name: "database", Check: down}, httperror.HealthCheck{Name: "cache", Check: ok})
+		rr := httptest.NewRecorder()
+		err = hh(rr, httptest.NewRequest("GET", "/healthz", nil))
+		assert.True(t, errors.Is(err, errDatabaseDown))
+		assert.True(t, errors.Is(err, httperror.ServiceUnavailable))
+		assert.True(t, httperror.ResponseWritten(err))
+		assert.Equal(t, "503 Service Unavailable: 1 of 2 health checks failed: database down", err.Error())
+
+		r := httptest.NewRequest("GET", "/healthz", nil)
+		r.Header.Set("Accept", "application/json")
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		assert.Equal(t, 503, rr.Code)
+		assert.Equal(t, `{"status":"error","message":"Service Unavailable: 2 of 3 health checks failed","code":503,"data":{"checks":[{"name":"database","status":"fail"},{"name":"cache","status":"pass"},{"name":"queue","status":"fail","message":"queue is full"}]}}`+"\n", rr.Body.String())
+	}
+}
+
+type orderID string
+
+func (id *orderID) UnmarshalText(b []byte) error {
+	if !strings.HasPrefix(string(b), "ord_") {
+		return errors.New("invalid order ID")
+	}
+	*id = orderID(b)
+	return nil
+}
+
+func TestPathValue(t *testing.T) {
+	mux := http.NewServeMux()
+	httperror.HandleFunc(mux, "GET /items/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		id, err := httperror.PathValue[int](r, "id")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "item %d", id)
+		return nil
+	})
+	httperror.HandleFunc(mux, "GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		id, err := httperror.PathValue[orderID](r, "id")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "order %s", id)
+		return nil
+	})
+	httperror.HandleFunc(mux, "GET /files/{path...}", func(w http.ResponseWriter, r *http.Request) error {
+		_, err := httperror.PathValue[string](r, "path")
+		return err
+	})
+	h := httperror.MuxHandler(mux)
+
+	{
+		s, m := testRequest(h, "/items/42")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "item 42", m)
+	}
+
+	{
+		s, m := testRequest(h, "/items/abc")
+		assert.Equal(t, 400, s)
+		assert.Contains(t, m, `invalid value for path parameter "id": expected integer`)
+	}
+
+	{
+		s, m := testRequest(h, "/orders/ord_1")
+		assert.Equal(t, 200, s)
+		assert.Equal(t, "order ord_1", m)
+	}
+
+	{
+		s, m := testRequest(h, "/orders/1")
+		assert.Equal(t, 400, s)
+		assert.Contains(t, m, "expected orderID")
+	}
+
+	{
+		s, _ := testRequest(h, "/files/")
+		assert.Equal(t, 404, s)
+	}
+}
@@ -0,0 +1,82 @@
+package httperror
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+const contentTypeXML = "application/xml"
+
+// S3ErrorCodes maps HTTP status codes to the error codes used in S3 error
+// responses by [httperror.S3ErrorHandler]. Errors with a code embedded
+// using [httperror.WithCode] (e.g. "NoSuchBucket") use that code instead.
+// Applications may modify this table during initialization.
+var S3ErrorCodes = map[int]string{
+	http.StatusBadRequest:                   "InvalidRequest",
+	http.StatusUnauthorized:                 "AccessDenied",
+	http.StatusForbidden:                    "AccessDenied",
+	http.StatusNotFound:                     "NoSuchKey",
+	http.StatusMethodNotAllowed:             "MethodNotAllowed",
+	http.StatusConflict:                     "OperationAborted",
+	http.StatusLengthRequired:               "MissingContentLength",
+	http.StatusPreconditionFailed:           "PreconditionFailed",
+	http.StatusRequestEntityTooLarge:        "EntityTooLarge",
+	http.StatusRequestedRangeNotSatisfiable: "InvalidRange",
+	http.StatusTooManyRequests:              "SlowDown",
+	http.StatusInternalServerError:          "InternalError",
+	http.StatusNotImplemented:               "NotImplemented",
+	http.StatusServiceUnavailable:           "ServiceUnavailable",
+}
+
+// S3ErrorHandler is an [httperror.ErrorHandler] that writes an XML error
+// response in the shape used by Amazon S3 and compatible object stores:
+//
+//	<Error><Code>NoSuchKey</Code><Message>...</Message><RequestId>...</RequestId></Error>
+//
+// The code is extracted using [httperror.ErrorCode], or looked up in
+// [httperror.S3ErrorCodes] if the error has no code. The message is the
+// public message (see [httperror.PublicMessage]) or the status text if there
+// is none. The request ID is taken from the x-amz-request-id response
+// header, if it has been set.
+func S3ErrorHandler(w http.ResponseWriter, e error) {
+	s := StatusCode(e)
+
+	body := s3Error{
+		Code:      ErrorCode(e),
+		Message:   PublicMessage(e),
+		RequestID: w.Header().Get("x-amz-request-id"),
+	}
+	if body.Code == "" {
+		body.Code = s3ErrorCode(s)
+	}
+	if body.Message == "" {
+		body.Message = statusText(s)
+	}
+
+	w.Header().Set("Content-Type", contentTypeXML)
+	setErrorHeaders(w, e)
+	w.WriteHeader(s)
+
+	x, _ := xml.Marshal(body) // No error handling for error handling
+
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(x)
+	_, _ = w.Write([]byte("\n"))
+}
+
+type s3Error struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	RequestID string   `xml:"RequestId,omitempty"`
+}
+
+func s3ErrorCode(s int) string {
+	if c, ok := S3ErrorCodes[s]; ok {
+		return c
+	}
+	if s >= 500 {
+		return "InternalError"
+	}
+	return "InvalidRequest"
+}
@@ -0,0 +1,109 @@
+/*
+Package sentryreport reports server errors and panics returned by handlers
+to Sentry (github.com/getsentry/sentry-go). See the documentation of the
+parent package at https://github.com/johnwarden/httperror
+*/
+package sentryreport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/johnwarden/httperror"
+)
+
+// Reporter sends errors to Sentry. The zero value reports server errors
+// (5xx) and panics using the hub of the request context, or the current
+// hub. Reporter is an [httperror.ErrorReporter], so it can also be
+// registered for all handlers:
+//
+//	httperror.RegisterErrorReporter(&sentryreport.Reporter{}, sentryreport.ShouldReport)
+type Reporter struct {
+	// Hub is the hub used to send events. If nil, the hub from the request
+	// context (see sentry.GetHubFromContext) is used, or else
+	// sentry.CurrentHub.
+	Hub *sentry.Hub
+
+	// ShouldReport decides which errors are sent. If nil, server errors
+	// (5xx) and panics are sent.
+	ShouldReport func(err error) bool
+}
+
+var _ httperror.ErrorReporter = (*Reporter)(nil)
+
+// ShouldReport reports whether err is a server error (5xx) or a panic.
+func ShouldReport(err error) bool {
+	return err != nil && (httperror.StatusCode(err) >= 500 || errors.Is(err, httperror.Panic))
+}
+
+// Report sends err to Sentry, if it qualifies (see Reporter.ShouldReport),
+// with the request, the status code and error code (see
+// [httperror.StatusCode] and [httperror.ErrorCode]) as tags, and a
+// fingerprint that groups events by status code and error code as well as
+// by stack trace. The stack trace is that of the caller, unless the error
+// carries one that Sentry can extract. Panics are reported with level
+// fatal, and other errors with level error.
+func (rep *Reporter) Report(ctx context.Context, r *http.Request, err error) {
+	shouldReport := rep.ShouldReport
+	if shouldReport == nil {
+		shouldReport = ShouldReport
+	}
+	if err == nil || !shouldReport(err) {
+		return
+	}
+
+	hub := rep.Hub
+	if hub == nil {
+		hub = sentry.GetHubFromContext(ctx)
+	}
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	s := strconv.Itoa(httperror.StatusCode(err))
+	code := httperror.ErrorCode(err)
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		if r != nil {
+			scope.SetRequest(r)
+		}
+		scope.SetTag("http.status_code", s)
+
+		fingerprint := []string{"{{ default }}", s}
+		if code != "" {
+			scope.SetTag("error.code", code)
+			fingerprint = append(fingerprint, code)
+		}
+		scope.SetFingerprint(fingerprint)
+
+		if errors.Is(err, httperror.Panic) {
+			scope.SetLevel(sentry.LevelFatal)
+		} else {
+			scope.SetLevel(sentry.LevelError)
+		}
+
+		hub.CaptureException(err)
+	})
+}
+
+// ErrorHandler returns an [httperror.RequestErrorHandler] that reports
+// errors to Sentry, then handles them with eh, so the reporter can be wired
+// in with the handler options:
+//
+//	rep := &sentryreport.Reporter{}
+//	h := httperror.NewHandler(f, httperror.WithRequestErrorHandler(rep.ErrorHandler(nil)))
+//
+// If eh is nil, [httperror.DefaultErrorHandler] is used.
+func (rep *Reporter) ErrorHandler(eh httperror.ErrorHandler) httperror.RequestErrorHandler {
+	if eh == nil {
+		eh = httperror.DefaultErrorHandler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		rep.Report(r.Context(), r, err)
+		eh(w, err)
+	}
+}
@@ -0,0 +1,95 @@
+package sentryreport_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/sentryreport"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type transport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *transport) Flush(time.Duration) bool       { return true }
+func (t *transport) Configure(sentry.ClientOptions) {}
+func (t *transport) SendEvent(e *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, e)
+}
+
+func newHub(t *testing.T) (*sentry.Hub, *transport) {
+	tr := &transport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: tr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentry.NewHub(client, sentry.NewScope()), tr
+}
+
+func TestReporter(t *testing.T) {
+	hub, tr := newHub(t)
+	rep := &sentryreport.Reporter{Hub: hub}
+
+	h := httperror.NewHandler(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/missing":
+			return httperror.NotFound
+		case "/panic":
+			panic("oops")
+		}
+		return httperror.WithCode(httperror.Wrap(errors.New("database down"), 503), "db_unavailable")
+	}, httperror.WithRequestErrorHandler(rep.ErrorHandler(nil)), httperror.WithPanicRecovery(true))
+
+	serve := func(path string) int {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr.Code
+	}
+
+	assert.Equal(t, 404, serve("/missing"))
+	assert.Empty(t, tr.events, "client errors are not reported")
+
+	assert.Equal(t, 503, serve("/orders"))
+	assert.Len(t, tr.events, 1)
+	e := tr.events[0]
+	assert.Equal(t, sentry.LevelError, e.Level)
+	assert.Equal(t, "503", e.Tags["http.status_code"])
+	assert.Equal(t, "db_unavailable", e.Tags["error.code"])
+	assert.Equal(t, []string{"{{ default }}", "503", "db_unavailable"}, e.Fingerprint)
+	assert.Equal(t, "http://example.com/orders", e.Request.URL)
+	assert.NotEmpty(t, e.Exception)
+	assert.NotNil(t, e.Exception[len(e.Exception)-1].Stacktrace, "stack trace is attached")
+
+	assert.Equal(t, 500, serve("/panic"))
+	assert.Len(t, tr.events, 2)
+	assert.Equal(t, sentry.LevelFatal, tr.events[1].Level, "panics are fatal")
+}
+
+func TestShouldReport(t *testing.T) {
+	hub, tr := newHub(t)
+	rep := &sentryreport.Reporter{Hub: hub, ShouldReport: func(err error) bool {
+		return errors.Is(err, httperror.Conflict)
+	}}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	rep.Report(r.Context(), r, httperror.InternalServerError)
+	rep.Report(r.Context(), r, httperror.Conflict)
+	rep.Report(r.Context(), r, nil)
+	assert.Len(t, tr.events, 1)
+
+	assert.False(t, sentryreport.ShouldReport(nil))
+	assert.False(t, sentryreport.ShouldReport(httperror.NotFound))
+	assert.True(t, sentryreport.ShouldReport(errors.New("unknown")))
+	assert.True(t, sentryreport.ShouldReport(httperror.PanicError("oops")))
+}
@@ -0,0 +1,104 @@
+package httperror
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LogValue returns a [slog.LogValuer] that expands err into a group with its
+// message, status code, error code (see [httperror.ErrorCode]), and public
+// message (see [httperror.PublicMessage]):
+//
+//	logger.Error("request failed", "error", httperror.LogValue(err))
+func LogValue(err error) slog.LogValuer {
+	return errorLogValuer{err}
+}
+
+type errorLogValuer struct {
+	err error
+}
+
+func (v errorLogValuer) LogValue() slog.Value {
+	if v.err == nil {
+		return slog.Value{}
+	}
+
+	attrs := []slog.Attr{
+		slog.String("msg", v.err.Error()),
+		slog.Int("status", StatusCode(v.err)),
+	}
+	if c := ErrorCode(v.err); c != "" {
+		attrs = append(attrs, slog.String("code", c))
+	}
+	if m := PublicMessage(v.err); m != "" {
+		attrs = append(attrs, slog.String("public", m))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// SlogMiddleware returns an [httperror.Middleware] that emits one log record
+// per request to logger, with the request method, path, status code (see
+// [httperror.StatusCode], or the status written by the handler if it
+// returned no error), duration, request ID (from the X-Request-Id
+// request header), trace ID (from the W3C traceparent request header), and
+// any returned error expanded using [httperror.LogValue]. The level is
+// derived from the severity of the error (see [httperror.ErrorSeverity]).
+// The error is then returned unchanged.
+func SlogMiddleware(logger *slog.Logger) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+
+			rw := NewResponseWriter(w)
+			err := h.Serve(rw, r)
+
+			status := StatusCode(err)
+			if err == nil && rw.Written() {
+				status = rw.Status()
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if id := r.Header.Get(requestIDHeader); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			if id := traceID(r); id != "" {
+				attrs = append(attrs, slog.String("trace_id", id))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", LogValue(err)))
+			}
+
+			logger.LogAttrs(r.Context(), slogLevel(ErrorSeverity(err)), "http request", attrs...)
+
+			return err
+		})
+	}
+}
+
+func slogLevel(s Severity) slog.Level {
+	switch s {
+	case SeverityError:
+		return slog.LevelError
+	case SeverityWarning:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// traceID extracts the trace ID from a W3C traceparent request header
+// (version-traceid-parentid-flags). Returns the empty string if there is no
+// valid header.
+func traceID(r *http.Request) string {
+	parts := strings.Split(r.Header.Get("traceparent"), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
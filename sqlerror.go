@@ -0,0 +1,94 @@
+package httperror
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SQLErrorClassifier returns the status code for a database error, or 0 if
+// it doesn't recognize the error.
+type SQLErrorClassifier = func(err error) int
+
+var (
+	sqlClassifiersMu sync.Mutex
+	sqlClassifiers   atomic.Pointer[[]SQLErrorClassifier]
+)
+
+// RegisterSQLErrorClassifier registers a classifier used by
+// [httperror.FromSQLError], for driver-specific errors. Classifiers are
+// tried in the order they were registered, before the built-in
+// classification.
+func RegisterSQLErrorClassifier(c SQLErrorClassifier) {
+	sqlClassifiersMu.Lock()
+	defer sqlClassifiersMu.Unlock()
+
+	var cs []SQLErrorClassifier
+	if p := sqlClassifiers.Load(); p != nil {
+		cs = append(cs, *p...)
+	}
+	cs = append(cs, c)
+	sqlClassifiers.Store(&cs)
+}
+
+// FromSQLError converts an error returned by database/sql into an error
+// with a status code, so that repositories can return database errors
+// directly:
+//
+//   - sql.ErrNoRows: NotFound
+//   - integrity constraint violations (SQLSTATE class 23): Conflict
+//   - serialization failures and deadlocks (SQLSTATE 40001 and 40P01):
+//     ServiceUnavailable, since the transaction can be retried
+//   - connection errors (sql.ErrConnDone, driver.ErrBadConn, and SQLSTATE
+//     class 08): ServiceUnavailable
+//
+// SQLSTATE codes are read from errors in the chain with a
+// `SQLState() string` method, which is implemented by the pgx and lib/pq
+// drivers. Classifiers registered with
+// [httperror.RegisterSQLErrorClassifier] are tried first. The original
+// error remains in the chain. Other errors, and errors that already have a
+// status code, are returned unchanged.
+func FromSQLError(err error) error {
+	if err == nil || HasStatusCode(err) {
+		return err
+	}
+
+	if p := sqlClassifiers.Load(); p != nil {
+		for _, c := range *p {
+			if s := c(err); s != 0 {
+				return Wrap(err, s)
+			}
+		}
+	}
+
+	if s := sqlErrorStatus(err); s != 0 {
+		return Wrap(err, s)
+	}
+	return err
+}
+
+func sqlErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		return http.StatusServiceUnavailable
+	}
+
+	var e interface{ SQLState() string }
+	if !errors.As(err, &e) {
+		return 0
+	}
+
+	switch state := e.SQLState(); {
+	case strings.HasPrefix(state, "23"):
+		return http.StatusConflict
+	case state == "40001", state == "40P01", strings.HasPrefix(state, "08"):
+		return http.StatusServiceUnavailable
+	}
+	return 0
+}
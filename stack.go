@@ -0,0 +1,54 @@
+package httperror
+
+import (
+	"net/http"
+)
+
+// Stack is a list of middleware, mixing error-aware [httperror.Middleware]
+// and [httperror.StandardMiddleware], applied in the order they were added:
+// the first middleware added is the outermost. The zero value is an empty
+// stack that handles errors with [DefaultErrorHandler].
+//
+//	var s httperror.Stack
+//	s.Use(httperror.LoggingMiddleware(logger)).
+//		UseStandard(cors.Default().Handler).
+//		Use(httperror.PanicMiddleware)
+//	http.Handle("/", s.Then(h))
+type Stack struct {
+	// ErrorHandler handles errors returned by the handler returned by
+	// Then. If nil, [DefaultErrorHandler] is used.
+	ErrorHandler ErrorHandler
+
+	middleware []Middleware
+}
+
+// Use adds error-aware middleware to the stack, and returns the stack.
+func (s *Stack) Use(ms ...Middleware) *Stack {
+	s.middleware = append(s.middleware, ms...)
+	return s
+}
+
+// UseStandard adds standard middleware to the stack (see
+// [httperror.ApplyStandardMiddleware]), and returns the stack.
+func (s *Stack) UseStandard(ms ...StandardMiddleware) *Stack {
+	for _, m := range ms {
+		m := m
+		s.middleware = append(s.middleware, func(h Handler) Handler {
+			return ApplyStandardMiddleware(h, m)
+		})
+	}
+	return s
+}
+
+// Handler applies the middleware in the stack to h, returning an
+// [httperror.Handler].
+func (s *Stack) Handler(h Handler) Handler {
+	return Chain(s.middleware...)(h)
+}
+
+// Then applies the middleware in the stack to h, and returns a standard
+// [http.Handler] that handles errors with the stack's ErrorHandler.
+// Middleware added to the stack later doesn't affect the returned handler.
+func (s *Stack) Then(h Handler) http.Handler {
+	return WrapHandler(s.Handler(h), s.ErrorHandler)
+}
@@ -3,18 +3,86 @@ package httperror
 import (
 	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 )
 
 type contextKey string
 
-var key = contextKey("key")
+// key is stored as an interface value, so that it is not converted (and
+// allocated) on every call to context.WithValue and Context.Value.
+var key interface{} = contextKey("key")
 
 // StandardMiddleware is a standard http.Handler wrapper.
 type StandardMiddleware = func(http.Handler) http.Handler
 
+// standardMiddleware carries the parameters and the error through the
+// standard middleware. Carriers are pooled, and a carrier is only returned
+// to the pool once the inner handler has completed, so that middleware that
+// abandons the inner handler (for example [http.TimeoutHandler]) can't
+// write into a carrier that is in use by another request. The
+// ResponseWriter is passed to the handlers, which may keep it after they
+// return, so it is allocated for each request rather than pooled.
 type standardMiddleware[P any] struct {
 	params P
 	err    error
+	called atomic.Bool
+	done   atomic.Bool
+	writer *ResponseWriter
+
+	// innerWrote is set if the response status was written while the inner
+	// handler was running, rather than by the middleware.
+	innerWrote atomic.Bool
+}
+
+func getCarrier[P any](pool *sync.Pool, p P) *standardMiddleware[P] {
+	sm := pool.Get().(*standardMiddleware[P])
+	sm.params = p
+	return sm
+}
+
+func putCarrier[P any](pool *sync.Pool, sm *standardMiddleware[P]) {
+	if !sm.done.Load() {
+		return
+	}
+
+	var zeroValue P
+	sm.params, sm.err = zeroValue, nil
+	sm.called.Store(false)
+	sm.done.Store(false)
+	sm.innerWrote.Store(false)
+	sm.writer = nil
+	pool.Put(sm)
+}
+
+// serveInner calls serve as the inner handler of the standard middleware,
+// recording whether it wrote the response status.
+func (sm *standardMiddleware[P]) serveInner(serve func() error) {
+	sm.called.Store(true)
+	written := sm.writer.Written()
+
+	sm.err = serve()
+	sm.innerWrote.Store(!written && sm.writer.Written())
+	sm.done.Store(true)
+}
+
+// middlewareError returns an error for an error status written by the
+// standard middleware itself: either without calling the inner handler (for
+// example an auth middleware denying the request, or a CORS middleware
+// rejecting an origin), or around an inner handler that returned nil (for
+// example [http.TimeoutHandler] giving up on the inner handler). It returns
+// nil if the middleware didn't write an error status, or if the inner
+// handler returned an error, which takes precedence. Since the response has
+// already been written, the error is marked as such (see
+// [httperror.ResponseWritten]).
+func (sm *standardMiddleware[P]) middlewareError() error {
+	if sm.writer.Status() < 400 {
+		return nil
+	}
+	if sm.done.Load() && (sm.err != nil || sm.innerWrote.Load()) {
+		return nil
+	}
+	return writtenError{httpError{sm.writer.Status()}}
 }
 
 // XApplyStandardMiddleware applies middleware written for a standard
@@ -26,12 +94,19 @@ type standardMiddleware[P any] struct {
 // [httperror.XHandler], and so parameters could not passed to it and it
 // could not return an error. This function solves that problem by passing
 // errors and parameters through the context.
+//
+// If a middleware writes an error status itself (for example to deny a
+// request), an error with that status is returned, so that logging and
+// other error middleware see the real outcome. The
+// error is marked as already written (see [httperror.ResponseWritten]), so
+// the error handlers in this package don't write a second response.
 func XApplyStandardMiddleware[P any](h XHandler[P], ms ...StandardMiddleware) XHandlerFunc[P] {
+	pool := &sync.Pool{New: func() interface{} { return new(standardMiddleware[P]) }}
+
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		sm := ctx.Value(key).(*standardMiddleware[P])
-
-		sm.err = h.Serve(w, r, sm.params)
+		sm.serveInner(func() error { return h.Serve(w, r, sm.params) })
 	})
 
 	for _, m := range ms {
@@ -39,12 +114,18 @@ func XApplyStandardMiddleware[P any](h XHandler[P], ms ...StandardMiddleware) XH
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, p P) error {
-		sm := &standardMiddleware[P]{p, nil}
+		sm := getCarrier(pool, p)
+		defer putCarrier(pool, sm)
+
 		c := r.Context()
 		c = context.WithValue(c, key, sm)
 
-		handler.ServeHTTP(w, r.WithContext(c))
+		sm.writer = &ResponseWriter{ResponseWriter: w}
+		handler.ServeHTTP(sm.writer, r.WithContext(c))
 
+		if err := sm.middlewareError(); err != nil {
+			return err
+		}
 		return sm.err
 	}
 }
@@ -58,12 +139,17 @@ func XApplyStandardMiddleware[P any](h XHandler[P], ms ...StandardMiddleware) XH
 // [httperror.Handler], and so parameters could not passed to it and it
 // could not return an error. This function solves that problem by passing
 // errors and parameters through the context.
+//
+// If a middleware writes an error status itself, an error with that status
+// is returned (see
+// [httperror.XApplyStandardMiddleware]).
 func ApplyStandardMiddleware(h Handler, ms ...StandardMiddleware) HandlerFunc {
+	pool := &sync.Pool{New: func() interface{} { return new(standardMiddleware[any]) }}
+
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		sm := ctx.Value(key).(*standardMiddleware[any])
-
-		sm.err = h.Serve(w, r)
+		sm.serveInner(func() error { return h.Serve(w, r) })
 	})
 
 	for _, m := range ms {
@@ -71,12 +157,18 @@ func ApplyStandardMiddleware(h Handler, ms ...StandardMiddleware) HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) error {
-		sm := &standardMiddleware[any]{}
+		sm := getCarrier[any](pool, nil)
+		defer putCarrier(pool, sm)
+
 		c := r.Context()
 		c = context.WithValue(c, key, sm)
 
-		handler.ServeHTTP(w, r.WithContext(c))
+		sm.writer = &ResponseWriter{ResponseWriter: w}
+		handler.ServeHTTP(sm.writer, r.WithContext(c))
 
+		if err := sm.middlewareError(); err != nil {
+			return err
+		}
 		return sm.err
 	}
 }
@@ -0,0 +1,17 @@
+module github.com/johnwarden/httperror/statsdreport
+
+go 1.22
+
+require (
+	github.com/DataDog/datadog-go/v5 v5.5.0
+	github.com/johnwarden/httperror v0.0.0-20261016035624-08a2347946c7
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/Microsoft/go-winio v0.5.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20210510120138-977fb7262007 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,105 @@
+/*
+Package statsdreport counts the errors handled by httperror handlers as
+StatsD/DogStatsD counters, using a client such as the Datadog client
+(github.com/DataDog/datadog-go/v5/statsd). See the documentation of the
+parent package at https://github.com/johnwarden/httperror
+*/
+package statsdreport
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/johnwarden/httperror"
+)
+
+// Client is the part of a StatsD client used by [Emitter]. It is
+// implemented by *statsd.Client from github.com/DataDog/datadog-go/v5.
+type Client interface {
+	Incr(name string, tags []string, rate float64) error
+}
+
+// Emitter increments a counter for each handled error, tagged with
+// status (the status code, see [httperror.StatusCode]), code (the error
+// code, see [httperror.ErrorCode], if any) and route. Tags are a DogStatsD
+// extension, which most StatsD servers ignore.
+//
+// Emitter is an [httperror.ErrorReporter], so it can be registered for all
+// handlers:
+//
+//	client, err := statsd.New("127.0.0.1:8125")
+//	...
+//	httperror.RegisterErrorReporter(&statsdreport.Emitter{Client: client}, func(error) bool { return true })
+//
+// Alternatively, the middleware returned by the Middleware method counts
+// the errors returned by individual handlers.
+type Emitter struct {
+	// Client sends the counters.
+	Client Client
+
+	// Metric is the name of the counter. The default is
+	// "httperror.errors".
+	Metric string
+
+	// Route derives the route tag from the request for errors counted by
+	// the Report method. If nil, errors are counted without a route tag.
+	Route func(*http.Request) string
+
+	// Rate is the sample rate. The default is 1.
+	Rate float64
+}
+
+var _ httperror.ErrorReporter = (*Emitter)(nil)
+
+// Emit increments the counter for err, tagged with route unless route is
+// empty. It does nothing if err is nil or has a status code below 400, such
+// as a redirect (see [httperror.Redirect]). Errors sending the counter are
+// ignored, since StatsD is fire-and-forget.
+func (e *Emitter) Emit(err error, route string) {
+	if err == nil || httperror.StatusCode(err) < 400 {
+		return
+	}
+
+	metric := e.Metric
+	if metric == "" {
+		metric = "httperror.errors"
+	}
+	rate := e.Rate
+	if rate == 0 {
+		rate = 1
+	}
+
+	tags := []string{"status:" + strconv.Itoa(httperror.StatusCode(err))}
+	if code := httperror.ErrorCode(err); code != "" {
+		tags = append(tags, "code:"+code)
+	}
+	if route != "" {
+		tags = append(tags, "route:"+route)
+	}
+
+	_ = e.Client.Incr(metric, tags, rate)
+}
+
+// Report counts err, with the route tag derived from r. It makes Emitter
+// an [httperror.ErrorReporter].
+func (e *Emitter) Report(ctx context.Context, r *http.Request, err error) {
+	var route string
+	if e.Route != nil && r != nil {
+		route = e.Route(r)
+	}
+	e.Emit(err, route)
+}
+
+// Middleware returns an [httperror.Middleware] that counts the errors
+// returned by the wrapped handler under route, and passes them on
+// unchanged.
+func (e *Emitter) Middleware(route string) httperror.Middleware {
+	return func(h httperror.Handler) httperror.Handler {
+		return httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := h.Serve(w, r)
+			e.Emit(err, route)
+			return err
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package statsdreport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/statsdreport"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ statsdreport.Client = (*statsd.Client)(nil)
+
+type incr struct {
+	name string
+	tags []string
+	rate float64
+}
+
+type recordingClient struct {
+	calls []incr
+}
+
+func (c *recordingClient) Incr(name string, tags []string, rate float64) error {
+	c.calls = append(c.calls, incr{name, tags, rate})
+	return nil
+}
+
+func TestEmitterMiddleware(t *testing.T) {
+	client := &recordingClient{}
+	e := &statsdreport.Emitter{Client: client}
+
+	h := e.Middleware("/orders")(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("fail") != "" {
+			return httperror.WithCode(httperror.NotFound, "order_not_found")
+		}
+		if r.URL.Query().Get("moved") != "" {
+			return httperror.Redirect(w, r, "/orders/42", http.StatusMovedPermanently)
+		}
+		return nil
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders?fail=1", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders?moved=1", nil))
+
+	assert.Equal(t, []incr{
+		{"httperror.errors", []string{"status:404", "code:order_not_found", "route:/orders"}, 1},
+	}, client.calls)
+}
+
+func TestEmitterReport(t *testing.T) {
+	client := &recordingClient{}
+	e := &statsdreport.Emitter{
+		Client: client,
+		Metric: "api.errors",
+		Route:  func(r *http.Request) string { return r.URL.Path },
+		Rate:   0.5,
+	}
+
+	e.Report(context.Background(), httptest.NewRequest(http.MethodGet, "/checkout", nil), httperror.InternalServerError)
+	e.Report(context.Background(), nil, httperror.BadGateway)
+	e.Report(context.Background(), nil, nil)
+
+	assert.Equal(t, []incr{
+		{"api.errors", []string{"status:500", "route:/checkout"}, 0.5},
+		{"api.errors", []string{"status:502"}, 0.5},
+	}, client.calls)
+}
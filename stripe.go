@@ -0,0 +1,73 @@
+package httperror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// StripeErrorHandler is an [httperror.ErrorHandler] that writes a JSON error
+// response in the shape used by the Stripe API:
+//
+//	{"error":{"type":"invalid_request_error","code":"resource_missing","message":"No such customer","param":"id"}}
+//
+// The type is derived from the status code, the code is extracted using
+// [httperror.ErrorCode], and the message is the public message (see
+// [httperror.PublicMessage]) or the status text if there is none. If the
+// error has a `Param() string` method, its value is used for the param
+// member. Use it with [httperror.WrapHandlerFunc] to serve APIs whose
+// clients expect this format.
+func StripeErrorHandler(w http.ResponseWriter, e error) {
+	s := StatusCode(e)
+
+	body := stripeErrorBody{
+		Type:    stripeErrorType(s),
+		Code:    ErrorCode(e),
+		Message: PublicMessage(e),
+	}
+	if body.Message == "" {
+		body.Message = statusText(s)
+	}
+
+	var paramError interface{ Param() string }
+	if errors.As(e, &paramError) {
+		body.Param = paramError.Param()
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	setErrorHeaders(w, e)
+	w.WriteHeader(s)
+
+	json, _ := json.Marshal(stripeError{body}) // No error handling for error handling
+
+	_, _ = w.Write(json)
+	_, _ = w.Write([]byte("\n"))
+}
+
+type stripeError struct {
+	Error stripeErrorBody `json:"error"`
+}
+
+type stripeErrorBody struct {
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	Param   string `json:"param,omitempty"`
+}
+
+// stripeErrorType maps an HTTP status code to one of the error types used
+// by the Stripe API.
+func stripeErrorType(s int) string {
+	switch {
+	case s >= 500:
+		return "api_error"
+	case s == http.StatusUnauthorized:
+		return "authentication_error"
+	case s == http.StatusPaymentRequired:
+		return "card_error"
+	case s == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	default:
+		return "invalid_request_error"
+	}
+}
@@ -0,0 +1,74 @@
+package httperror
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+)
+
+// FromTransportError converts an error returned by an [http.Client] or a
+// network call to an upstream service into an error with a gateway status
+// code, so that proxy and aggregator handlers can return transport errors
+// directly:
+//
+//   - timeouts (context.DeadlineExceeded, and net.Error timeouts):
+//     GatewayTimeout
+//   - refused connections, and temporary DNS failures: ServiceUnavailable
+//   - hosts that can't be resolved, reset connections, and other network
+//     errors: BadGateway
+//   - requests canceled by the client (context.Canceled): 499 Client Closed
+//     Request
+//
+// Except for canceled requests, the resulting errors are retryable (see
+// [httperror.IsRetryable]). The original error remains in the chain. Other
+// errors, and errors that already have a status code, are returned
+// unchanged.
+func FromTransportError(err error) error {
+	if err == nil || HasStatusCode(err) {
+		return err
+	}
+
+	if s := transportErrorStatus(err); s != 0 {
+		return Wrap(err, s)
+	}
+	return err
+}
+
+func transportErrorStatus(err error) int {
+	if errors.Is(err, context.Canceled) {
+		return statusClientClosedRequest
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsTimeout:
+			return http.StatusGatewayTimeout
+		case dnsErr.IsTemporary:
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusBadGateway
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return http.StatusGatewayTimeout
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return http.StatusServiceUnavailable
+	}
+
+	var urlErr *url.Error
+	var opErr *net.OpError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.Is(err, syscall.ECONNRESET) {
+		return http.StatusBadGateway
+	}
+	return 0
+}
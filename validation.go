@@ -0,0 +1,89 @@
+package httperror
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// FieldError describes a validation problem with a single field of a
+// request. The Code is a machine-readable reason (e.g. "missing_field" or
+// "invalid"), and the Message is safe to show to users.
+type FieldError struct {
+	Resource string `json:"resource,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Error returns the field name followed by the message, or the code if
+// there is no message.
+func (e FieldError) Error() string {
+	m := e.Message
+	if m == "" {
+		m = e.Code
+	}
+	if e.Field == "" {
+		return m
+	}
+	return e.Field + ": " + m
+}
+
+// Validation is an interface that requires a FieldErrors() []FieldError
+// method. [httperror.FieldErrors] will extract the field errors from errors
+// that implement this interface.
+type Validation = interface {
+	FieldErrors() []FieldError
+}
+
+// FieldErrors extracts the field validation errors from errors that have a
+// `FieldErrors() []FieldError` method.
+func FieldErrors(err error) []FieldError {
+	var validation Validation
+
+	if err == nil {
+		return nil
+	}
+
+	if errors.As(err, &validation) {
+		return validation.FieldErrors()
+	}
+
+	return nil
+}
+
+// NewValidationError returns a new 422 Unprocessable Entity error
+// describing the given invalid fields. The field errors can be extracted
+// using [httperror.FieldErrors].
+func NewValidationError(fieldErrors ...FieldError) error {
+	return validationError{fieldErrors, httpError{http.StatusUnprocessableEntity}}
+}
+
+type validationError struct {
+	fieldErrors []FieldError
+	httpError
+}
+
+// Error returns the status text followed by the list of invalid fields.
+func (e validationError) Error() string {
+	var b bytes.Buffer
+
+	b.WriteString(strconv.Itoa(e.status))
+	b.WriteString(" ")
+	b.WriteString(statusText(e.status))
+
+	for i, f := range e.fieldErrors {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(f.Error())
+	}
+	return b.String()
+}
+
+func (e validationError) FieldErrors() []FieldError {
+	return e.fieldErrors
+}
@@ -1,57 +1,94 @@
 package httperror
 
 import (
-	"bytes"
 	"fmt"
-	"net/http"
 	"strconv"
+	"sync"
 )
 
 // New constructs an error with an embedded an HTTP status code. The status
-// code can be extracted using [httperror.StatusCode].
+// code can be extracted using [httperror.StatusCode]. The message is used
+// as is, not as a format string, and the error is allocated at most once.
 func New(s int, m string) error {
 	if m == "" {
 		return httpError{s}
 	}
-	return Wrap(fmt.Errorf(m), s)
+	return &messageError{message: m, httpError: httpError{s}}
 }
 
 // Errorf works like fmt.Errorf but it also embeds an HTTP status code. The
 // status code can be extracted using [httperror.StatusCode].
 func Errorf(s int, format string, args ...interface{}) error {
-	m := fmt.Sprintf(format, args...)
-	if m == "" {
+	err := fmt.Errorf(format, args...)
+	if err.Error() == "" {
 		return httpError{s}
 	}
 
-	return Wrap(fmt.Errorf(m), s)
+	return Wrap(err, s)
 }
 
 // Wrap wraps an error and embeds an HTTP status code that can be extracted
-// using [httperror.StatusCode]
+// using [httperror.StatusCode]. Like the errors returned by New, the
+// resulting error is a single value, allocated once.
 func Wrap(err error, status int) error {
-	return wrappedError{err, httpError{status}}
+	return &wrappedError{inner: err, httpError: httpError{status}}
 }
 
-type wrappedError struct {
-	inner error
+// messageError is the error returned by New: a message and a status code,
+// without an inner error.
+type messageError struct {
+	message string
 	httpError
+	text errorText
 }
 
-// Error returns the HTTP status text corresponding to this error status code.
-func (e wrappedError) Error() string {
-	var b bytes.Buffer
+// Error returns the HTTP status text followed by the message.
+func (e *messageError) Error() string {
+	return e.text.get(func() string { return formatError(e.status, e.message) })
+}
 
-	b.WriteString(strconv.Itoa(e.status))
-	b.WriteString(" ")
-	b.WriteString(http.StatusText(e.status))
-	b.Write([]byte(": "))
-	b.Write([]byte(e.inner.Error()))
+type wrappedError struct {
+	inner error
+	httpError
+	text errorText
+}
 
-	return b.String()
+// Error returns the HTTP status text corresponding to this error status code,
+// followed by the error string of the inner error.
+func (e *wrappedError) Error() string {
+	return e.text.get(func() string { return formatError(e.status, e.inner.Error()) })
 }
 
 // Unwrap returns the inner error of a wrappedError
-func (e wrappedError) Unwrap() error {
+func (e *wrappedError) Unwrap() error {
 	return e.inner
 }
+
+// errorText memoizes the string of an error, which is formatted the first
+// time it is needed. Loggers may call Error many times for the same error,
+// and the components of the errors created by this package don't change.
+type errorText struct {
+	once sync.Once
+	text string
+}
+
+func (t *errorText) get(format func() string) string {
+	t.once.Do(func() { t.text = format() })
+	return t.text
+}
+
+// formatError returns the status code and status text, followed by the
+// message if there is one, e.g. "404 Not Found: no such user".
+func formatError(status int, message string) string {
+	text := statusText(status)
+
+	b := make([]byte, 0, 3+1+len(text)+2+len(message))
+	b = strconv.AppendInt(b, int64(status), 10)
+	b = append(b, ' ')
+	b = append(b, text...)
+	if message != "" {
+		b = append(b, ": "...)
+		b = append(b, message...)
+	}
+	return string(b)
+}
@@ -0,0 +1,154 @@
+package httperror
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrWriteAfterError is returned by writes to the response that are
+// discarded by [httperror.WriteGuardMiddleware] because the error response
+// has already been written.
+var ErrWriteAfterError = errors.New("httperror: write after error response")
+
+// WriteGuardMiddleware returns an [httperror.Middleware] that handles
+// errors returned by the wrapped handler with eh, and then turns any further
+// writes from the handler (for example from deferred functions or
+// goroutines that outlive the handler) into no-ops that return
+// ErrWriteAfterError, so that they can't corrupt the error response. The
+// first discarded write of each request is logged to logger, if not nil,
+// with SeverityWarning.
+//
+// The error is then returned marked as already written (see
+// [httperror.ResponseWritten]), so that outer middleware such as logging
+// still sees it, but the handler wrappers in this package don't write a
+// second response.
+func WriteGuardMiddleware(eh ErrorHandler, logger Logger) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+
+			gw := &guardedWriter{w: w}
+			gw.warn = func() {
+				if logger == nil {
+					return
+				}
+				logger.Log(LogEntry{
+					Method:    r.Method,
+					Path:      r.URL.Path,
+					Status:    gw.status,
+					Duration:  time.Since(start),
+					RequestID: r.Header.Get(requestIDHeader),
+					Severity:  SeverityWarning,
+					Err:       ErrWriteAfterError,
+				})
+			}
+
+			err := h.Serve(gw, r)
+			if err == nil || ResponseWritten(err) {
+				return err
+			}
+
+			gw.seal(StatusCode(err))
+			eh(w, err)
+
+			return writtenError{err}
+		})
+	}
+}
+
+// guardedWriter is an http.ResponseWriter that discards writes once it has
+// been sealed.
+type guardedWriter struct {
+	w    http.ResponseWriter
+	warn func()
+
+	mu     sync.Mutex
+	sealed bool
+	warned bool
+	status int
+	header http.Header
+}
+
+func (gw *guardedWriter) seal(status int) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	gw.sealed = true
+	gw.status = status
+}
+
+// discard reports whether a write must be discarded, logging the first
+// discarded write. It must be called with gw.mu held.
+func (gw *guardedWriter) discard() bool {
+	if !gw.sealed {
+		return false
+	}
+	if !gw.warned {
+		gw.warned = true
+		gw.warn()
+	}
+	return true
+}
+
+func (gw *guardedWriter) Header() http.Header {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.sealed {
+		// Changes to the header after sealing are discarded too.
+		if gw.header == nil {
+			gw.header = make(http.Header)
+		}
+		return gw.header
+	}
+	return gw.w.Header()
+}
+
+func (gw *guardedWriter) Write(b []byte) (int, error) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.discard() {
+		return 0, ErrWriteAfterError
+	}
+	return gw.w.Write(b)
+}
+
+func (gw *guardedWriter) WriteHeader(s int) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.discard() {
+		return
+	}
+	gw.w.WriteHeader(s)
+}
+
+func (gw *guardedWriter) Flush() {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.sealed {
+		return
+	}
+	flush(gw.w)
+}
+
+func (gw *guardedWriter) ReadFrom(src io.Reader) (int64, error) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.discard() {
+		return 0, ErrWriteAfterError
+	}
+	return readFrom(gw.w, src)
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// [http.ResponseController].
+func (gw *guardedWriter) Unwrap() http.ResponseWriter {
+	return gw.w
+}
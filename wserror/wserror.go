@@ -0,0 +1,147 @@
+/*
+Package wserror maps httperrors to and from WebSocket close codes, for
+WebSocket handlers using github.com/gorilla/websocket. See the
+documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package wserror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"github.com/johnwarden/httperror"
+)
+
+// closeBadGateway is the close code for a bad gateway (RFC 6455 registry),
+// which has no constant in the websocket package.
+const closeBadGateway = 1014
+
+// maxReasonLength is the maximum length of a close reason: the payload of a
+// control frame is limited to 125 bytes, including the 2-byte close code.
+const maxReasonLength = 123
+
+// CloseCode returns the WebSocket close code for err: normal closure for
+// nil, and otherwise a code derived from the status code of the error (see
+// [httperror.StatusCode]). Client errors map to policy violation, except
+// Bad Request (invalid payload data), Request Entity Too Large (message too
+// big) and Unsupported Media Type (unsupported data). Server errors and
+// panics map to internal error, except Service Unavailable (try again
+// later) and Bad Gateway and Gateway Timeout (bad gateway).
+func CloseCode(err error) int {
+	if err == nil {
+		return websocket.CloseNormalClosure
+	}
+	if errors.Is(err, httperror.Panic) {
+		return websocket.CloseInternalServerErr
+	}
+
+	switch s := httperror.StatusCode(err); s {
+	case http.StatusBadRequest:
+		return websocket.CloseInvalidFramePayloadData
+	case http.StatusRequestEntityTooLarge:
+		return websocket.CloseMessageTooBig
+	case http.StatusUnsupportedMediaType:
+		return websocket.CloseUnsupportedData
+	case http.StatusServiceUnavailable:
+		return websocket.CloseTryAgainLater
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return closeBadGateway
+	default:
+		if s >= 400 && s < 500 {
+			return websocket.ClosePolicyViolation
+		}
+		return websocket.CloseInternalServerErr
+	}
+}
+
+// StatusFromCloseCode returns the HTTP status code corresponding to a
+// WebSocket close code: OK for normal closure and going away, the inverse
+// of [CloseCode] for the codes it returns, Bad Request for protocol errors,
+// Service Unavailable for service restart, and Internal Server Error
+// otherwise.
+func StatusFromCloseCode(code int) int {
+	switch code {
+	case websocket.CloseNormalClosure, websocket.CloseGoingAway:
+		return http.StatusOK
+	case websocket.CloseProtocolError, websocket.CloseInvalidFramePayloadData:
+		return http.StatusBadRequest
+	case websocket.CloseUnsupportedData:
+		return http.StatusUnsupportedMediaType
+	case websocket.ClosePolicyViolation:
+		return http.StatusForbidden
+	case websocket.CloseMessageTooBig:
+		return http.StatusRequestEntityTooLarge
+	case websocket.CloseServiceRestart, websocket.CloseTryAgainLater:
+		return http.StatusServiceUnavailable
+	case closeBadGateway:
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+// FromCloseError converts a *websocket.CloseError in the chain of err, as
+// returned when reading from a connection closed by the peer, into an
+// error with the status code for its close code (see
+// [StatusFromCloseCode]). The close error remains in the chain. Other
+// errors, and close errors with normal closure or going away, are returned
+// unchanged.
+func FromCloseError(err error) error {
+	var ce *websocket.CloseError
+	if !errors.As(err, &ce) {
+		return err
+	}
+	s := StatusFromCloseCode(ce.Code)
+	if s < 400 {
+		return err
+	}
+	return httperror.Wrap(err, s)
+}
+
+// Upgrade upgrades the connection like u.Upgrade, but if the handshake
+// fails, it returns an error with the status code and a public message
+// describing the failure instead of writing the response, so that the
+// handler can return it and the application's error handler renders it:
+//
+//	conn, err := wserror.Upgrade(&upgrader, w, r, nil)
+//	if err != nil {
+//		return err
+//	}
+//
+// The Error function of u is ignored.
+func Upgrade(u *websocket.Upgrader, w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*websocket.Conn, error) {
+	var status int
+	upgrader := *u
+	upgrader.Error = func(w http.ResponseWriter, r *http.Request, s int, reason error) {
+		status = s
+	}
+
+	conn, err := upgrader.Upgrade(w, r, responseHeader)
+	if err != nil && status != 0 {
+		err = httperror.WithHeader(httperror.NewPublic(status, err.Error()), "Sec-Websocket-Version", "13")
+	}
+	return conn, err
+}
+
+// Close closes an established connection, first sending a close message
+// with the close code for err (see [CloseCode]) and its public message
+// (see [httperror.PublicMessage]), truncated to fit in a control frame, as
+// the reason. Internal error details are never sent. The error from closing
+// the connection is returned.
+func Close(conn *websocket.Conn, err error) error {
+	reason := httperror.PublicMessage(err)
+	if len(reason) > maxReasonLength {
+		n := maxReasonLength
+		for n > 0 && !utf8.RuneStart(reason[n]) {
+			n--
+		}
+		reason = reason[:n]
+	}
+
+	msg := websocket.FormatCloseMessage(CloseCode(err), reason)
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	return conn.Close()
+}
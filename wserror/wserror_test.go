@@ -0,0 +1,71 @@
+package wserror_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/wserror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseCode(t *testing.T) {
+	assert.Equal(t, websocket.CloseNormalClosure, wserror.CloseCode(nil))
+	assert.Equal(t, websocket.ClosePolicyViolation, wserror.CloseCode(httperror.Forbidden))
+	assert.Equal(t, websocket.CloseMessageTooBig, wserror.CloseCode(httperror.RequestEntityTooLarge))
+	assert.Equal(t, websocket.CloseTryAgainLater, wserror.CloseCode(httperror.ServiceUnavailable))
+	assert.Equal(t, websocket.CloseInternalServerErr, wserror.CloseCode(errors.New("oops")))
+	assert.Equal(t, websocket.CloseInternalServerErr, wserror.CloseCode(httperror.PanicError("oops")))
+
+	for _, err := range []error{httperror.BadRequest, httperror.RequestEntityTooLarge, httperror.UnsupportedMediaType, httperror.ServiceUnavailable, httperror.BadGateway, httperror.InternalServerError} {
+		assert.Equal(t, httperror.StatusCode(err), wserror.StatusFromCloseCode(wserror.CloseCode(err)))
+	}
+	assert.Equal(t, http.StatusOK, wserror.StatusFromCloseCode(websocket.CloseGoingAway))
+}
+
+func TestFromCloseError(t *testing.T) {
+	ce := &websocket.CloseError{Code: websocket.CloseMessageTooBig}
+	err := wserror.FromCloseError(ce)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, httperror.StatusCode(err))
+	assert.ErrorIs(t, err, ce)
+
+	ce = &websocket.CloseError{Code: websocket.CloseGoingAway}
+	assert.Equal(t, ce, wserror.FromCloseError(ce))
+}
+
+func TestUpgradeAndClose(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	s := httptest.NewServer(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		conn, err := wserror.Upgrade(&upgrader, w, r, nil)
+		if err != nil {
+			return err
+		}
+		return wserror.Close(conn, httperror.NewPublic(http.StatusForbidden, "subscription expired"))
+	}))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "13", resp.Header.Get("Sec-Websocket-Version"))
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	_, _, err = conn.ReadMessage()
+	var ce *websocket.CloseError
+	if assert.ErrorAs(t, err, &ce) {
+		assert.Equal(t, websocket.ClosePolicyViolation, ce.Code)
+		assert.Equal(t, "subscription expired", ce.Text)
+	}
+}
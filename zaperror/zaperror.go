@@ -0,0 +1,134 @@
+/*
+Package zaperror provides structured logging of httperrors with zap
+(go.uber.org/zap). See the documentation of the parent package at
+https://github.com/johnwarden/httperror
+*/
+package zaperror
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/johnwarden/httperror"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Fields returns zap fields describing err: the error itself (see
+// zap.Error), its status code (see [httperror.StatusCode]), error code (see
+// [httperror.ErrorCode]) and public message (see
+// [httperror.PublicMessage]), if any, and the stack trace of the innermost
+// error in the chain that carries one (such as errors created with
+// github.com/pkg/errors). A nil error returns no fields.
+//
+//	logger.Error("request failed", zaperror.Fields(err)...)
+func Fields(err error) []zap.Field {
+	if err == nil {
+		return nil
+	}
+
+	fields := []zap.Field{
+		zap.Error(err),
+		zap.Int("status", httperror.StatusCode(err)),
+	}
+	return appendDetails(fields, err)
+}
+
+// appendDetails appends the fields returned by [Fields] for err, other than
+// the error and the status code.
+func appendDetails(fields []zap.Field, err error) []zap.Field {
+	if c := httperror.ErrorCode(err); c != "" {
+		fields = append(fields, zap.String("code", c))
+	}
+	if m := httperror.PublicMessage(err); m != "" {
+		fields = append(fields, zap.String("public", m))
+	}
+	if st := stackTrace(err); st != nil {
+		fields = append(fields, zap.String("stack", strings.TrimPrefix(fmt.Sprintf("%+v", st), "\n")))
+	}
+	return fields
+}
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// stackTrace returns the stack trace of the innermost error in the chain of
+// err that has one, or nil.
+func stackTrace(err error) errors.StackTrace {
+	var st errors.StackTrace
+	for ; err != nil; err = errors.Unwrap(err) {
+		if t, ok := err.(stackTracer); ok {
+			st = t.StackTrace()
+		}
+	}
+	return st
+}
+
+// Middleware returns an [httperror.Middleware] that logs one entry per
+// request to logger, like [httperror.SlogMiddleware]: with the request
+// method, path, status code (see [httperror.StatusCode], or the status
+// written by the handler if it returned no error), duration, request ID
+// (from the X-Request-Id request header), trace ID (from the W3C
+// traceparent request header), and the fields returned by [Fields] for any
+// returned error. The level is derived from the severity of the error (see
+// [httperror.ErrorSeverity]). The error is then returned unchanged.
+func Middleware(logger *zap.Logger) httperror.Middleware {
+	return func(h httperror.Handler) httperror.Handler {
+		return httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+
+			rw := httperror.NewResponseWriter(w)
+			err := h.Serve(rw, r)
+
+			status := httperror.StatusCode(err)
+			if err == nil && rw.Written() {
+				status = rw.Status()
+			}
+
+			fields := []zap.Field{
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", status),
+				zap.Duration("duration", time.Since(start)),
+			}
+			if id := r.Header.Get("X-Request-Id"); id != "" {
+				fields = append(fields, zap.String("request_id", id))
+			}
+			if id := traceID(r); id != "" {
+				fields = append(fields, zap.String("trace_id", id))
+			}
+			if err != nil {
+				fields = appendDetails(append(fields, zap.Error(err)), err)
+			}
+
+			logger.Log(level(httperror.ErrorSeverity(err)), "http request", fields...)
+
+			return err
+		})
+	}
+}
+
+func level(s httperror.Severity) zapcore.Level {
+	switch s {
+	case httperror.SeverityError:
+		return zapcore.ErrorLevel
+	case httperror.SeverityWarning:
+		return zapcore.WarnLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// traceID extracts the trace ID from a W3C traceparent request header
+// (version-traceid-parentid-flags). Returns the empty string if there is no
+// valid header.
+func traceID(r *http.Request) string {
+	parts := strings.Split(r.Header.Get("traceparent"), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
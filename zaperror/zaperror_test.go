@@ -0,0 +1,70 @@
+package zaperror_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnwarden/httperror"
+	"github.com/johnwarden/httperror/zaperror"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFields(t *testing.T) {
+	assert.Nil(t, zaperror.Fields(nil))
+
+	err := httperror.WithCode(httperror.NewPublic(http.StatusConflict, "order already paid"), "already_paid")
+	fields := zaperror.Fields(err)
+	assert.Equal(t, []zap.Field{
+		zap.Error(err),
+		zap.Int("status", 409),
+		zap.String("code", "already_paid"),
+		zap.String("public", "order already paid"),
+	}, fields)
+
+	fields = zaperror.Fields(httperror.Wrap(errors.New("connection refused"), http.StatusBadGateway))
+	assert.Len(t, fields, 3)
+	assert.Equal(t, "stack", fields[2].Key)
+	assert.Contains(t, fields[2].String, "TestFields")
+}
+
+func TestMiddleware(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	h := zaperror.Middleware(zap.New(core))(httperror.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/missing":
+			return httperror.WithCode(httperror.NotFound, "order_not_found")
+		case "/broken":
+			return httperror.InternalServerError
+		}
+		return nil
+	}))
+
+	for _, path := range []string{"/", "/missing", "/broken"} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("X-Request-Id", "req-1")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	entries := logs.AllUntimed()
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, int64(200), entries[0].ContextMap()["status"])
+	assert.NotContains(t, entries[0].ContextMap(), "error")
+
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, int64(404), entries[1].ContextMap()["status"])
+	assert.Equal(t, "order_not_found", entries[1].ContextMap()["code"])
+	assert.Equal(t, "req-1", entries[1].ContextMap()["request_id"])
+
+	assert.Equal(t, zapcore.ErrorLevel, entries[2].Level)
+	assert.Equal(t, "500 Internal Server Error", entries[2].ContextMap()["error"])
+}